@@ -4,10 +4,10 @@ package cuserr
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 )
 
 // ValidationError represents a single field validation error
@@ -122,6 +122,25 @@ func (ec *ErrorCollection) WithRequestID(requestID string) *ErrorCollection {
 	return ec
 }
 
+// Clone returns a copy of the collection that can be changed independently
+// The member errors themselves are shared
+func (ec *ErrorCollection) Clone() *ErrorCollection {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	clone := &ErrorCollection{
+		Errors:           append(make([]*CustomError, 0, len(ec.Errors)), ec.Errors...),
+		ValidationErrors: append(make([]ValidationError, 0, len(ec.ValidationErrors)), ec.ValidationErrors...),
+		Summary:          ec.Summary,
+		RequestID:        ec.RequestID,
+		Context:          make(map[string]string, len(ec.Context)),
+	}
+	for key, value := range ec.Context {
+		clone.Context[key] = value
+	}
+	return clone
+}
+
 // WithContext adds context metadata
 func (ec *ErrorCollection) WithContext(key, value string) *ErrorCollection {
 	ec.mu.Lock()
@@ -180,18 +199,18 @@ func (ec *ErrorCollection) GetFieldErrors(field string) []ValidationError {
 }
 
 // GetFields returns all fields that have validation errors
+// Fields are listed once each, in the order they were first added
 func (ec *ErrorCollection) GetFields() []string {
 	ec.mu.RLock()
 	defer ec.mu.RUnlock()
 
 	fieldSet := make(map[string]bool)
+	fields := make([]string, 0, len(ec.ValidationErrors))
 	for _, err := range ec.ValidationErrors {
-		fieldSet[err.Field] = true
-	}
-
-	fields := make([]string, 0, len(fieldSet))
-	for field := range fieldSet {
-		fields = append(fields, field)
+		if !fieldSet[err.Field] {
+			fieldSet[err.Field] = true
+			fields = append(fields, err.Field)
+		}
 	}
 
 	return fields
@@ -222,23 +241,23 @@ func (ec *ErrorCollection) ToCustomError() *CustomError {
 	err := NewCustomError(sentinel, nil, message)
 
 	if ec.RequestID != "" {
-		err.WithRequestID(ec.RequestID)
+		err = err.WithRequestID(ec.RequestID)
 	}
 
 	// Add error counts to metadata (fluent API - ignoring return values)
-	_ = err.WithMetadata("error_count", fmt.Sprintf("%d", len(ec.Errors)))
-	_ = err.WithMetadata("validation_error_count", fmt.Sprintf("%d", len(ec.ValidationErrors)))
-	_ = err.WithMetadata("total_error_count", fmt.Sprintf("%d", ec.Count()))
+	_ = err.WithMetadataValue("error_count", len(ec.Errors))
+	_ = err.WithMetadataValue("validation_error_count", len(ec.ValidationErrors))
+	_ = err.WithMetadataValue("total_error_count", ec.Count())
 
 	// Add context metadata
 	for key, value := range ec.Context {
-		err.WithMetadata(key, value)
+		err = err.WithMetadata(key, value)
 	}
 
 	// Add fields with errors
 	if len(ec.ValidationErrors) > 0 {
 		fields := ec.GetFields()
-		err.WithMetadata("validation_fields", strings.Join(fields, ","))
+		err = err.WithMetadataValue("validation_fields", fields)
 	}
 
 	return err
@@ -260,6 +279,68 @@ func (ec *ErrorCollection) Error() string {
 	return fmt.Sprintf("multiple errors occurred (%d total)", ec.Count())
 }
 
+// Unwrap returns every member so that errors.Is and errors.As inspect the whole collection
+// Validation errors are synthesized as validation CustomErrors without stack traces
+func (ec *ErrorCollection) Unwrap() []error {
+	members := ec.members()
+	errs := make([]error, len(members))
+	for i, member := range members {
+		errs[i] = member
+	}
+	return errs
+}
+
+// members returns the collection's errors followed by its synthesized validation errors
+func (ec *ErrorCollection) members() []*CustomError {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	members := make([]*CustomError, 0, len(ec.Errors)+len(ec.ValidationErrors))
+	members = append(members, ec.Errors...)
+	for _, validationErr := range ec.ValidationErrors {
+		members = append(members, validationErr.customError(ec.RequestID))
+	}
+	return members
+}
+
+// customError synthesizes a validation CustomError for a field error
+// No stack trace is captured since the error is built on demand during inspection
+func (ve ValidationError) customError(requestID string) *CustomError {
+	code := ve.Code
+	if code == "" {
+		code = ERROR_CODE_INVALID_INPUT
+	}
+
+	metadata := map[string]interface{}{MetaField: ve.Field}
+	if ve.Value != "" {
+		metadata[MetaValidationValue] = ve.Value
+	}
+
+	return &CustomError{
+		Category:  ErrorCategoryValidation,
+		Code:      code,
+		Message:   ve.Message,
+		RequestID: requestID,
+		Timestamp: time.Now().UTC(),
+		Sentinel:  ErrInvalidInput,
+		metadata:  metadata,
+	}
+}
+
+// category returns the category of the most severe member
+func (ec *ErrorCollection) category() ErrorCategory {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	return ec.categoryLocked()
+}
+
+// categoryLocked returns the collection category; caller must hold the lock
+func (ec *ErrorCollection) categoryLocked() ErrorCategory {
+	category, _ := ec.mostSevereLocked()
+	return category
+}
+
 // ToJSON returns the collection as a JSON-serializable map
 func (ec *ErrorCollection) ToJSON() map[string]interface{} {
 	ec.mu.RLock()
@@ -267,8 +348,8 @@ func (ec *ErrorCollection) ToJSON() map[string]interface{} {
 
 	result := map[string]interface{}{
 		"error": map[string]interface{}{
-			"category":    string(ErrorCategoryValidation),
-			"code":        "MULTIPLE_ERRORS",
+			"category":    string(ec.categoryLocked()),
+			"code":        ERROR_CODE_MULTIPLE_ERRORS,
 			"message":     ec.Error(),
 			"summary":     ec.Summary,
 			"error_count": ec.Count(),
@@ -336,25 +417,53 @@ func (ec *ErrorCollection) ToClientJSON() map[string]interface{} {
 }
 
 // ToHTTPStatus returns the appropriate HTTP status code
+// The most severe member decides; validation errors count as a member of the
+// validation category and win ties
 func (ec *ErrorCollection) ToHTTPStatus() int {
 	if ec.IsEmpty() {
 		return 200 // OK
 	}
 
-	// If we have validation errors, return 400 (Bad Request)
-	if ec.ValidationCount() > 0 {
-		return 400
-	}
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
 
-	// Otherwise, use the status from the first error
+	category, _ := ec.mostSevereLocked()
+	return CategoryToHTTPStatus(category)
+}
+
+// Severity returns the severity of the most severe member
+func (ec *ErrorCollection) Severity() Severity {
 	ec.mu.RLock()
 	defer ec.mu.RUnlock()
 
-	if len(ec.Errors) > 0 {
-		return ec.Errors[0].ToHTTPStatus()
+	return ec.severityLocked()
+}
+
+// severityLocked returns the collection severity; caller must hold the lock
+func (ec *ErrorCollection) severityLocked() Severity {
+	_, severity := ec.mostSevereLocked()
+	return severity
+}
+
+// mostSevereLocked finds the category and severity of the most severe member
+// Caller must hold the lock
+func (ec *ErrorCollection) mostSevereLocked() (ErrorCategory, Severity) {
+	category := ErrorCategoryValidation
+	var severity Severity
+	if len(ec.ValidationErrors) > 0 {
+		severity = CategorySeverity(ErrorCategoryValidation)
+	}
+
+	for _, err := range ec.Errors {
+		if errSeverity := err.Severity(); errSeverity > severity {
+			category, severity = err.Category, errSeverity
+		}
 	}
 
-	return 400 // Default to Bad Request
+	if severity == 0 {
+		severity = CategorySeverity(ErrorCategoryValidation)
+	}
+	return category, severity
 }
 
 // Builder pattern for error collections
@@ -475,5 +584,9 @@ func ValidateEmail(field, value string, collection *ErrorCollection) {
 
 // MarshalJSON implements json.Marshaler interface
 func (ec *ErrorCollection) MarshalJSON() ([]byte, error) {
-	return json.Marshal(ec.ToJSON())
+	jw := getJSONWriter(nil)
+	defer putJSONWriter(jw)
+
+	ec.appendJSON(jw)
+	return append([]byte(nil), jw.buf...), nil
 }
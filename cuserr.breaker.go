@@ -0,0 +1,476 @@
+// Package cuserr provides circuit breakers keyed by dependency name.
+// This file contains Breaker, BreakerRegistry and the CIRCUIT_OPEN error.
+package cuserr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BreakerState describes whether a circuit breaker lets calls through
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through while counting failures
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects calls with a CIRCUIT_OPEN error until the open timeout elapses
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of probe calls through to test recovery
+	BreakerHalfOpen
+)
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return BREAKER_STATE_CLOSED
+	case BreakerOpen:
+		return BREAKER_STATE_OPEN
+	case BreakerHalfOpen:
+		return BREAKER_STATE_HALF_OPEN
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText renders the state by name so health check output is readable
+func (s BreakerState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// BreakerConfig configures a circuit breaker
+// Zero fields fall back to the DefaultBreakerConfig values. A circuit opens
+// when the failures inside Window reach FailureThreshold and make up at least
+// FailureRatio of the recorded calls; a negative FailureRatio disables the
+// ratio check.
+type BreakerConfig struct {
+	// Window is the sliding window in which outcomes are counted
+	Window time.Duration
+	// FailureThreshold is the number of failures in the window that opens the circuit
+	FailureThreshold int
+	// FailureRatio is the minimum share of failed calls in the window that opens the circuit
+	FailureRatio float64
+	// OpenTimeout is how long the circuit stays open before allowing probes
+	OpenTimeout time.Duration
+	// HalfOpenRequests limits the concurrent probe calls while half-open
+	HalfOpenRequests int
+	// IsFailure decides which errors count against the dependency; defaults to
+	// external and timeout errors. Other errors count as successful calls.
+	IsFailure func(err error) bool
+	// Clock provides the current time; defaults to SystemClock
+	Clock Clock
+}
+
+// DefaultBreakerConfig returns the default circuit breaker configuration
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Window:           BREAKER_DEFAULT_WINDOW_MS * time.Millisecond,
+		FailureThreshold: BREAKER_DEFAULT_FAILURE_THRESHOLD,
+		FailureRatio:     BREAKER_DEFAULT_FAILURE_RATIO,
+		OpenTimeout:      BREAKER_DEFAULT_OPEN_TIMEOUT_MS * time.Millisecond,
+		HalfOpenRequests: BREAKER_DEFAULT_HALF_OPEN_REQUESTS,
+	}
+}
+
+// withDefaults fills unset configuration fields
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	defaults := DefaultBreakerConfig()
+
+	if c.Window <= 0 {
+		c.Window = defaults.Window
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = defaults.FailureThreshold
+	}
+	if c.FailureRatio == 0 {
+		c.FailureRatio = defaults.FailureRatio
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = defaults.OpenTimeout
+	}
+	if c.HalfOpenRequests <= 0 {
+		c.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+	if c.IsFailure == nil {
+		c.IsFailure = isBreakerFailure
+	}
+	if c.Clock == nil {
+		c.Clock = SystemClock
+	}
+	return c
+}
+
+// isBreakerFailure counts external and timeout errors, including plain deadline and timeout errors
+func isBreakerFailure(err error) bool {
+	if IsErrorCategory(err, ErrorCategoryExternal) || IsErrorCategory(err, ErrorCategoryTimeout) {
+		return true
+	}
+
+	var customErr *CustomError
+	if errors.As(err, &customErr) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var timeoutErr interface{ Timeout() bool }
+	return errors.As(err, &timeoutErr) && timeoutErr.Timeout()
+}
+
+// BreakerStatus is a snapshot of a circuit breaker for health checks
+type BreakerStatus struct {
+	Service  string       `json:"service"`
+	State    BreakerState `json:"state"`
+	Requests int          `json:"requests"`
+	Failures int          `json:"failures"`
+	// OpenedAt is set while the circuit is open or half-open
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+	// RetryAt is when an open circuit starts allowing probes
+	RetryAt *time.Time `json:"retry_at,omitempty"`
+}
+
+// breakerOutcome records the result of one call inside the sliding window
+type breakerOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+// Breaker is a circuit breaker protecting a single dependency
+// All methods are safe for concurrent use.
+type Breaker struct {
+	service  string
+	config   BreakerConfig
+	mu       sync.Mutex
+	state    BreakerState
+	openedAt time.Time
+	outcomes []breakerOutcome
+	probes   int
+}
+
+// NewBreaker creates a standalone circuit breaker for a dependency
+func NewBreaker(service string, config BreakerConfig) *Breaker {
+	return &Breaker{
+		service: service,
+		config:  config.withDefaults(),
+	}
+}
+
+// Service returns the name of the protected dependency
+func (b *Breaker) Service() string {
+	return b.service
+}
+
+// Allow reports whether a call may proceed
+// It returns a CIRCUIT_OPEN error while the circuit is open, with the time
+// until the half-open transition attached as its retry hint. Every allowed
+// call must be followed by Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.config.Clock.Now()
+	b.advanceLocked(now)
+
+	switch b.state {
+	case BreakerOpen:
+		return b.openErrorLocked(now)
+	case BreakerHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			return b.openErrorLocked(now)
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call
+// Pass nil for successful calls so the failure ratio stays meaningful. A
+// failed probe reopens the circuit and a successful one closes it; while
+// half-open, outcomes are ignored unless a probe granted by Allow is pending.
+func (b *Breaker) Record(err error) {
+	b.record(err, true)
+}
+
+// record stores the outcome of a call; admitted marks calls that went through Allow
+// Outcomes observed without Allow only count while the circuit is closed
+func (b *Breaker) record(err error, admitted bool) {
+	b.recordOutcome(err != nil && b.config.IsFailure(err), admitted)
+}
+
+// recordOutcome stores a classified outcome; admitted marks calls that went through Allow
+func (b *Breaker) recordOutcome(failed, admitted bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.config.Clock.Now()
+	b.advanceLocked(now)
+
+	switch b.state {
+	case BreakerClosed:
+		b.outcomes = append(b.outcomes, breakerOutcome{at: now, failed: failed})
+		b.pruneLocked(now)
+		if b.shouldTripLocked() {
+			b.tripLocked(now)
+		}
+	case BreakerHalfOpen:
+		if !admitted || b.probes == 0 {
+			return
+		}
+		b.probes--
+		if failed {
+			b.tripLocked(now)
+		} else {
+			b.resetLocked()
+		}
+	}
+}
+
+// Do runs fn when the circuit allows it and records the outcome
+// A panic in fn is recorded as a failure, releasing a half-open probe, and
+// keeps propagating
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+
+	completed := false
+	defer func() {
+		if !completed {
+			b.recordOutcome(true, true)
+		}
+	}()
+
+	err := fn(ctx)
+	completed = true
+	b.Record(err)
+	return err
+}
+
+// State returns the current state of the circuit
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advanceLocked(b.config.Clock.Now())
+	return b.state
+}
+
+// Status returns a snapshot of the circuit for health checks
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.config.Clock.Now()
+	b.advanceLocked(now)
+	b.pruneLocked(now)
+
+	status := BreakerStatus{
+		Service:  b.service,
+		State:    b.state,
+		Requests: len(b.outcomes),
+		Failures: b.failuresLocked(),
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.config.OpenTimeout)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// Reset closes the circuit and forgets all recorded outcomes
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetLocked()
+}
+
+// advanceLocked moves an open circuit to half-open once the open timeout has elapsed
+func (b *Breaker) advanceLocked(now time.Time) {
+	if b.state == BreakerOpen && !now.Before(b.openedAt.Add(b.config.OpenTimeout)) {
+		b.state = BreakerHalfOpen
+		b.probes = 0
+	}
+}
+
+// pruneLocked drops outcomes that fell out of the sliding window
+func (b *Breaker) pruneLocked(now time.Time) {
+	cutoff := now.Add(-b.config.Window)
+	drop := 0
+	for drop < len(b.outcomes) && !b.outcomes[drop].at.After(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		b.outcomes = append(b.outcomes[:0], b.outcomes[drop:]...)
+	}
+}
+
+// failuresLocked counts failed outcomes in the window
+func (b *Breaker) failuresLocked() int {
+	failures := 0
+	for _, outcome := range b.outcomes {
+		if outcome.failed {
+			failures++
+		}
+	}
+	return failures
+}
+
+// shouldTripLocked applies the failure threshold and ratio to the window
+func (b *Breaker) shouldTripLocked() bool {
+	failures := b.failuresLocked()
+	if failures < b.config.FailureThreshold {
+		return false
+	}
+	if b.config.FailureRatio < 0 {
+		return true
+	}
+	return float64(failures)/float64(len(b.outcomes)) >= b.config.FailureRatio
+}
+
+// tripLocked opens the circuit
+func (b *Breaker) tripLocked(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.outcomes = nil
+	b.probes = 0
+}
+
+// resetLocked closes the circuit
+func (b *Breaker) resetLocked() {
+	b.state = BreakerClosed
+	b.openedAt = time.Time{}
+	b.outcomes = nil
+	b.probes = 0
+}
+
+// openErrorLocked builds the CIRCUIT_OPEN error returned while calls are rejected
+func (b *Breaker) openErrorLocked(now time.Time) *CustomError {
+	err := NewCustomError(ErrCircuitOpen, nil, fmt.Sprintf("circuit breaker open for service '%s'", b.service)).
+		WithMetadata(MetaService, b.service)
+
+	if retryAt := b.openedAt.Add(b.config.OpenTimeout); retryAt.After(now) {
+		err = err.WithRetryAfter(retryAt.Sub(now))
+	}
+	return err
+}
+
+// BreakerRegistry holds one circuit breaker per service name
+type BreakerRegistry struct {
+	mu       sync.RWMutex
+	config   BreakerConfig
+	breakers map[string]*Breaker
+}
+
+// NewBreakerRegistry creates a registry whose breakers use the given configuration
+func NewBreakerRegistry(config BreakerConfig) *BreakerRegistry {
+	return &BreakerRegistry{
+		config:   config,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Breaker returns the breaker for a service, creating it on first use
+func (r *BreakerRegistry) Breaker(service string) *Breaker {
+	r.mu.RLock()
+	breaker, exists := r.breakers[service]
+	r.mu.RUnlock()
+	if exists {
+		return breaker
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if breaker, exists := r.breakers[service]; exists {
+		return breaker
+	}
+	breaker = NewBreaker(service, r.config)
+	r.breakers[service] = breaker
+	return breaker
+}
+
+// Configure replaces the breaker for a service with one using its own configuration
+func (r *BreakerRegistry) Configure(service string, config BreakerConfig) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	breaker := NewBreaker(service, config)
+	r.breakers[service] = breaker
+	return breaker
+}
+
+// RecordError records a call outcome against the service named in the error's metadata
+// Errors from NewExternalError and NewUnavailableError carry the service name;
+// errors without one are ignored and errors that do not count as failures are
+// recorded as successful calls. Report successful calls with RecordSuccess,
+// otherwise every recorded call is a failure and the ratio check always passes.
+// Outcomes recorded here bypass Allow, so they never decide a half-open probe.
+func (r *BreakerRegistry) RecordError(err error) {
+	service, exists := GetErrorMetadata(err, MetaService)
+	if !exists || service == "" {
+		return
+	}
+	r.Breaker(service).record(err, false)
+}
+
+// RecordSuccess records a successful call against a service
+func (r *BreakerRegistry) RecordSuccess(service string) {
+	if service == "" {
+		return
+	}
+	r.Breaker(service).record(nil, false)
+}
+
+// Statuses returns a snapshot of every breaker, sorted by service name
+func (r *BreakerRegistry) Statuses() []BreakerStatus {
+	r.mu.RLock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, breaker := range r.breakers {
+		breakers = append(breakers, breaker)
+	}
+	r.mu.RUnlock()
+
+	statuses := make([]BreakerStatus, 0, len(breakers))
+	for _, breaker := range breakers {
+		statuses = append(statuses, breaker.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Service < statuses[j].Service
+	})
+	return statuses
+}
+
+// Package-level breaker registry using the default configuration
+var breakerRegistry = NewBreakerRegistry(DefaultBreakerConfig())
+
+// GetBreaker returns the package-level breaker for a service
+func GetBreaker(service string) *Breaker {
+	return breakerRegistry.Breaker(service)
+}
+
+// ConfigureBreaker replaces the package-level breaker for a service
+func ConfigureBreaker(service string, config BreakerConfig) *Breaker {
+	return breakerRegistry.Configure(service, config)
+}
+
+// RecordBreakerError records a failure against the package-level breaker named in the error
+func RecordBreakerError(err error) {
+	breakerRegistry.RecordError(err)
+}
+
+// RecordBreakerSuccess records a successful call against the package-level breaker for a service
+func RecordBreakerSuccess(service string) {
+	breakerRegistry.RecordSuccess(service)
+}
+
+// BreakerStatuses returns a snapshot of every package-level breaker for health checks
+func BreakerStatuses() []BreakerStatus {
+	return breakerRegistry.Statuses()
+}
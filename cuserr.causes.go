@@ -0,0 +1,123 @@
+// Package cuserr provides support for errors with several causes.
+// This file contains WithCauses, Causes and the rendering of cause lists.
+package cuserr
+
+import (
+	"errors"
+	"strings"
+)
+
+// WithCauses adds causes to the error
+// Several causes are stored as an errors.Join value in Wrapped, so Unwrap,
+// errors.Is and errors.As traverse every one of them. Nil causes are ignored.
+func (e *CustomError) WithCauses(causes ...error) *CustomError {
+	target := e.lockWritable()
+	defer target.mu.Unlock()
+
+	all := splitCauses(target.Wrapped)
+	for _, cause := range causes {
+		if cause != nil {
+			all = append(all, cause)
+		}
+	}
+
+	target.Wrapped = joinCauses(all)
+	return target
+}
+
+// Causes returns the direct causes of the error
+// A wrapped errors.Join value contributes each of its members; any other
+// wrapped error, including fmt.Errorf with several %w verbs and an
+// ErrorCollection, is a single cause so that its own text is kept
+func (e *CustomError) Causes() []error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return splitCauses(e.Wrapped)
+}
+
+// joinCauses turns a cause list into a Wrapped value
+func joinCauses(causes []error) error {
+	switch len(causes) {
+	case 0:
+		return nil
+	case 1:
+		return causes[0]
+	default:
+		return errors.Join(causes...)
+	}
+}
+
+// splitCauses expands an errors.Join value into its members
+// Other multi-errors stay whole since their text is more than their members
+func splitCauses(wrapped error) []error {
+	if wrapped == nil {
+		return nil
+	}
+	if joined, ok := wrapped.(interface{ Unwrap() []error }); ok {
+		members := joined.Unwrap()
+		if !isJoinedError(wrapped, members) {
+			return []error{wrapped}
+		}
+		causes := make([]error, 0, len(members))
+		for _, member := range members {
+			if member != nil {
+				causes = append(causes, member)
+			}
+		}
+		return causes
+	}
+	return []error{wrapped}
+}
+
+// isJoinedError reports whether err renders exactly as errors.Join renders members
+// Such an error, including one built by joinCauses, has no text of its own to lose
+func isJoinedError(err error, members []error) bool {
+	if _, isCollection := err.(*ErrorCollection); isCollection || len(members) == 0 {
+		return false
+	}
+	messages := make([]string, 0, len(members))
+	for _, member := range members {
+		if member != nil {
+			messages = append(messages, member.Error())
+		}
+	}
+	return err.Error() == strings.Join(messages, "\n")
+}
+
+// causeMessages returns the message of every cause
+func causeMessages(causes []error) []string {
+	messages := make([]string, len(causes))
+	for i, cause := range causes {
+		messages[i] = cause.Error()
+	}
+	return messages
+}
+
+// joinCauseMessages renders causes on a single line
+func joinCauseMessages(causes []error) string {
+	return strings.Join(causeMessages(causes), CAUSE_SEPARATOR)
+}
+
+// causesJSON renders causes as JSON objects, recursing into nested CustomErrors
+// Other errors, including CustomErrors wrapped by fmt.Errorf, become message-only nodes
+func causesJSON(causes []error, depth int) []map[string]interface{} {
+	rendered := make([]map[string]interface{}, 0, len(causes))
+	for _, cause := range causes {
+		node := map[string]interface{}{}
+
+		if customErr, ok := cause.(*CustomError); ok {
+			node[JSON_FIELD_CODE] = customErr.Code
+			node[JSON_FIELD_CATEGORY] = customErr.Category
+			node[JSON_FIELD_MESSAGE] = customErr.Message
+			if nested := customErr.Causes(); len(nested) > 0 && depth < MAX_ERROR_CHAIN_DEPTH {
+				node[JSON_FIELD_CAUSES] = causesJSON(nested, depth+1)
+			}
+		} else {
+			node[JSON_FIELD_MESSAGE] = cause.Error()
+		}
+
+		rendered = append(rendered, node)
+	}
+	return rendered
+}
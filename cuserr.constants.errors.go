@@ -28,6 +28,28 @@ const (
 	ERROR_CODE_EXTERNAL_ERROR = "EXTERNAL_ERROR"
 	// ERROR_CODE_INTERNAL_ERROR represents internal server errors
 	ERROR_CODE_INTERNAL_ERROR = "INTERNAL_ERROR"
+	// ERROR_CODE_UNAVAILABLE represents temporarily unavailable service errors
+	ERROR_CODE_UNAVAILABLE = "UNAVAILABLE"
+	// ERROR_CODE_NOT_IMPLEMENTED represents unimplemented functionality errors
+	ERROR_CODE_NOT_IMPLEMENTED = "NOT_IMPLEMENTED"
+	// ERROR_CODE_GONE represents permanently removed resource errors
+	ERROR_CODE_GONE = "GONE"
+	// ERROR_CODE_PRECONDITION_FAILED represents failed request precondition errors
+	ERROR_CODE_PRECONDITION_FAILED = "PRECONDITION_FAILED"
+	// ERROR_CODE_PAYLOAD_TOO_LARGE represents oversized request payload errors
+	ERROR_CODE_PAYLOAD_TOO_LARGE = "PAYLOAD_TOO_LARGE"
+	// ERROR_CODE_UNSUPPORTED_MEDIA_TYPE represents unsupported content type errors
+	ERROR_CODE_UNSUPPORTED_MEDIA_TYPE = "UNSUPPORTED_MEDIA_TYPE"
+	// ERROR_CODE_UNPROCESSABLE_ENTITY represents semantically invalid request errors
+	ERROR_CODE_UNPROCESSABLE_ENTITY = "UNPROCESSABLE_ENTITY"
+	// ERROR_CODE_METHOD_NOT_ALLOWED represents unsupported HTTP method errors
+	ERROR_CODE_METHOD_NOT_ALLOWED = "METHOD_NOT_ALLOWED"
+	// ERROR_CODE_CLIENT_CLOSED_REQUEST represents requests abandoned by the client
+	ERROR_CODE_CLIENT_CLOSED_REQUEST = "CLIENT_CLOSED_REQUEST"
+	// ERROR_CODE_CIRCUIT_OPEN represents calls rejected by an open circuit breaker
+	ERROR_CODE_CIRCUIT_OPEN = "CIRCUIT_OPEN"
+	// ERROR_CODE_MULTIPLE_ERRORS represents an error collection with several members
+	ERROR_CODE_MULTIPLE_ERRORS = "MULTIPLE_ERRORS"
 
 	// Error category string constants
 
@@ -49,6 +71,24 @@ const (
 	CATEGORY_RATE_LIMIT = "rate_limit"
 	// CATEGORY_EXTERNAL represents external service error category
 	CATEGORY_EXTERNAL = "external"
+	// CATEGORY_UNAVAILABLE represents temporarily unavailable service error category
+	CATEGORY_UNAVAILABLE = "unavailable"
+	// CATEGORY_NOT_IMPLEMENTED represents unimplemented functionality error category
+	CATEGORY_NOT_IMPLEMENTED = "not_implemented"
+	// CATEGORY_GONE represents permanently removed resource error category
+	CATEGORY_GONE = "gone"
+	// CATEGORY_PRECONDITION_FAILED represents failed precondition error category
+	CATEGORY_PRECONDITION_FAILED = "precondition_failed"
+	// CATEGORY_PAYLOAD_TOO_LARGE represents oversized payload error category
+	CATEGORY_PAYLOAD_TOO_LARGE = "payload_too_large"
+	// CATEGORY_UNSUPPORTED_MEDIA_TYPE represents unsupported media type error category
+	CATEGORY_UNSUPPORTED_MEDIA_TYPE = "unsupported_media_type"
+	// CATEGORY_UNPROCESSABLE represents semantically invalid request error category
+	CATEGORY_UNPROCESSABLE = "unprocessable"
+	// CATEGORY_METHOD_NOT_ALLOWED represents unsupported HTTP method error category
+	CATEGORY_METHOD_NOT_ALLOWED = "method_not_allowed"
+	// CATEGORY_CLIENT_CLOSED represents client-abandoned request error category
+	CATEGORY_CLIENT_CLOSED = "client_closed"
 
 	// Sentinel error message constants
 
@@ -70,6 +110,120 @@ const (
 	SENTINEL_MSG_RATE_LIMIT = "rate limit exceeded"
 	// SENTINEL_MSG_EXTERNAL represents default message for external service errors
 	SENTINEL_MSG_EXTERNAL = "external service error"
+	// SENTINEL_MSG_UNAVAILABLE represents default message for unavailable service errors
+	SENTINEL_MSG_UNAVAILABLE = "service unavailable"
+	// SENTINEL_MSG_NOT_IMPLEMENTED represents default message for unimplemented functionality
+	SENTINEL_MSG_NOT_IMPLEMENTED = "not implemented"
+	// SENTINEL_MSG_GONE represents default message for permanently removed resources
+	SENTINEL_MSG_GONE = "resource gone"
+	// SENTINEL_MSG_PRECONDITION_FAILED represents default message for failed preconditions
+	SENTINEL_MSG_PRECONDITION_FAILED = "precondition failed"
+	// SENTINEL_MSG_PAYLOAD_TOO_LARGE represents default message for oversized payloads
+	SENTINEL_MSG_PAYLOAD_TOO_LARGE = "payload too large"
+	// SENTINEL_MSG_UNSUPPORTED_MEDIA_TYPE represents default message for unsupported media types
+	SENTINEL_MSG_UNSUPPORTED_MEDIA_TYPE = "unsupported media type"
+	// SENTINEL_MSG_UNPROCESSABLE represents default message for unprocessable entities
+	SENTINEL_MSG_UNPROCESSABLE = "unprocessable entity"
+	// SENTINEL_MSG_METHOD_NOT_ALLOWED represents default message for unsupported methods
+	SENTINEL_MSG_METHOD_NOT_ALLOWED = "method not allowed"
+	// SENTINEL_MSG_CLIENT_CLOSED represents default message for client-abandoned requests
+	SENTINEL_MSG_CLIENT_CLOSED = "client closed request"
+	// SENTINEL_MSG_CIRCUIT_OPEN represents default message for calls rejected by an open circuit
+	SENTINEL_MSG_CIRCUIT_OPEN = "circuit breaker open"
+
+	// Registry error message constants
+
+	// REGISTRY_MSG_DUPLICATE_CODE represents the message for duplicate error code registrations
+	REGISTRY_MSG_DUPLICATE_CODE = "error code already registered"
+	// REGISTRY_MSG_SENTINEL_REGISTERED represents the message for conflicting sentinel registrations
+	REGISTRY_MSG_SENTINEL_REGISTERED = "sentinel already registered"
+	// REGISTRY_MSG_INVALID_SENTINEL represents the message for invalid sentinel registrations
+	REGISTRY_MSG_INVALID_SENTINEL = "invalid sentinel registration"
+	// REGISTRY_MSG_INVALID_CATEGORY represents the message for invalid category definitions
+	REGISTRY_MSG_INVALID_CATEGORY = "invalid category definition"
+	// REGISTRY_MSG_INVALID_PROBLEM_TYPE represents the message for invalid problem type registrations
+	REGISTRY_MSG_INVALID_PROBLEM_TYPE = "invalid problem type"
+	// RPC_MSG_INVALID_CODE represents the message for unparseable RPC codes
+	RPC_MSG_INVALID_CODE = "invalid RPC code"
+	// REGISTRY_MSG_INVALID_FORMATTER represents the message for invalid response formatter registrations
+	REGISTRY_MSG_INVALID_FORMATTER = "invalid response formatter"
+
+	// Client-safe message constants
+
+	// SAFE_MSG_INTERNAL represents the production message for internal errors
+	SAFE_MSG_INTERNAL = "An internal error occurred"
+	// SAFE_MSG_UNAVAILABLE represents the production message for dependency failures
+	SAFE_MSG_UNAVAILABLE = "A service is temporarily unavailable"
+	// SAFE_MSG_DEFAULT represents the production message for hidden errors without a safe message
+	SAFE_MSG_DEFAULT = "An error occurred"
+
+	// Severity names
+
+	// SEVERITY_DEBUG represents the name of the debug severity
+	SEVERITY_DEBUG = "debug"
+	// SEVERITY_INFO represents the name of the info severity
+	SEVERITY_INFO = "info"
+	// SEVERITY_NOTICE represents the name of the notice severity
+	SEVERITY_NOTICE = "notice"
+	// SEVERITY_WARNING represents the name of the warning severity
+	SEVERITY_WARNING = "warning"
+	// SEVERITY_ERROR represents the name of the error severity
+	SEVERITY_ERROR = "error"
+	// SEVERITY_CRITICAL represents the name of the critical severity
+	SEVERITY_CRITICAL = "critical"
+	// SEVERITY_FATAL represents the name of the fatal severity
+	SEVERITY_FATAL = "fatal"
+	// SEVERITY_MSG_INVALID represents the message for unknown severity names
+	SEVERITY_MSG_INVALID = "invalid severity"
+
+	// TEMPLATE_PARAM_OPEN marks the start of a placeholder in definition message templates
+	TEMPLATE_PARAM_OPEN = "{"
+	// TEMPLATE_PARAM_CLOSE marks the end of a placeholder in definition message templates
+	TEMPLATE_PARAM_CLOSE = "}"
+
+	// METADATA_MSG_NOT_FOUND represents the message for missing metadata keys
+	METADATA_MSG_NOT_FOUND = "metadata key not found"
+	// METADATA_MSG_DECODE_FAILED represents the message for metadata values of the wrong type
+	METADATA_MSG_DECODE_FAILED = "metadata value cannot be decoded"
+	// METADATA_LIST_SEPARATOR joins list values when metadata is formatted as a string
+	METADATA_LIST_SEPARATOR = ","
+
+	// Retry policy defaults
+
+	// RETRY_DEFAULT_MAX_ATTEMPTS defines the default number of attempts, including the first
+	RETRY_DEFAULT_MAX_ATTEMPTS = 3
+	// RETRY_DEFAULT_INITIAL_DELAY_MS defines the default delay before the first retry
+	RETRY_DEFAULT_INITIAL_DELAY_MS = 100
+	// RETRY_DEFAULT_MAX_DELAY_MS defines the default upper bound for backoff delays
+	RETRY_DEFAULT_MAX_DELAY_MS = 10000
+	// RETRY_DEFAULT_MULTIPLIER defines the default backoff growth factor
+	RETRY_DEFAULT_MULTIPLIER = 2.0
+	// RETRY_DEFAULT_JITTER defines the default fraction of each delay that is randomized
+	RETRY_DEFAULT_JITTER = 0.2
+	// RETRY_MSG_FAILED represents the summary of collections returned by Retry
+	RETRY_MSG_FAILED = "retry attempts failed"
+
+	// Circuit breaker defaults
+
+	// BREAKER_DEFAULT_WINDOW_MS defines the default sliding window for counting failures
+	BREAKER_DEFAULT_WINDOW_MS = 60000
+	// BREAKER_DEFAULT_FAILURE_THRESHOLD defines the default number of failures that opens a circuit
+	BREAKER_DEFAULT_FAILURE_THRESHOLD = 5
+	// BREAKER_DEFAULT_FAILURE_RATIO defines the default failure ratio that opens a circuit
+	BREAKER_DEFAULT_FAILURE_RATIO = 0.5
+	// BREAKER_DEFAULT_OPEN_TIMEOUT_MS defines the default time a circuit stays open before probing
+	BREAKER_DEFAULT_OPEN_TIMEOUT_MS = 30000
+	// BREAKER_DEFAULT_HALF_OPEN_REQUESTS defines the default number of concurrent probes when half-open
+	BREAKER_DEFAULT_HALF_OPEN_REQUESTS = 1
+	// BREAKER_STATE_CLOSED represents the name of the closed breaker state
+	BREAKER_STATE_CLOSED = "closed"
+	// BREAKER_STATE_OPEN represents the name of the open breaker state
+	BREAKER_STATE_OPEN = "open"
+	// BREAKER_STATE_HALF_OPEN represents the name of the half-open breaker state
+	BREAKER_STATE_HALF_OPEN = "half_open"
+
+	// MAX_ERROR_CHAIN_DEPTH limits error chain traversal to prevent runaway recursion
+	MAX_ERROR_CHAIN_DEPTH = 100
 
 	// Stack trace configuration constants
 
@@ -94,6 +248,138 @@ const (
 	JSON_FIELD_REQUEST_ID = "request_id"
 	// JSON_FIELD_TIMESTAMP defines the JSON field name for timestamps
 	JSON_FIELD_TIMESTAMP = "timestamp"
+	// JSON_FIELD_CAUSES defines the JSON field name for the causes of an error
+	JSON_FIELD_CAUSES = "causes"
+	// JSON_FIELD_RETRYABLE defines the JSON field name for the retryable flag
+	JSON_FIELD_RETRYABLE = "retryable"
+	// JSON_FIELD_RETRY_AFTER_SECONDS defines the JSON field name for retry hints
+	JSON_FIELD_RETRY_AFTER_SECONDS = "retry_after_seconds"
+	// JSON_FIELD_ERRORS defines the JSON field name for the errors of a collection
+	JSON_FIELD_ERRORS = "errors"
+	// JSON_FIELD_ERROR_COUNT defines the JSON field name for the error count of a collection
+	JSON_FIELD_ERROR_COUNT = "error_count"
+	// JSON_FIELD_SUMMARY defines the JSON field name for the summary of a collection
+	JSON_FIELD_SUMMARY = "summary"
+	// JSON_FIELD_CONTEXT defines the JSON field name for the context of a collection
+	JSON_FIELD_CONTEXT = "context"
+	// JSON_FIELD_VALIDATION_ERRORS defines the JSON field name for validation errors
+	JSON_FIELD_VALIDATION_ERRORS = "validation_errors"
+	// JSON_FIELD_VALIDATION_COUNT defines the JSON field name for the validation error count
+	JSON_FIELD_VALIDATION_COUNT = "validation_count"
+	// JSON_FIELD_FIELD defines the JSON field name for the field of a validation error
+	JSON_FIELD_FIELD = "field"
+	// JSON_FIELD_VALUE defines the JSON field name for the value of a validation error
+	JSON_FIELD_VALUE = "value"
+
+	// RFC 9457 problem details
+
+	// CONTENT_TYPE_PROBLEM_JSON defines the media type of problem details responses
+	CONTENT_TYPE_PROBLEM_JSON = "application/problem+json"
+	// PROBLEM_TYPE_DEFAULT defines the problem type used when no type URI is configured
+	PROBLEM_TYPE_DEFAULT = "about:blank"
+	// PROBLEM_INSTANCE_PREFIX defines the URN prefix used to derive instance from the request ID
+	PROBLEM_INSTANCE_PREFIX = "urn:request:"
+	// JSON_FIELD_TYPE defines the JSON field name for problem types
+	JSON_FIELD_TYPE = "type"
+	// JSON_FIELD_TITLE defines the JSON field name for problem titles
+	JSON_FIELD_TITLE = "title"
+	// JSON_FIELD_STATUS defines the JSON field name for problem HTTP statuses
+	JSON_FIELD_STATUS = "status"
+	// JSON_FIELD_DETAIL defines the JSON field name for problem details
+	JSON_FIELD_DETAIL = "detail"
+	// JSON_FIELD_INSTANCE defines the JSON field name for problem instances
+	JSON_FIELD_INSTANCE = "instance"
+	// JSON_FIELD_POINTER defines the JSON field name for JSON pointers to invalid fields
+	JSON_FIELD_POINTER = "pointer"
+
+	// JSON:API error documents
+
+	// CONTENT_TYPE_JSONAPI defines the JSON:API media type
+	CONTENT_TYPE_JSONAPI = "application/vnd.api+json"
+	// JSONAPI_ATTRIBUTES_POINTER defines the JSON pointer prefix of resource attributes
+	JSONAPI_ATTRIBUTES_POINTER = "/data/attributes"
+
+	// GraphQL error extensions
+
+	// GRAPHQL_CODE_BAD_USER_INPUT is the GraphQL extension code for invalid input
+	GRAPHQL_CODE_BAD_USER_INPUT = "BAD_USER_INPUT"
+	// GRAPHQL_CODE_UNAUTHENTICATED is the GraphQL extension code for missing authentication
+	GRAPHQL_CODE_UNAUTHENTICATED = "UNAUTHENTICATED"
+	// GRAPHQL_CODE_FORBIDDEN is the GraphQL extension code for denied access
+	GRAPHQL_CODE_FORBIDDEN = "FORBIDDEN"
+	// GRAPHQL_CODE_NOT_FOUND is the GraphQL extension code for missing resources
+	GRAPHQL_CODE_NOT_FOUND = "NOT_FOUND"
+	// GRAPHQL_CODE_INTERNAL_SERVER_ERROR is the GraphQL extension code for every other error
+	GRAPHQL_CODE_INTERNAL_SERVER_ERROR = "INTERNAL_SERVER_ERROR"
+	// JSON_FIELD_ERROR_CODE defines the JSON field name for the error code next to a protocol code
+	JSON_FIELD_ERROR_CODE = "error_code"
+	// JSON_FIELD_FIELD_VIOLATIONS defines the Twirp meta key holding the field violations of a collection
+	JSON_FIELD_FIELD_VIOLATIONS = "field_violations"
+
+	// google.rpc.Status detail types
+
+	// RPC_TYPE_ERROR_INFO defines the type URL of google.rpc.ErrorInfo details
+	RPC_TYPE_ERROR_INFO = "type.googleapis.com/google.rpc.ErrorInfo"
+	// RPC_TYPE_BAD_REQUEST defines the type URL of google.rpc.BadRequest details
+	RPC_TYPE_BAD_REQUEST = "type.googleapis.com/google.rpc.BadRequest"
+	// RPC_TYPE_RETRY_INFO defines the type URL of google.rpc.RetryInfo details
+	RPC_TYPE_RETRY_INFO = "type.googleapis.com/google.rpc.RetryInfo"
+
+	// HTTP error responses
+
+	// CONTENT_TYPE_JSON defines the media type of native JSON error responses
+	CONTENT_TYPE_JSON = "application/json"
+	// CONTENT_TYPE_XML defines the media type of XML error responses
+	CONTENT_TYPE_XML = "application/xml; charset=utf-8"
+	// CONTENT_TYPE_TEXT_XML defines the legacy text/xml media type of XML error responses
+	CONTENT_TYPE_TEXT_XML = "text/xml; charset=utf-8"
+	// CONTENT_TYPE_TEXT defines the media type of plain-text error responses
+	CONTENT_TYPE_TEXT = "text/plain; charset=utf-8"
+	// CONTENT_TYPE_HTML defines the media type of HTML error pages
+	CONTENT_TYPE_HTML = "text/html; charset=utf-8"
+	// HTTP_HEADER_ACCEPT defines the request header used for content negotiation
+	HTTP_HEADER_ACCEPT = "Accept"
+	// HTTP_HEADER_CONTENT_TYPE defines the Content-Type response header
+	HTTP_HEADER_CONTENT_TYPE = "Content-Type"
+	// HTTP_HEADER_CONTENT_LENGTH defines the Content-Length response header
+	HTTP_HEADER_CONTENT_LENGTH = "Content-Length"
+	// HTTP_HEADER_CONTENT_TYPE_OPTIONS defines the header that disables MIME sniffing
+	HTTP_HEADER_CONTENT_TYPE_OPTIONS = "X-Content-Type-Options"
+	// HTTP_HEADER_VARY defines the Vary response header
+	HTTP_HEADER_VARY = "Vary"
+
+	// HTTP error headers
+
+	// HTTP_HEADER_RETRY_AFTER defines the Retry-After response header
+	HTTP_HEADER_RETRY_AFTER = "Retry-After"
+	// HTTP_HEADER_RATELIMIT_LIMIT defines the RateLimit-Limit response header
+	HTTP_HEADER_RATELIMIT_LIMIT = "RateLimit-Limit"
+	// HTTP_HEADER_RATELIMIT_REMAINING defines the RateLimit-Remaining response header
+	HTTP_HEADER_RATELIMIT_REMAINING = "RateLimit-Remaining"
+	// HTTP_HEADER_RATELIMIT_RESET defines the RateLimit-Reset response header
+	HTTP_HEADER_RATELIMIT_RESET = "RateLimit-Reset"
+	// HTTP_HEADER_RATELIMIT_POLICY defines the RateLimit-Policy response header
+	HTTP_HEADER_RATELIMIT_POLICY = "RateLimit-Policy"
+	// HTTP_HEADER_WWW_AUTHENTICATE defines the WWW-Authenticate response header
+	HTTP_HEADER_WWW_AUTHENTICATE = "WWW-Authenticate"
+	// HTTP_HEADER_ALLOW defines the Allow response header
+	HTTP_HEADER_ALLOW = "Allow"
+	// HTTP_HEADER_REQUEST_ID defines the response header echoing the request ID
+	HTTP_HEADER_REQUEST_ID = "X-Request-ID"
+	// AUTH_CHALLENGE_DEFAULT defines the default WWW-Authenticate challenge
+	AUTH_CHALLENGE_DEFAULT = "Bearer"
+
+	// Remote error responses
+
+	// HTTP_RESPONSE_MAX_BODY_BYTES defines how much of an error response body FromHTTPResponse reads by default
+	HTTP_RESPONSE_MAX_BODY_BYTES = 1 << 20
+
+	// JSON writer buffers
+
+	// JSON_WRITER_INITIAL_BUFFER defines the initial capacity of pooled JSON writer buffers
+	JSON_WRITER_INITIAL_BUFFER = 1024
+	// JSON_WRITER_MAX_POOLED_BUFFER defines the largest buffer returned to the JSON writer pool
+	JSON_WRITER_MAX_POOLED_BUFFER = 64 * 1024
 
 	// HTTP status codes
 
@@ -105,16 +391,38 @@ const (
 	HTTP_STATUS_FORBIDDEN = 403
 	// HTTP_STATUS_NOT_FOUND represents HTTP 404 Not Found status
 	HTTP_STATUS_NOT_FOUND = 404
+	// HTTP_STATUS_METHOD_NOT_ALLOWED represents HTTP 405 Method Not Allowed status
+	HTTP_STATUS_METHOD_NOT_ALLOWED = 405
 	// HTTP_STATUS_REQUEST_TIMEOUT represents HTTP 408 Request Timeout status
 	HTTP_STATUS_REQUEST_TIMEOUT = 408
 	// HTTP_STATUS_CONFLICT represents HTTP 409 Conflict status
 	HTTP_STATUS_CONFLICT = 409
+	// HTTP_STATUS_GONE represents HTTP 410 Gone status
+	HTTP_STATUS_GONE = 410
+	// HTTP_STATUS_PRECONDITION_FAILED represents HTTP 412 Precondition Failed status
+	HTTP_STATUS_PRECONDITION_FAILED = 412
+	// HTTP_STATUS_PAYLOAD_TOO_LARGE represents HTTP 413 Payload Too Large status
+	HTTP_STATUS_PAYLOAD_TOO_LARGE = 413
+	// HTTP_STATUS_UNSUPPORTED_MEDIA_TYPE represents HTTP 415 Unsupported Media Type status
+	HTTP_STATUS_UNSUPPORTED_MEDIA_TYPE = 415
+	// HTTP_STATUS_UNPROCESSABLE_ENTITY represents HTTP 422 Unprocessable Entity status
+	HTTP_STATUS_UNPROCESSABLE_ENTITY = 422
 	// HTTP_STATUS_TOO_MANY_REQUESTS represents HTTP 429 Too Many Requests status
 	HTTP_STATUS_TOO_MANY_REQUESTS = 429
+	// HTTP_STATUS_CLIENT_CLOSED_REQUEST represents the non-standard HTTP 499 Client Closed Request status
+	HTTP_STATUS_CLIENT_CLOSED_REQUEST = 499
 	// HTTP_STATUS_INTERNAL_SERVER_ERROR represents HTTP 500 Internal Server Error status
 	HTTP_STATUS_INTERNAL_SERVER_ERROR = 500
+	// HTTP_STATUS_NOT_IMPLEMENTED represents HTTP 501 Not Implemented status
+	HTTP_STATUS_NOT_IMPLEMENTED = 501
 	// HTTP_STATUS_BAD_GATEWAY represents HTTP 502 Bad Gateway status
 	HTTP_STATUS_BAD_GATEWAY = 502
+	// HTTP_STATUS_SERVICE_UNAVAILABLE represents HTTP 503 Service Unavailable status
+	HTTP_STATUS_SERVICE_UNAVAILABLE = 503
+	// HTTP_STATUS_GATEWAY_TIMEOUT represents HTTP 504 Gateway Timeout status
+	HTTP_STATUS_GATEWAY_TIMEOUT = 504
+	// HTTP_STATUS_MAX_ERROR represents the highest valid HTTP error status
+	HTTP_STATUS_MAX_ERROR = 599
 	// HTTP_STATUS_DEFAULT_ERROR represents the default HTTP status for errors
 	HTTP_STATUS_DEFAULT_ERROR = 500
 
@@ -132,6 +440,24 @@ const (
 	LOG_TEMPLATE_REQUEST_ID = "RequestID: %s"
 	// LOG_TEMPLATE_WRAPPED_ERROR defines template for wrapped error logging
 	LOG_TEMPLATE_WRAPPED_ERROR = "Wrapped: %v"
+	// LOG_TEMPLATE_CAUSE defines template for one of several causes in detailed error information
+	LOG_TEMPLATE_CAUSE = "  Cause %d: %v"
+	// FORMAT_TEMPLATE_FIELD defines template for a labelled field in %+v output
+	FORMAT_TEMPLATE_FIELD = "\n%s: %s"
+	// FORMAT_TEMPLATE_METADATA defines template for a metadata entry in %+v output
+	FORMAT_TEMPLATE_METADATA = "\n    %s: %s"
+	// FORMAT_TEMPLATE_STACK_FRAME defines template for a stack frame in %+v output, matching pkg/errors
+	FORMAT_TEMPLATE_STACK_FRAME = "\n%s\n\t%s:%d"
+	// FORMAT_TEMPLATE_VALIDATION defines template for a validation error in %+v output
+	FORMAT_TEMPLATE_VALIDATION = "\n  - %s: %s"
+	// FORMAT_TEMPLATE_MEMBER defines template for the header of a collection member in %+v output
+	FORMAT_TEMPLATE_MEMBER = "\n[%d] "
+	// FORMAT_TEMPLATE_BAD_VERB defines template for unsupported format verbs, matching fmt
+	FORMAT_TEMPLATE_BAD_VERB = "%%!%c(%s=%s)"
+	// FORMAT_CAUSED_BY introduces each cause in %+v output
+	FORMAT_CAUSED_BY = "\ncaused by: "
+	// CAUSE_SEPARATOR joins the messages of several causes on a single line
+	CAUSE_SEPARATOR = "; "
 
 	// Configuration environment variables
 
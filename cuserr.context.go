@@ -14,6 +14,8 @@ const (
 	ConfigContextKey contextKey = "cuserr_config"
 	// ErrorHandlerContextKey is the context key for custom error handlers
 	ErrorHandlerContextKey contextKey = "cuserr_error_handler"
+	// RequestIDContextKey is the context key for request IDs set with ContextWithRequestID
+	RequestIDContextKey contextKey = "cuserr_request_id"
 )
 
 // ContextConfig holds error configuration that can be passed via context
@@ -177,7 +179,7 @@ func (b *ContextualErrorBuilder) Build() *CustomError {
 		if userID := GetUserIDFromContext(b.ctx); userID != "" {
 			// Add user_id to the ErrorBuilder's metadata map
 			if b.ErrorBuilder.metadata == nil {
-				b.ErrorBuilder.metadata = make(map[string]string)
+				b.ErrorBuilder.metadata = make(map[string]interface{})
 			}
 			b.ErrorBuilder.metadata["user_id"] = userID
 		}
@@ -186,7 +188,7 @@ func (b *ContextualErrorBuilder) Build() *CustomError {
 		}
 		if traceID := GetTraceIDFromContext(b.ctx); traceID != "" {
 			if b.ErrorBuilder.metadata == nil {
-				b.ErrorBuilder.metadata = make(map[string]string)
+				b.ErrorBuilder.metadata = make(map[string]interface{})
 			}
 			b.ErrorBuilder.metadata["trace_id"] = traceID
 		}
@@ -202,12 +204,25 @@ func (b *ContextualErrorBuilder) Build() *CustomError {
 
 // Context value extractors
 
+// ContextWithRequestID returns a context carrying the request ID under RequestIDContextKey
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, RequestIDContextKey, requestID)
+}
+
 // GetRequestIDFromContext extracts request ID from context
+// RequestIDContextKey is checked before the common string keys
 func GetRequestIDFromContext(ctx context.Context) string {
 	if ctx == nil {
 		return ""
 	}
 
+	if value, ok := ctx.Value(RequestIDContextKey).(string); ok && value != "" {
+		return value
+	}
+
 	// Try multiple common context keys
 	keys := []string{"request_id", "requestID", "req_id", "x-request-id"}
 	for _, key := range keys {
@@ -261,17 +276,17 @@ func enrichFromContext(ctx context.Context, err *CustomError) *CustomError {
 
 	// Extract request ID
 	if requestID := GetRequestIDFromContext(ctx); requestID != "" {
-		err.WithRequestID(requestID)
+		err = err.WithRequestID(requestID)
 	}
 
 	// Extract user ID
 	if userID := GetUserIDFromContext(ctx); userID != "" {
-		err.WithMetadata("user_id", userID)
+		err = err.WithMetadata("user_id", userID)
 	}
 
 	// Extract trace ID
 	if traceID := GetTraceIDFromContext(ctx); traceID != "" {
-		err.WithMetadata("trace_id", traceID)
+		err = err.WithMetadata("trace_id", traceID)
 	}
 
 	// Handle error if handler is set
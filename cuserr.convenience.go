@@ -5,6 +5,7 @@ package cuserr
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 // Convenience constructors for common error patterns
@@ -36,7 +37,7 @@ func NewNotFoundError(resource, id string) *CustomError {
 		WithMetadata("error_type", "not_found")
 
 	if id != "" {
-		err.WithMetadata("resource_id", id)
+		err = err.WithMetadata("resource_id", id)
 	}
 
 	return err
@@ -53,7 +54,7 @@ func NewUnauthorizedError(reason string) *CustomError {
 		WithMetadata("error_type", "unauthorized")
 
 	if reason != "" {
-		err.WithMetadata("reason", reason)
+		err = err.WithMetadata("reason", reason)
 	}
 
 	return err
@@ -70,10 +71,10 @@ func NewForbiddenError(action, resource string) *CustomError {
 		WithMetadata("error_type", "forbidden")
 
 	if action != "" {
-		err.WithMetadata("action", action)
+		err = err.WithMetadata("action", action)
 	}
 	if resource != "" {
-		err.WithMetadata("resource", resource)
+		err = err.WithMetadata("resource", resource)
 	}
 
 	return err
@@ -91,10 +92,10 @@ func NewConflictError(resource, field, value string) *CustomError {
 		WithMetadata("error_type", "conflict")
 
 	if field != "" {
-		err.WithMetadata("conflict_field", field)
+		err = err.WithMetadata("conflict_field", field)
 	}
 	if value != "" {
-		err.WithMetadata("conflict_value", value)
+		err = err.WithMetadata("conflict_value", value)
 	}
 
 	return err
@@ -111,7 +112,7 @@ func NewInternalError(component string, wrapped error) *CustomError {
 		WithMetadata("error_type", "internal")
 
 	if component != "" {
-		err.WithMetadata("component", component)
+		err = err.WithMetadata("component", component)
 	}
 
 	return err
@@ -128,10 +129,10 @@ func NewExternalError(service, operation string, wrapped error) *CustomError {
 		WithMetadata("error_type", "external")
 
 	if service != "" {
-		err.WithMetadata("service", service)
+		err = err.WithMetadata("service", service)
 	}
 	if operation != "" {
-		err.WithMetadata("operation", operation)
+		err = err.WithMetadata("operation", operation)
 	}
 
 	return err
@@ -148,13 +149,16 @@ func NewTimeoutError(operation string, wrapped error) *CustomError {
 		WithMetadata("error_type", "timeout")
 
 	if operation != "" {
-		err.WithMetadata("operation", operation)
+		err = err.WithMetadata("operation", operation)
 	}
 
 	return err
 }
 
 // NewRateLimitError creates a rate limit error with limit information
+// The RateLimit-Policy and RateLimit-Reset headers need an integer limit and a
+// window given as a Go duration ("1m") or a unit word with an optional count
+// ("hour", "15 minutes"); other windows are kept only as metadata
 func NewRateLimitError(limit, window string) *CustomError {
 	message := "rate limit exceeded"
 	if limit != "" && window != "" {
@@ -165,10 +169,187 @@ func NewRateLimitError(limit, window string) *CustomError {
 		WithMetadata("error_type", "rate_limit")
 
 	if limit != "" {
-		err.WithMetadata("limit", limit)
+		err = err.WithMetadata(MetaRateLimit, limit)
 	}
 	if window != "" {
-		err.WithMetadata("window", window)
+		err = err.WithMetadata(MetaRateLimitWindow, window)
+	}
+
+	return err
+}
+
+// NewUnavailableError creates a service unavailable error with optional reason
+func NewUnavailableError(service, reason string) *CustomError {
+	message := "service unavailable"
+	if service != "" {
+		message = fmt.Sprintf("service '%s' unavailable", service)
+	}
+	if reason != "" {
+		message = fmt.Sprintf("%s: %s", message, reason)
+	}
+
+	err := NewCustomError(ErrUnavailable, nil, message).
+		WithMetadata("error_type", "unavailable")
+
+	if service != "" {
+		err = err.WithMetadata(MetaService, service)
+	}
+	if reason != "" {
+		err = err.WithMetadata(MetaReason, reason)
+	}
+
+	return err
+}
+
+// NewNotImplementedError creates a not implemented error for a feature
+func NewNotImplementedError(feature string) *CustomError {
+	message := "not implemented"
+	if feature != "" {
+		message = fmt.Sprintf("%s is not implemented", feature)
+	}
+
+	err := NewCustomError(ErrNotImplemented, nil, message).
+		WithMetadata("error_type", "not_implemented")
+
+	if feature != "" {
+		err = err.WithMetadata(MetaFeature, feature)
+	}
+
+	return err
+}
+
+// NewGoneError creates an error for a permanently removed resource
+func NewGoneError(resource, id string) *CustomError {
+	message := fmt.Sprintf("%s no longer exists", resource)
+	if id != "" {
+		message = fmt.Sprintf("%s with id '%s' no longer exists", resource, id)
+	}
+
+	err := NewCustomError(ErrGone, nil, message).
+		WithMetadata("resource", resource).
+		WithMetadata("error_type", "gone")
+
+	if id != "" {
+		err = err.WithMetadata("resource_id", id)
+	}
+
+	return err
+}
+
+// NewPreconditionFailedError creates an error for an unmet request precondition
+func NewPreconditionFailedError(condition string) *CustomError {
+	message := "precondition failed"
+	if condition != "" {
+		message = fmt.Sprintf("precondition failed: %s", condition)
+	}
+
+	err := NewCustomError(ErrPreconditionFailed, nil, message).
+		WithMetadata("error_type", "precondition_failed")
+
+	if condition != "" {
+		err = err.WithMetadata(MetaPrecondition, condition)
+	}
+
+	return err
+}
+
+// NewPayloadTooLargeError creates an error for a request body exceeding the size limit
+// Sizes are in bytes; pass zero for unknown values
+func NewPayloadTooLargeError(limit, size int64) *CustomError {
+	message := "payload too large"
+	if limit > 0 {
+		message = fmt.Sprintf("payload too large: limit is %d bytes", limit)
+	}
+
+	err := NewCustomError(ErrPayloadTooLarge, nil, message).
+		WithMetadata("error_type", "payload_too_large")
+
+	if limit > 0 {
+		err = KeyPayloadLimit.Set(err, limit)
+	}
+	if size > 0 {
+		err = KeyPayloadSize.Set(err, size)
+	}
+
+	return err
+}
+
+// NewUnsupportedMediaTypeError creates an error for an unsupported request content type
+func NewUnsupportedMediaTypeError(mediaType string, supported ...string) *CustomError {
+	message := "unsupported media type"
+	if mediaType != "" {
+		message = fmt.Sprintf("unsupported media type '%s'", mediaType)
+	}
+
+	err := NewCustomError(ErrUnsupportedMediaType, nil, message).
+		WithMetadata("error_type", "unsupported_media_type")
+
+	if mediaType != "" {
+		err = err.WithMetadata(MetaMediaType, mediaType)
+	}
+	if len(supported) > 0 {
+		err = err.WithMetadata(MetaSupportedMediaTypes, strings.Join(supported, ", "))
+	}
+
+	return err
+}
+
+// NewUnprocessableError creates an error for a well-formed request that cannot be processed
+func NewUnprocessableError(entity, reason string) *CustomError {
+	message := "unprocessable entity"
+	if entity != "" && reason != "" {
+		message = fmt.Sprintf("cannot process %s: %s", entity, reason)
+	} else if entity != "" {
+		message = fmt.Sprintf("cannot process %s", entity)
+	}
+
+	err := NewCustomError(ErrUnprocessable, nil, message).
+		WithMetadata("error_type", "unprocessable")
+
+	if entity != "" {
+		err = err.WithMetadata(MetaEntity, entity)
+	}
+	if reason != "" {
+		err = err.WithMetadata(MetaReason, reason)
+	}
+
+	return err
+}
+
+// NewMethodNotAllowedError creates an error for an unsupported HTTP method
+// The allowed methods are recorded for the Allow response header
+func NewMethodNotAllowedError(method string, allowed ...string) *CustomError {
+	message := "method not allowed"
+	if method != "" {
+		message = fmt.Sprintf("method %s not allowed", method)
+	}
+
+	err := NewCustomError(ErrMethodNotAllowed, nil, message).
+		WithMetadata("error_type", "method_not_allowed")
+
+	if method != "" {
+		err = err.WithMetadata(MetaMethod, method)
+	}
+	if len(allowed) > 0 {
+		err = err.WithMetadata(MetaAllowedMethods, strings.Join(allowed, ", "))
+	}
+
+	return err
+}
+
+// NewClientClosedError creates an error for a request abandoned by the client
+// Typically wraps context.Canceled
+func NewClientClosedError(operation string, wrapped error) *CustomError {
+	message := "client closed request"
+	if operation != "" {
+		message = fmt.Sprintf("client closed request during %s", operation)
+	}
+
+	err := NewCustomError(ErrClientClosed, wrapped, message).
+		WithMetadata("error_type", "client_closed")
+
+	if operation != "" {
+		err = err.WithMetadata("operation", operation)
 	}
 
 	return err
@@ -230,6 +411,64 @@ func NewForbiddenErrorFromContext(ctx context.Context, action, resource string)
 	return enrichFromContext(ctx, err)
 }
 
+// NewUnavailableErrorFromContext creates a service unavailable error with request context
+func NewUnavailableErrorFromContext(ctx context.Context, service, reason string) *CustomError {
+	err := NewUnavailableError(service, reason)
+	return enrichFromContext(ctx, err)
+}
+
+// NewNotImplementedErrorFromContext creates a not implemented error with request context
+func NewNotImplementedErrorFromContext(ctx context.Context, feature string) *CustomError {
+	err := NewNotImplementedError(feature)
+	return enrichFromContext(ctx, err)
+}
+
+// NewGoneErrorFromContext creates a gone error with request context
+func NewGoneErrorFromContext(ctx context.Context, resource, id string) *CustomError {
+	err := NewGoneError(resource, id)
+	return enrichFromContext(ctx, err)
+}
+
+// NewPreconditionFailedErrorFromContext creates a precondition failed error with request context
+func NewPreconditionFailedErrorFromContext(ctx context.Context, condition string) *CustomError {
+	err := NewPreconditionFailedError(condition)
+	return enrichFromContext(ctx, err)
+}
+
+// NewPayloadTooLargeErrorFromContext creates a payload too large error with request context
+func NewPayloadTooLargeErrorFromContext(ctx context.Context, limit, size int64) *CustomError {
+	err := NewPayloadTooLargeError(limit, size)
+	return enrichFromContext(ctx, err)
+}
+
+// NewUnsupportedMediaTypeErrorFromContext creates an unsupported media type error with request context
+func NewUnsupportedMediaTypeErrorFromContext(ctx context.Context, mediaType string, supported ...string) *CustomError {
+	err := NewUnsupportedMediaTypeError(mediaType, supported...)
+	return enrichFromContext(ctx, err)
+}
+
+// NewUnprocessableErrorFromContext creates an unprocessable entity error with request context
+func NewUnprocessableErrorFromContext(ctx context.Context, entity, reason string) *CustomError {
+	err := NewUnprocessableError(entity, reason)
+	return enrichFromContext(ctx, err)
+}
+
+// NewMethodNotAllowedErrorFromContext creates a method not allowed error with request context
+func NewMethodNotAllowedErrorFromContext(ctx context.Context, method string, allowed ...string) *CustomError {
+	err := NewMethodNotAllowedError(method, allowed...)
+	return enrichFromContext(ctx, err)
+}
+
+// NewClientClosedErrorFromContext creates a client closed error with request context
+// The context error is wrapped when no explicit cause is given
+func NewClientClosedErrorFromContext(ctx context.Context, operation string, wrapped error) *CustomError {
+	if wrapped == nil && ctx != nil {
+		wrapped = ctx.Err()
+	}
+	err := NewClientClosedError(operation, wrapped)
+	return enrichFromContext(ctx, err)
+}
+
 // Error builder pattern for complex errors
 
 // ErrorBuilder provides a fluent interface for building complex errors
@@ -237,7 +476,7 @@ type ErrorBuilder struct {
 	sentinel  error
 	wrapped   error
 	message   string
-	metadata  map[string]string
+	metadata  map[string]interface{}
 	requestID string
 }
 
@@ -245,7 +484,7 @@ type ErrorBuilder struct {
 func NewErrorBuilder(sentinel error) *ErrorBuilder {
 	return &ErrorBuilder{
 		sentinel: sentinel,
-		metadata: make(map[string]string),
+		metadata: make(map[string]interface{}),
 	}
 }
 
@@ -273,6 +512,12 @@ func (b *ErrorBuilder) WithMetadata(key, value string) *ErrorBuilder {
 	return b
 }
 
+// WithMetadataValue adds typed metadata
+func (b *ErrorBuilder) WithMetadataValue(key string, value interface{}) *ErrorBuilder {
+	b.metadata[key] = value
+	return b
+}
+
 // WithRequestID sets the request ID
 func (b *ErrorBuilder) WithRequestID(requestID string) *ErrorBuilder {
 	b.requestID = requestID
@@ -305,11 +550,11 @@ func (b *ErrorBuilder) Build() *CustomError {
 	err := NewCustomError(b.sentinel, b.wrapped, b.message)
 
 	if b.requestID != "" {
-		err.WithRequestID(b.requestID)
+		err = err.WithRequestID(b.requestID)
 	}
 
 	for key, value := range b.metadata {
-		err.WithMetadata(key, value)
+		err = err.WithMetadataValue(key, value)
 	}
 
 	return err
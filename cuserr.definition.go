@@ -0,0 +1,153 @@
+// Package cuserr provides declarative error definitions.
+// This file contains Define, which documents an error code once and instantiates it many times.
+package cuserr
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Definition describes an error code declared once at package level
+// A Definition is itself an error and is registered as a sentinel, so
+// errors.Is(err, definition) matches every error created from it.
+//
+//	var ErrUserSuspended = cuserr.Define("USER_SUSPENDED", cuserr.ErrorCategoryForbidden, "user {user_id} is suspended")
+//
+//	return ErrUserSuspended.New(ctx, cuserr.Arg("user_id", id))
+type Definition struct {
+	code        string
+	category    ErrorCategory
+	template    string
+	description string
+}
+
+// Param is a named template parameter created with Arg
+type Param struct {
+	// Key is the placeholder name and the metadata key
+	Key string
+	// Value is rendered into the message and stored as metadata
+	Value interface{}
+}
+
+// Arg creates a template parameter for Definition.New and Definition.Wrap
+func Arg(key string, value interface{}) Param {
+	return Param{Key: key, Value: value}
+}
+
+// Define declares an error code with its category and message template
+// Placeholders in the template use the {name} syntax and are filled from the
+// parameters passed to New. The definition is registered in the sentinel registry,
+// so Define panics when the code is already owned by another sentinel; it is
+// intended for package-level variable initialization.
+func Define(code string, category ErrorCategory, template string, opts ...SentinelOption) *Definition {
+	options := &sentinelOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	def := &Definition{
+		code:        code,
+		category:    category,
+		template:    template,
+		description: options.description,
+	}
+	MustRegisterSentinel(def, category, code, opts...)
+	return def
+}
+
+// Definitions returns all registered definitions in registration order
+func Definitions() []*Definition {
+	var defs []*Definition
+	for _, spec := range RegisteredSentinels() {
+		if def, ok := spec.Sentinel.(*Definition); ok {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// Error implements the error interface, returning the unrendered template
+func (d *Definition) Error() string {
+	if d.template == "" {
+		return d.code
+	}
+	return d.template
+}
+
+// Code returns the error code of the definition
+func (d *Definition) Code() string {
+	return d.code
+}
+
+// Category returns the category of the definition
+func (d *Definition) Category() ErrorCategory {
+	return d.category
+}
+
+// Template returns the message template of the definition
+func (d *Definition) Template() string {
+	return d.template
+}
+
+// Description returns the documentation attached with WithSentinelDescription
+func (d *Definition) Description() string {
+	return d.description
+}
+
+// New creates an error from the definition
+// The message is rendered from the template and every parameter is kept as metadata.
+// Request, user and trace IDs are extracted from the context when present.
+func (d *Definition) New(ctx context.Context, args ...Param) *CustomError {
+	err := d.instantiate(nil, args)
+	err.stackTrace = captureStackTrace(STACK_SKIP_FRAMES)
+	return enrichFromContext(ctx, err)
+}
+
+// Wrap creates an error from the definition that wraps an underlying cause
+// Returns nil if cause is nil, mirroring WrapWithCustomError
+func (d *Definition) Wrap(ctx context.Context, cause error, args ...Param) *CustomError {
+	if cause == nil {
+		return nil
+	}
+
+	err := d.instantiate(cause, args)
+	err.stackTrace = captureStackTrace(STACK_SKIP_FRAMES)
+	return enrichFromContext(ctx, err)
+}
+
+// instantiate builds the error without stack trace or context enrichment
+// Category and code are resolved through the registry so re-registrations apply
+func (d *Definition) instantiate(wrapped error, args []Param) *CustomError {
+	category, code := d.category, d.code
+	if spec := sentinelRegistry.lookup(d); spec != nil {
+		category, code = spec.Category, spec.Code
+	}
+
+	err := &CustomError{
+		Category:  category,
+		Code:      code,
+		Message:   renderTemplate(d.template, args),
+		Timestamp: time.Now().UTC(),
+		Wrapped:   wrapped,
+		Sentinel:  d,
+	}
+	for _, arg := range args {
+		err = err.WithMetadataValue(arg.Key, arg.Value)
+	}
+	return err
+}
+
+// renderTemplate replaces {key} placeholders with parameter values
+// Placeholders without a matching parameter are left untouched
+func renderTemplate(template string, args []Param) string {
+	if len(args) == 0 || !strings.Contains(template, TEMPLATE_PARAM_OPEN) {
+		return template
+	}
+
+	pairs := make([]string, 0, len(args)*2)
+	for _, arg := range args {
+		pairs = append(pairs, TEMPLATE_PARAM_OPEN+arg.Key+TEMPLATE_PARAM_CLOSE, FormatMetadataValue(arg.Value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
@@ -23,52 +23,33 @@ var (
 	ErrRateLimit = errors.New(SENTINEL_MSG_RATE_LIMIT)
 	// ErrExternal indicates external service failure
 	ErrExternal = errors.New(SENTINEL_MSG_EXTERNAL)
+	// ErrUnavailable indicates a service is temporarily unavailable
+	ErrUnavailable = errors.New(SENTINEL_MSG_UNAVAILABLE)
+	// ErrNotImplemented indicates the requested functionality is not implemented
+	ErrNotImplemented = errors.New(SENTINEL_MSG_NOT_IMPLEMENTED)
+	// ErrGone indicates a resource has been permanently removed
+	ErrGone = errors.New(SENTINEL_MSG_GONE)
+	// ErrPreconditionFailed indicates a request precondition was not met
+	ErrPreconditionFailed = errors.New(SENTINEL_MSG_PRECONDITION_FAILED)
+	// ErrPayloadTooLarge indicates the request payload exceeds the allowed size
+	ErrPayloadTooLarge = errors.New(SENTINEL_MSG_PAYLOAD_TOO_LARGE)
+	// ErrUnsupportedMediaType indicates the request content type is not supported
+	ErrUnsupportedMediaType = errors.New(SENTINEL_MSG_UNSUPPORTED_MEDIA_TYPE)
+	// ErrUnprocessable indicates a well-formed request with semantic errors
+	ErrUnprocessable = errors.New(SENTINEL_MSG_UNPROCESSABLE)
+	// ErrMethodNotAllowed indicates the HTTP method is not supported by the resource
+	ErrMethodNotAllowed = errors.New(SENTINEL_MSG_METHOD_NOT_ALLOWED)
+	// ErrClientClosed indicates the client closed the request before completion
+	ErrClientClosed = errors.New(SENTINEL_MSG_CLIENT_CLOSED)
+	// ErrCircuitOpen indicates a call was rejected because a dependency's circuit breaker is open
+	ErrCircuitOpen = errors.New(SENTINEL_MSG_CIRCUIT_OPEN)
 )
 
-// mapSentinelToCategory maps sentinel errors to their appropriate categories
-func mapSentinelToCategory(sentinel error) ErrorCategory {
-	switch {
-	case errors.Is(sentinel, ErrNotFound):
-		return ErrorCategoryNotFound
-	case errors.Is(sentinel, ErrAlreadyExists):
-		return ErrorCategoryConflict
-	case errors.Is(sentinel, ErrInvalidInput):
-		return ErrorCategoryValidation
-	case errors.Is(sentinel, ErrUnauthorized):
-		return ErrorCategoryUnauthorized
-	case errors.Is(sentinel, ErrForbidden):
-		return ErrorCategoryForbidden
-	case errors.Is(sentinel, ErrTimeout):
-		return ErrorCategoryTimeout
-	case errors.Is(sentinel, ErrRateLimit):
-		return ErrorCategoryRateLimit
-	case errors.Is(sentinel, ErrExternal):
-		return ErrorCategoryExternal
-	default:
-		return ErrorCategoryInternal
-	}
-}
-
-// generateErrorCode creates consistent error codes from sentinel errors
-func generateErrorCode(sentinel error) string {
-	switch {
-	case errors.Is(sentinel, ErrNotFound):
-		return ERROR_CODE_NOT_FOUND
-	case errors.Is(sentinel, ErrAlreadyExists):
-		return ERROR_CODE_ALREADY_EXISTS
-	case errors.Is(sentinel, ErrInvalidInput):
-		return ERROR_CODE_INVALID_INPUT
-	case errors.Is(sentinel, ErrUnauthorized):
-		return ERROR_CODE_UNAUTHORIZED
-	case errors.Is(sentinel, ErrForbidden):
-		return ERROR_CODE_FORBIDDEN
-	case errors.Is(sentinel, ErrTimeout):
-		return ERROR_CODE_TIMEOUT
-	case errors.Is(sentinel, ErrRateLimit):
-		return ERROR_CODE_RATE_LIMIT
-	case errors.Is(sentinel, ErrExternal):
-		return ERROR_CODE_EXTERNAL_ERROR
-	default:
-		return ERROR_CODE_INTERNAL_ERROR
+// resolveSentinel maps a sentinel error to its registered category and code
+// Unregistered sentinels fall back to the internal category and INTERNAL_ERROR
+func resolveSentinel(sentinel error) (ErrorCategory, string) {
+	if spec := sentinelRegistry.lookup(sentinel); spec != nil {
+		return spec.Category, spec.Code
 	}
+	return ErrorCategoryInternal, ERROR_CODE_INTERNAL_ERROR
 }
@@ -0,0 +1,151 @@
+// Package cuserr provides fmt.Formatter support for errors.
+// This file contains the %v, %+v and %#v renderings of CustomError and ErrorCollection.
+package cuserr
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Format implements fmt.Formatter
+//
+//	%s, %v  the message chain, as returned by Error
+//	%q      the quoted message chain
+//	%+v     category, code, request ID, metadata, stack trace and every cause,
+//	        in a layout compatible with github.com/pkg/errors
+//	%#v     a Go-syntax representation
+func (e *CustomError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			e.writeDetailed(s, 0)
+		case s.Flag('#'):
+			e.writeGoSyntax(s)
+		default:
+			_, _ = io.WriteString(s, e.Error())
+		}
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = fmt.Fprintf(s, FORMAT_TEMPLATE_BAD_VERB, verb, "*cuserr.CustomError", e.Error())
+	}
+}
+
+// writeDetailed writes the %+v rendering; depth bounds recursion into nested causes
+func (e *CustomError) writeDetailed(w io.Writer, depth int) {
+	_, _ = io.WriteString(w, e.Message)
+	_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_FIELD, JSON_FIELD_CATEGORY, e.Category)
+	_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_FIELD, JSON_FIELD_CODE, e.Code)
+	if e.RequestID != "" {
+		_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_FIELD, JSON_FIELD_REQUEST_ID, e.RequestID)
+	}
+
+	metadata := e.GetAllMetadata()
+	if len(metadata) > 0 {
+		_, _ = fmt.Fprintf(w, "\n%s:", JSON_FIELD_METADATA)
+		for _, key := range sortedMetadataKeys(metadata) {
+			_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_METADATA, key, FormatMetadataValue(metadata[key]))
+		}
+	}
+
+	for _, frame := range e.GetStackTrace() {
+		_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_STACK_FRAME, frame.Function, frame.File, frame.Line)
+	}
+
+	for _, cause := range e.Causes() {
+		_, _ = io.WriteString(w, FORMAT_CAUSED_BY)
+		if customErr, ok := cause.(*CustomError); ok && depth < MAX_ERROR_CHAIN_DEPTH {
+			customErr.writeDetailed(w, depth+1)
+		} else {
+			_, _ = fmt.Fprintf(w, "%+v", cause)
+		}
+	}
+}
+
+// writeGoSyntax writes the %#v rendering without copying the error's mutex
+func (e *CustomError) writeGoSyntax(w io.Writer) {
+	_, _ = fmt.Fprintf(w,
+		"&cuserr.CustomError{Category:%#v, Code:%#v, Message:%#v, RequestID:%#v, Timestamp:%#v, metadata:%#v, Wrapped:%#v, Sentinel:%#v}",
+		e.Category, e.Code, e.Message, e.RequestID, e.Timestamp, e.GetAllMetadata(), e.Wrapped, e.Sentinel)
+}
+
+// Format implements fmt.Formatter
+//
+//	%s, %v  the summary, as returned by Error
+//	%q      the quoted summary
+//	%+v     the summary followed by every validation error and the %+v rendering of every error
+//	%#v     a Go-syntax representation
+func (ec *ErrorCollection) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			ec.writeDetailed(s)
+		case s.Flag('#'):
+			ec.writeGoSyntax(s)
+		default:
+			_, _ = io.WriteString(s, ec.Error())
+		}
+	case 's':
+		_, _ = io.WriteString(s, ec.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", ec.Error())
+	default:
+		_, _ = fmt.Fprintf(s, FORMAT_TEMPLATE_BAD_VERB, verb, "*cuserr.ErrorCollection", ec.Error())
+	}
+}
+
+// writeDetailed writes the %+v rendering of the collection
+func (ec *ErrorCollection) writeDetailed(w io.Writer) {
+	_, _ = io.WriteString(w, ec.Error())
+
+	ec.mu.RLock()
+	requestID := ec.RequestID
+	validationErrors := append([]ValidationError(nil), ec.ValidationErrors...)
+	errs := append([]*CustomError(nil), ec.Errors...)
+	ec.mu.RUnlock()
+
+	if requestID != "" {
+		_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_FIELD, JSON_FIELD_REQUEST_ID, requestID)
+	}
+	for _, validationErr := range validationErrors {
+		_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_VALIDATION, validationErr.Field, validationErr.Message)
+		if validationErr.Code != "" {
+			_, _ = fmt.Fprintf(w, " (%s)", validationErr.Code)
+		}
+	}
+	for i, err := range errs {
+		_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_MEMBER, i+1)
+		err.writeDetailed(w, 1)
+	}
+}
+
+// writeGoSyntax writes the %#v rendering without copying the collection's mutex
+func (ec *ErrorCollection) writeGoSyntax(w io.Writer) {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	_, _ = io.WriteString(w, "&cuserr.ErrorCollection{Errors:[]*cuserr.CustomError{")
+	for i, err := range ec.Errors {
+		if i > 0 {
+			_, _ = io.WriteString(w, ", ")
+		}
+		err.writeGoSyntax(w)
+	}
+	_, _ = fmt.Fprintf(w, "}, ValidationErrors:%#v, Summary:%#v, RequestID:%#v, Context:%#v}",
+		ec.ValidationErrors, ec.Summary, ec.RequestID, ec.Context)
+}
+
+// sortedMetadataKeys returns metadata keys in a deterministic order
+func sortedMetadataKeys(metadata map[string]interface{}) []string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,235 @@
+// Package cuserr provides GraphQL error formatting.
+// This file contains the GraphQL renderer, extension code mapping and partial-data responses.
+package cuserr
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GraphQLError is an entry of the errors list of a GraphQL response
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation is a position in the GraphQL document
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLResponse is a GraphQL response carrying data and errors
+// Data is omitted when nil; use json.RawMessage("null") for an explicit null
+type GraphQLResponse struct {
+	Data       interface{}            `json:"data,omitempty"`
+	Errors     []GraphQLError         `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// graphQLCodeStore stores GraphQL extension codes per category with thread-safe access
+type graphQLCodeStore struct {
+	mu         sync.RWMutex
+	byCategory map[ErrorCategory]string
+}
+
+// newGraphQLCodeStore creates a store with the conventional extension codes
+func newGraphQLCodeStore() *graphQLCodeStore {
+	return &graphQLCodeStore{
+		byCategory: map[ErrorCategory]string{
+			ErrorCategoryValidation:    GRAPHQL_CODE_BAD_USER_INPUT,
+			ErrorCategoryUnprocessable: GRAPHQL_CODE_BAD_USER_INPUT,
+			ErrorCategoryUnauthorized:  GRAPHQL_CODE_UNAUTHENTICATED,
+			ErrorCategoryForbidden:     GRAPHQL_CODE_FORBIDDEN,
+			ErrorCategoryNotFound:      GRAPHQL_CODE_NOT_FOUND,
+			ErrorCategoryGone:          GRAPHQL_CODE_NOT_FOUND,
+		},
+	}
+}
+
+// Package-level GraphQL code registry instance
+var graphQLCodeRegistry = newGraphQLCodeStore()
+
+// SetGraphQLCode sets the GraphQL extension code for a category
+// An empty code removes the mapping so the category falls back to INTERNAL_SERVER_ERROR
+func SetGraphQLCode(category ErrorCategory, code string) {
+	graphQLCodeRegistry.mu.Lock()
+	defer graphQLCodeRegistry.mu.Unlock()
+
+	if code == "" {
+		delete(graphQLCodeRegistry.byCategory, category)
+		return
+	}
+	graphQLCodeRegistry.byCategory[category] = code
+}
+
+// GraphQLCode returns the GraphQL extension code for a category
+func GraphQLCode(category ErrorCategory) string {
+	graphQLCodeRegistry.mu.RLock()
+	defer graphQLCodeRegistry.mu.RUnlock()
+
+	if code, exists := graphQLCodeRegistry.byCategory[category]; exists {
+		return code
+	}
+	return GRAPHQL_CODE_INTERNAL_SERVER_ERROR
+}
+
+// WithGraphQLPath records the resolver path of the field that failed
+// Path segments are field names (string) and list indexes (int)
+func (e *CustomError) WithGraphQLPath(path ...interface{}) *CustomError {
+	return e.WithMetadataValue(MetaGraphQLPath, append([]interface{}(nil), path...))
+}
+
+// WithGraphQLLocation adds a location in the GraphQL document
+func (e *CustomError) WithGraphQLLocation(line, column int) *CustomError {
+	locations := graphQLLocations(e)
+	locations = append(append([]GraphQLLocation(nil), locations...), GraphQLLocation{Line: line, Column: column})
+	return e.WithMetadataValue(MetaGraphQLLocations, locations)
+}
+
+// graphQLPath reads the resolver path from metadata
+// Besides a segment list, a dotted string such as "user.friends.0.name" is accepted
+func graphQLPath(e *CustomError) []interface{} {
+	value, exists := e.GetMetadataValue(MetaGraphQLPath)
+	if !exists {
+		return nil
+	}
+
+	switch path := value.(type) {
+	case []interface{}:
+		return append([]interface{}(nil), path...)
+	case []string:
+		segments := make([]interface{}, len(path))
+		for i, segment := range path {
+			segments[i] = segment
+		}
+		return segments
+	case string:
+		if path == "" {
+			return nil
+		}
+		parts := strings.Split(path, ".")
+		segments := make([]interface{}, len(parts))
+		for i, part := range parts {
+			if index, err := strconv.Atoi(part); err == nil {
+				segments[i] = index
+			} else {
+				segments[i] = part
+			}
+		}
+		return segments
+	default:
+		return nil
+	}
+}
+
+// graphQLLocations reads the document locations from metadata
+func graphQLLocations(e *CustomError) []GraphQLLocation {
+	value, _ := e.GetMetadataValue(MetaGraphQLLocations)
+	locations, _ := value.([]GraphQLLocation)
+	return locations
+}
+
+// ToGraphQLError converts the error to a GraphQL error
+// extensions.code is the GraphQL code of the category (see SetGraphQLCode)
+// and extensions.error_code the error's own code. The message is the
+// client-safe message and metadata is filtered as in ToClientJSON, so
+// ProductionMode masks internal details. Path and locations come from
+// WithGraphQLPath and WithGraphQLLocation.
+func (e *CustomError) ToGraphQLError() GraphQLError {
+	extensions := map[string]interface{}{
+		JSON_FIELD_CODE:       GraphQLCode(e.Category),
+		JSON_FIELD_CATEGORY:   string(e.Category),
+		JSON_FIELD_ERROR_CODE: e.Code,
+	}
+	if e.RequestID != "" {
+		extensions[JSON_FIELD_REQUEST_ID] = e.RequestID
+	}
+	e.addRetryFields(extensions)
+
+	metadata := e.clientMetadata()
+	delete(metadata, MetaGraphQLPath)
+	delete(metadata, MetaGraphQLLocations)
+	if len(metadata) > 0 {
+		extensions[JSON_FIELD_METADATA] = metadata
+	}
+
+	return GraphQLError{
+		Message:    e.ClientSafeMessage(),
+		Locations:  graphQLLocations(e),
+		Path:       graphQLPath(e),
+		Extensions: extensions,
+	}
+}
+
+// ToGraphQLErrors converts the collection to GraphQL errors
+// Validation errors become BAD_USER_INPUT errors with the field in
+// extensions.field; the collection's request ID is added to every error
+// that has none.
+func (ec *ErrorCollection) ToGraphQLErrors() []GraphQLError {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	graphQLErrors := make([]GraphQLError, 0, len(ec.ValidationErrors)+len(ec.Errors))
+	for _, validationErr := range ec.ValidationErrors {
+		code := validationErr.Code
+		if code == "" {
+			code = ERROR_CODE_INVALID_INPUT
+		}
+		graphQLErrors = append(graphQLErrors, GraphQLError{
+			Message: validationErr.Message,
+			Extensions: map[string]interface{}{
+				JSON_FIELD_CODE:       GraphQLCode(ErrorCategoryValidation),
+				JSON_FIELD_CATEGORY:   string(ErrorCategoryValidation),
+				JSON_FIELD_ERROR_CODE: code,
+				JSON_FIELD_FIELD:      validationErr.Field,
+			},
+		})
+	}
+	for _, err := range ec.Errors {
+		graphQLErrors = append(graphQLErrors, err.ToGraphQLError())
+	}
+
+	if ec.RequestID != "" {
+		for _, graphQLErr := range graphQLErrors {
+			if _, exists := graphQLErr.Extensions[JSON_FIELD_REQUEST_ID]; !exists {
+				graphQLErr.Extensions[JSON_FIELD_REQUEST_ID] = ec.RequestID
+			}
+		}
+	}
+	return graphQLErrors
+}
+
+// GraphQLErrors converts any error to GraphQL errors
+// CustomErrors and ErrorCollections are found through wrapping. Other errors
+// are internal errors unless they wrap a registered sentinel; either way the
+// message is the sentinel's text, so their own text is never exposed.
+func GraphQLErrors(err error) []GraphQLError {
+	if err == nil {
+		return nil
+	}
+
+	customErr, collection := inspectError(err)
+	switch {
+	case collection != nil:
+		return collection.ToGraphQLErrors()
+	case customErr != nil:
+		return []GraphQLError{customErr.ToGraphQLError()}
+	default:
+		return []GraphQLError{FromError(err).ToGraphQLError()}
+	}
+}
+
+// NewGraphQLResponse builds a response from data and the errors raised while resolving it
+// Partial data is returned alongside the errors of the fields that failed;
+// nil errors are skipped
+func NewGraphQLResponse(data interface{}, errs ...error) *GraphQLResponse {
+	response := &GraphQLResponse{Data: data}
+	for _, err := range errs {
+		response.Errors = append(response.Errors, GraphQLErrors(err)...)
+	}
+	return response
+}
@@ -0,0 +1,257 @@
+// Package cuserr provides decoding of HTTP error responses from other services.
+// This file contains FromHTTPResponse and the remote error markers.
+package cuserr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPResponseOption configures FromHTTPResponse
+type HTTPResponseOption func(*httpResponseOptions)
+
+// httpResponseOptions holds the FromHTTPResponse settings
+type httpResponseOptions struct {
+	originService string
+	maxBodyBytes  int64
+}
+
+// WithOriginService names the service that sent the response
+// The default is the host of the request that produced the response
+func WithOriginService(service string) HTTPResponseOption {
+	return func(options *httpResponseOptions) {
+		options.originService = service
+	}
+}
+
+// WithMaxBodyBytes limits how much of the response body is read
+// Non-positive limits are ignored; the default is 1 MiB
+func WithMaxBodyBytes(limit int64) HTTPResponseOption {
+	return func(options *httpResponseOptions) {
+		if limit > 0 {
+			options.maxBodyBytes = limit
+		}
+	}
+}
+
+// FromHTTPResponse decodes the error response of another service
+// Native cuserr bodies restore the code, category, message, metadata, request
+// ID and retry hint, collection bodies become an ErrorCollection, problem+json
+// bodies go through ParseProblemDetails and JSON:API documents through
+// ParseJSONAPIErrors. Bodies that are missing, too large or not JSON fall back
+// to the status as in FromHTTPStatus, using the first line of a text/plain
+// body as the message. Registered codes restore their sentinel, so errors.Is
+// keeps working. The X-Request-ID and Retry-After headers fill in a missing
+// request ID and retry hint, and the result is marked remote with the origin
+// service (see IsRemoteError). Responses below 400 yield nil. At most
+// WithMaxBodyBytes of the body are read and the body is not closed.
+func FromHTTPResponse(resp *http.Response, opts ...HTTPResponseOption) error {
+	if resp == nil || resp.StatusCode < HTTP_STATUS_BAD_REQUEST {
+		return nil
+	}
+
+	options := httpResponseOptions{maxBodyBytes: HTTP_RESPONSE_MAX_BODY_BYTES}
+	if resp.Request != nil && resp.Request.URL != nil {
+		options.originService = resp.Request.URL.Host
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var data []byte
+	if resp.Body != nil {
+		// Read errors are tolerated: a partial body simply fails to decode
+		data, _ = io.ReadAll(io.LimitReader(resp.Body, options.maxBodyBytes+1))
+		if int64(len(data)) > options.maxBodyBytes {
+			data = nil
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get(HTTP_HEADER_CONTENT_TYPE))
+	requestID := resp.Header.Get(HTTP_HEADER_REQUEST_ID)
+	retryAfter, hasRetryAfter := ParseRetryAfter(resp.Header.Get(HTTP_HEADER_RETRY_AFTER))
+
+	remote := func(err *CustomError) *CustomError {
+		if err.RequestID == "" && requestID != "" {
+			err = err.WithRequestID(requestID)
+		}
+		if _, exists := err.RetryAfter(); !exists && hasRetryAfter {
+			err = err.WithRetryAfter(retryAfter)
+		}
+		return err.WithRemoteOrigin(options.originService)
+	}
+
+	switch decoded := decodeErrorBody(mediaType, data, resp.StatusCode).(type) {
+	case *ErrorCollection:
+		decoded.mu.Lock()
+		for i, member := range decoded.Errors {
+			decoded.Errors[i] = remote(member)
+		}
+		if decoded.RequestID == "" {
+			decoded.RequestID = requestID
+		}
+		decoded.mu.Unlock()
+
+		decoded.WithContext(MetaRemote, "true")
+		if options.originService != "" {
+			decoded.WithContext(MetaOriginService, options.originService)
+		}
+		return decoded
+	case *CustomError:
+		return remote(decoded)
+	default:
+		message := ""
+		if strings.HasPrefix(mediaType, "text/plain") {
+			message, _, _ = strings.Cut(string(data), "\n")
+			message = strings.TrimSpace(message)
+		}
+		return remote(codeStatusError("", resp.StatusCode, message))
+	}
+}
+
+// decodeErrorBody decodes a JSON error body into a *CustomError or *ErrorCollection
+// Returns nil when the body is not a recognized error document
+func decodeErrorBody(mediaType string, data []byte, statusCode int) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	switch {
+	case mediaType == CONTENT_TYPE_JSONAPI:
+		if collection, err := ParseJSONAPIErrors(data); err == nil && !collection.IsEmpty() {
+			return collection
+		}
+	case isJSONObject(fields[JSON_FIELD_ERROR]):
+		if decoded := decodeNativeErrorBody(fields[JSON_FIELD_ERROR], statusCode); decoded != nil {
+			return decoded
+		}
+	case mediaType == CONTENT_TYPE_PROBLEM_JSON || fields[JSON_FIELD_TITLE] != nil || fields[JSON_FIELD_DETAIL] != nil:
+		if customErr, err := ParseProblemDetails(data); err == nil {
+			return customErr
+		}
+	}
+	return nil
+}
+
+// nativeErrorBody holds the fields of the native error object beyond the UnmarshalJSON wire format
+type nativeErrorBody struct {
+	Code              string            `json:"code"`
+	Message           string            `json:"message"`
+	RetryAfterSeconds int64             `json:"retry_after_seconds"`
+	Errors            []json.RawMessage `json:"errors"`
+	ValidationErrors  []ValidationError `json:"validation_errors"`
+	Summary           string            `json:"summary"`
+	RequestID         string            `json:"request_id"`
+	Context           map[string]string `json:"context"`
+}
+
+// decodeNativeErrorBody decodes the object under "error" in the ToJSON and ToClientJSON formats
+func decodeNativeErrorBody(data json.RawMessage, statusCode int) error {
+	var body nativeErrorBody
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil
+	}
+
+	if body.Code != ERROR_CODE_MULTIPLE_ERRORS {
+		customErr, err := decodeNativeError(data)
+		if err != nil {
+			return nil
+		}
+		return customErr
+	}
+
+	collection := NewErrorCollection(body.Summary)
+	for _, member := range body.Errors {
+		customErr, err := decodeNativeError(member)
+		if err != nil {
+			return nil
+		}
+		collection.Add(customErr)
+	}
+	collection.ValidationErrors = append(collection.ValidationErrors, body.ValidationErrors...)
+	collection.RequestID = body.RequestID
+	for key, value := range body.Context {
+		collection.WithContext(key, value)
+	}
+	// Production mode omits the members of collections
+	if collection.IsEmpty() {
+		collection.Add(codeStatusError("", statusCode, body.Message))
+	}
+	return collection
+}
+
+// decodeNativeError decodes a single native error object
+// Unregistered codes keep their category and take the category's built-in
+// sentinel, so errors.Is(err, ErrNotFound) still matches. Unregistered codes
+// of user-defined categories get no sentinel, since matching a local
+// Definition of the same category would be wrong
+func decodeNativeError(data json.RawMessage) (*CustomError, error) {
+	customErr := &CustomError{}
+	if err := customErr.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+
+	if customErr.Sentinel == nil {
+		customErr.Sentinel = builtinCategorySentinel(customErr.Category)
+	}
+
+	var body nativeErrorBody
+	if err := json.Unmarshal(data, &body); err == nil && body.RetryAfterSeconds > 0 {
+		customErr = customErr.WithRetryAfter(time.Duration(body.RetryAfterSeconds) * time.Second)
+	}
+	return customErr, nil
+}
+
+// WithRemoteOrigin marks the error as received from another service
+// An empty service only sets the remote marker
+func (e *CustomError) WithRemoteOrigin(service string) *CustomError {
+	err := KeyRemote.Set(e, true)
+	if service != "" {
+		err = KeyOriginService.Set(err, service)
+	}
+	return err
+}
+
+// IsRemoteError reports whether err was decoded from another service's response
+// For an ErrorCollection the collection context or any member decides
+func IsRemoteError(err error) bool {
+	if _, collection := inspectError(err); collection != nil && collection.contextValue(MetaRemote) == "true" {
+		return true
+	}
+	return anyInspectedError(err, func(customErr *CustomError) bool {
+		remote, _ := KeyRemote.Get(customErr)
+		return remote
+	})
+}
+
+// GetOriginService returns the service a remote error was received from
+// Returns an empty string for local errors and unknown origins
+func GetOriginService(err error) string {
+	if _, collection := inspectError(err); collection != nil {
+		if service := collection.contextValue(MetaOriginService); service != "" {
+			return service
+		}
+	}
+	service, _ := GetErrorMetadata(err, MetaOriginService)
+	return service
+}
+
+// contextValue returns a value of the collection context
+func (ec *ErrorCollection) contextValue(key string) string {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	return ec.Context[key]
+}
+
+// isJSONObject reports whether data holds a JSON object
+func isJSONObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
@@ -0,0 +1,204 @@
+// Package cuserr provides HTTP response headers derived from errors.
+// This file contains Headers and the Retry-After, RateLimit, WWW-Authenticate, Allow and X-Request-ID rules.
+package cuserr
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// authChallenge holds the default WWW-Authenticate challenge
+var authChallenge = struct {
+	mu        sync.RWMutex
+	challenge string
+}{challenge: AUTH_CHALLENGE_DEFAULT}
+
+// SetDefaultAuthChallenge sets the WWW-Authenticate challenge of unauthorized errors without their own
+// The default is "Bearer"; an empty challenge omits the header
+func SetDefaultAuthChallenge(challenge string) {
+	authChallenge.mu.Lock()
+	defer authChallenge.mu.Unlock()
+
+	authChallenge.challenge = challenge
+}
+
+// defaultAuthChallenge returns the default WWW-Authenticate challenge
+func defaultAuthChallenge() string {
+	authChallenge.mu.RLock()
+	defer authChallenge.mu.RUnlock()
+
+	return authChallenge.challenge
+}
+
+// WithAuthChallenge sets the WWW-Authenticate challenge sent with an unauthorized error
+// For example `Bearer realm="api", error="invalid_token"`
+func (e *CustomError) WithAuthChallenge(challenge string) *CustomError {
+	return e.WithMetadata(MetaAuthChallenge, challenge)
+}
+
+// Headers returns the HTTP response headers implied by err
+// CustomErrors and ErrorCollections are found through wrapping; other errors
+// yield an empty header. The result is never nil.
+func Headers(err error) http.Header {
+	customErr, collection := inspectError(err)
+	switch {
+	case collection != nil:
+		return collection.Headers()
+	case customErr != nil:
+		return customErr.Headers()
+	default:
+		return http.Header{}
+	}
+}
+
+// Headers returns the HTTP response headers implied by the error
+//
+//	Retry-After                 the retry hint, in seconds
+//	RateLimit-Limit/-Remaining  rate limit errors: the limit metadata and
+//	RateLimit-Reset             rate_limit_remaining (default 0); reset is the
+//	RateLimit-Policy            retry hint or the window
+//	WWW-Authenticate            unauthorized errors: WithAuthChallenge or the default challenge
+//	Allow                       the allowed_methods metadata of method-not-allowed errors
+//	X-Request-ID                the request ID
+func (e *CustomError) Headers() http.Header {
+	header := http.Header{}
+
+	delay, hasDelay := e.RetryAfter()
+	if hasDelay {
+		header.Set(HTTP_HEADER_RETRY_AFTER, strconv.FormatInt(retryAfterSeconds(delay), 10))
+	}
+
+	switch e.Category {
+	case ErrorCategoryRateLimit:
+		e.addRateLimitHeaders(header, delay, hasDelay)
+	case ErrorCategoryUnauthorized:
+		challenge, exists := e.GetMetadata(MetaAuthChallenge)
+		if !exists {
+			challenge = defaultAuthChallenge()
+		}
+		if challenge != "" {
+			header.Set(HTTP_HEADER_WWW_AUTHENTICATE, challenge)
+		}
+	case ErrorCategoryMethodNotAllowed:
+		if allowed, exists := e.GetMetadata(MetaAllowedMethods); exists && allowed != "" {
+			header.Set(HTTP_HEADER_ALLOW, allowed)
+		}
+	}
+
+	if e.RequestID != "" {
+		header.Set(HTTP_HEADER_REQUEST_ID, e.RequestID)
+	}
+	return header
+}
+
+// addRateLimitHeaders adds the RateLimit fields of a rate limit error
+func (e *CustomError) addRateLimitHeaders(header http.Header, delay time.Duration, hasDelay bool) {
+	limitValue, _ := e.GetMetadata(MetaRateLimit)
+	limit, limitErr := strconv.ParseInt(limitValue, 10, 64)
+	if limitErr != nil {
+		return
+	}
+
+	remaining := int64(0)
+	if value, exists := e.GetMetadata(MetaRateLimitRemaining); exists {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil && parsed >= 0 {
+			remaining = parsed
+		}
+	}
+
+	header.Set(HTTP_HEADER_RATELIMIT_LIMIT, strconv.FormatInt(limit, 10))
+	header.Set(HTTP_HEADER_RATELIMIT_REMAINING, strconv.FormatInt(remaining, 10))
+
+	windowValue, _ := e.GetMetadata(MetaRateLimitWindow)
+	window, hasWindow := parseRateLimitWindow(windowValue)
+	if hasWindow {
+		header.Set(HTTP_HEADER_RATELIMIT_POLICY, strconv.FormatInt(limit, 10)+";w="+strconv.FormatInt(retryAfterSeconds(window), 10))
+	}
+
+	switch {
+	case hasDelay:
+		header.Set(HTTP_HEADER_RATELIMIT_RESET, strconv.FormatInt(retryAfterSeconds(delay), 10))
+	case hasWindow:
+		header.Set(HTTP_HEADER_RATELIMIT_RESET, strconv.FormatInt(retryAfterSeconds(window), 10))
+	}
+}
+
+// rateLimitWindowUnits maps the unit words accepted in rate limit windows to durations
+var rateLimitWindowUnits = map[string]time.Duration{
+	"second": time.Second,
+	"sec":    time.Second,
+	"minute": time.Minute,
+	"min":    time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// parseRateLimitWindow parses the window of a rate limit error
+// Go durations such as "1m" or "90s" are accepted, as are unit words with an
+// optional count such as "hour", "15 minutes" or "1 day". Returns false for
+// anything else, including non-positive windows.
+func parseRateLimitWindow(window string) (time.Duration, bool) {
+	window = strings.ToLower(strings.TrimSpace(window))
+	if duration, err := time.ParseDuration(window); err == nil {
+		return duration, duration > 0
+	}
+
+	count := int64(1)
+	unit := window
+	if number, rest, found := strings.Cut(window, " "); found {
+		parsed, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		count, unit = parsed, strings.TrimSpace(rest)
+	}
+
+	size, known := rateLimitWindowUnits[unit]
+	if !known {
+		size, known = rateLimitWindowUnits[strings.TrimSuffix(unit, "s")]
+	}
+	if !known || count <= 0 {
+		return 0, false
+	}
+	return time.Duration(count) * size, true
+}
+
+// Headers returns the HTTP response headers implied by the collection
+// Members with the collection's status contribute their headers, the first
+// member winning each header; Retry-After is the longest hint of any member
+// and X-Request-ID the collection's request ID when set.
+func (ec *ErrorCollection) Headers() http.Header {
+	status := ec.ToHTTPStatus()
+
+	ec.mu.RLock()
+	requestID := ec.RequestID
+	errs := append([]*CustomError(nil), ec.Errors...)
+	ec.mu.RUnlock()
+
+	header := http.Header{}
+	var longest time.Duration
+	for _, err := range errs {
+		if delay, ok := err.RetryAfter(); ok && delay > longest {
+			longest = delay
+		}
+		if err.ToHTTPStatus() != status {
+			continue
+		}
+		for key, values := range err.Headers() {
+			if _, exists := header[key]; !exists {
+				header[key] = values
+			}
+		}
+	}
+
+	if longest > 0 {
+		header.Set(HTTP_HEADER_RETRY_AFTER, strconv.FormatInt(retryAfterSeconds(longest), 10))
+	}
+	if requestID != "" {
+		header.Set(HTTP_HEADER_REQUEST_ID, requestID)
+	}
+	return header
+}
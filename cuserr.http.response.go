@@ -0,0 +1,431 @@
+// Package cuserr provides content-negotiated HTTP error responses.
+// This file contains WriteHTTPError, the ResponseFormatter registry and the built-in formatters.
+package cuserr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidResponseFormatter indicates a response formatter registration was rejected
+var ErrInvalidResponseFormatter = errors.New(REGISTRY_MSG_INVALID_FORMATTER)
+
+// ResponseFormatter renders errors in one media type
+// Format receives a *CustomError or an *ErrorCollection and must apply
+// production-mode filtering itself, as the built-in formatters do.
+type ResponseFormatter interface {
+	// ContentType returns the Content-Type header value, such as "application/xml; charset=utf-8"
+	ContentType() string
+	// Format writes the error body to w
+	Format(w io.Writer, err error) error
+}
+
+// responseFormatter is a ResponseFormatter built from a function
+type responseFormatter struct {
+	contentType string
+	format      func(w io.Writer, err error) error
+}
+
+// ContentType returns the Content-Type header value
+func (f *responseFormatter) ContentType() string {
+	return f.contentType
+}
+
+// Format writes the error body to w
+func (f *responseFormatter) Format(w io.Writer, err error) error {
+	return f.format(w, err)
+}
+
+// NewResponseFormatter creates a formatter from a content type and a format function
+func NewResponseFormatter(contentType string, format func(w io.Writer, err error) error) ResponseFormatter {
+	return &responseFormatter{contentType: contentType, format: format}
+}
+
+// responseFormatterStore stores formatters per media type with thread-safe access
+type responseFormatterStore struct {
+	mu               sync.RWMutex
+	byMediaType      map[string]ResponseFormatter
+	order            []string
+	defaultMediaType string
+}
+
+// newResponseFormatterStore creates a store with the built-in formatters
+// Registration order decides which formatter answers a type wildcard such as text/*
+func newResponseFormatterStore() *responseFormatterStore {
+	store := &responseFormatterStore{
+		byMediaType:      make(map[string]ResponseFormatter),
+		defaultMediaType: CONTENT_TYPE_JSON,
+	}
+	for _, formatter := range []ResponseFormatter{
+		nativeJSONFormatter,
+		NewResponseFormatter(CONTENT_TYPE_PROBLEM_JSON, formatProblemJSON),
+		NewResponseFormatter(CONTENT_TYPE_JSONAPI, formatJSONAPI),
+		NewResponseFormatter(CONTENT_TYPE_XML, formatXML),
+		NewResponseFormatter(CONTENT_TYPE_TEXT, formatText),
+		NewResponseFormatter(CONTENT_TYPE_HTML, formatHTML),
+		NewResponseFormatter(CONTENT_TYPE_TEXT_XML, formatXML),
+	} {
+		mediaType, _, _ := mime.ParseMediaType(formatter.ContentType())
+		store.byMediaType[mediaType] = formatter
+		store.order = append(store.order, mediaType)
+	}
+	return store
+}
+
+// nativeJSONFormatter renders the ToJSON format and is the fallback of negotiation
+var nativeJSONFormatter = NewResponseFormatter(CONTENT_TYPE_JSON, formatNativeJSON)
+
+// Package-level response formatter registry instance
+var responseFormatterRegistry = newResponseFormatterStore()
+
+// RegisterResponseFormatter registers a formatter for the media type of its content type
+// An existing formatter for the media type is replaced
+func RegisterResponseFormatter(formatter ResponseFormatter) error {
+	if formatter == nil {
+		return fmt.Errorf("%w: formatter is nil", ErrInvalidResponseFormatter)
+	}
+	mediaType, _, err := mime.ParseMediaType(formatter.ContentType())
+	if err != nil || strings.Contains(mediaType, "*") {
+		return fmt.Errorf("%w: content type %q", ErrInvalidResponseFormatter, formatter.ContentType())
+	}
+
+	responseFormatterRegistry.mu.Lock()
+	defer responseFormatterRegistry.mu.Unlock()
+
+	if _, exists := responseFormatterRegistry.byMediaType[mediaType]; !exists {
+		responseFormatterRegistry.order = append(responseFormatterRegistry.order, mediaType)
+	}
+	responseFormatterRegistry.byMediaType[mediaType] = formatter
+	return nil
+}
+
+// UnregisterResponseFormatter removes the formatter of a media type
+// Returns false when no formatter was registered
+func UnregisterResponseFormatter(mediaType string) bool {
+	mediaType = strings.ToLower(mediaType)
+
+	responseFormatterRegistry.mu.Lock()
+	defer responseFormatterRegistry.mu.Unlock()
+
+	if _, exists := responseFormatterRegistry.byMediaType[mediaType]; !exists {
+		return false
+	}
+	delete(responseFormatterRegistry.byMediaType, mediaType)
+	for i, registered := range responseFormatterRegistry.order {
+		if registered == mediaType {
+			responseFormatterRegistry.order = append(responseFormatterRegistry.order[:i:i], responseFormatterRegistry.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// SetDefaultResponseFormat sets the media type used when Accept is missing, */* or unmatched
+// The media type must have a registered formatter; the default is application/json
+func SetDefaultResponseFormat(mediaType string) error {
+	mediaType = strings.ToLower(mediaType)
+
+	responseFormatterRegistry.mu.Lock()
+	defer responseFormatterRegistry.mu.Unlock()
+
+	if _, exists := responseFormatterRegistry.byMediaType[mediaType]; !exists {
+		return fmt.Errorf("%w: no formatter for %q", ErrInvalidResponseFormatter, mediaType)
+	}
+	responseFormatterRegistry.defaultMediaType = mediaType
+	return nil
+}
+
+// acceptRange is a media range of an Accept header
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// specificity ranks exact types over type/* over */*
+func (a acceptRange) specificity() int {
+	switch {
+	case a.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(a.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// parseAccept parses an Accept header into ranges ordered by preference
+// Ranges with q=0 or invalid syntax are dropped
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+// NegotiateResponseFormatter picks the formatter for an Accept header
+// The most preferred range with a registered formatter wins; a type wildcard
+// prefers the default format and then registration order. A missing or
+// unmatched header yields the default formatter.
+func NegotiateResponseFormatter(accept string) ResponseFormatter {
+	responseFormatterRegistry.mu.RLock()
+	defer responseFormatterRegistry.mu.RUnlock()
+
+	defaultFormatter, exists := responseFormatterRegistry.byMediaType[responseFormatterRegistry.defaultMediaType]
+	if !exists {
+		defaultFormatter = nativeJSONFormatter
+	}
+
+	for _, candidate := range parseAccept(accept) {
+		switch candidate.specificity() {
+		case 0:
+			return defaultFormatter
+		case 1:
+			prefix := strings.TrimSuffix(candidate.mediaType, "*")
+			if exists && strings.HasPrefix(responseFormatterRegistry.defaultMediaType, prefix) {
+				return defaultFormatter
+			}
+			for _, mediaType := range responseFormatterRegistry.order {
+				if strings.HasPrefix(mediaType, prefix) {
+					return responseFormatterRegistry.byMediaType[mediaType]
+				}
+			}
+		default:
+			if formatter, registered := responseFormatterRegistry.byMediaType[candidate.mediaType]; registered {
+				return formatter
+			}
+		}
+	}
+	return defaultFormatter
+}
+
+// WriteHTTPError writes err as an HTTP error response negotiated from the request's Accept header
+// CustomErrors and ErrorCollections are found through wrapping; other errors
+// are internal errors unless they wrap a registered sentinel, and their text is
+// never exposed. A request ID found in the request context is added to a copy
+// of the error or collection when it has none. The status comes from the error's
+// category, Content-Type from the chosen formatter, the remaining headers
+// from Headers, and HEAD requests get headers only. The request may be nil, which selects the default format.
+func WriteHTTPError(w http.ResponseWriter, r *http.Request, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var accept, requestID string
+	if r != nil {
+		accept = r.Header.Get(HTTP_HEADER_ACCEPT)
+		requestID = GetRequestIDFromContext(r.Context())
+	}
+
+	var rendered error
+	var status int
+	switch customErr, collection := inspectError(err); {
+	case collection != nil:
+		if collection.RequestID == "" && requestID != "" {
+			collection = collection.Clone().WithRequestID(requestID)
+		}
+		rendered, status = collection, collection.ToHTTPStatus()
+	case customErr != nil:
+		if customErr.RequestID == "" && requestID != "" {
+			customErr = customErr.Clone().WithRequestID(requestID)
+		}
+		rendered, status = customErr, customErr.ToHTTPStatus()
+	default:
+		customErr := FromError(err).WithRequestID(requestID)
+		rendered, status = customErr, customErr.ToHTTPStatus()
+	}
+
+	formatter := NegotiateResponseFormatter(accept)
+	var body bytes.Buffer
+	if formatErr := formatter.Format(&body, rendered); formatErr != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return formatErr
+	}
+
+	header := w.Header()
+	for key, values := range Headers(rendered) {
+		header[key] = values
+	}
+	if requestID != "" && header.Get(HTTP_HEADER_REQUEST_ID) == "" {
+		header.Set(HTTP_HEADER_REQUEST_ID, requestID)
+	}
+	header.Set(HTTP_HEADER_CONTENT_TYPE, formatter.ContentType())
+	header.Set(HTTP_HEADER_CONTENT_LENGTH, strconv.Itoa(body.Len()))
+	header.Set(HTTP_HEADER_CONTENT_TYPE_OPTIONS, "nosniff")
+	header.Add(HTTP_HEADER_VARY, HTTP_HEADER_ACCEPT)
+	w.WriteHeader(status)
+
+	if r != nil && r.Method == http.MethodHead {
+		return nil
+	}
+	_, writeErr := w.Write(body.Bytes())
+	return writeErr
+}
+
+// formatNativeJSON writes ToClientJSON in production mode and ToJSON otherwise
+func formatNativeJSON(w io.Writer, err error) error {
+	productionMode := GetConfig().ProductionMode
+	switch typed := err.(type) {
+	case *ErrorCollection:
+		if productionMode {
+			return json.NewEncoder(w).Encode(typed.ToClientJSON())
+		}
+		return typed.WriteJSON(w)
+	case *CustomError:
+		if productionMode {
+			return json.NewEncoder(w).Encode(typed.ToClientJSON())
+		}
+		return typed.WriteJSON(w)
+	default:
+		return fmt.Errorf("%w: unsupported error type %T", ErrInvalidResponseFormatter, err)
+	}
+}
+
+// formatProblemJSON writes RFC 9457 problem details
+func formatProblemJSON(w io.Writer, err error) error {
+	switch typed := err.(type) {
+	case *ErrorCollection:
+		return json.NewEncoder(w).Encode(typed.ToProblemDetails())
+	case *CustomError:
+		return json.NewEncoder(w).Encode(typed.ToProblemDetails())
+	default:
+		return fmt.Errorf("%w: unsupported error type %T", ErrInvalidResponseFormatter, err)
+	}
+}
+
+// formatJSONAPI writes a JSON:API errors document
+func formatJSONAPI(w io.Writer, err error) error {
+	switch typed := err.(type) {
+	case *ErrorCollection:
+		return json.NewEncoder(w).Encode(typed.ToJSONAPI())
+	case *CustomError:
+		return json.NewEncoder(w).Encode(typed.ToJSONAPI())
+	default:
+		return fmt.Errorf("%w: unsupported error type %T", ErrInvalidResponseFormatter, err)
+	}
+}
+
+// formatXML writes the XML representation with a declaration
+func formatXML(w io.Writer, err error) error {
+	switch typed := err.(type) {
+	case *ErrorCollection:
+		return typed.WriteXML(w, WithXMLHeader())
+	case *CustomError:
+		return typed.WriteXML(w, WithXMLHeader())
+	default:
+		return fmt.Errorf("%w: unsupported error type %T", ErrInvalidResponseFormatter, err)
+	}
+}
+
+// formatText writes the text/plain representation
+func formatText(w io.Writer, err error) error {
+	switch typed := err.(type) {
+	case *ErrorCollection:
+		return typed.WriteText(w)
+	case *CustomError:
+		return typed.WriteText(w)
+	default:
+		return fmt.Errorf("%w: unsupported error type %T", ErrInvalidResponseFormatter, err)
+	}
+}
+
+// htmlErrorPage holds the values rendered by htmlErrorTemplate
+type htmlErrorPage struct {
+	Status           int
+	StatusText       string
+	Message          string
+	Code             string
+	RequestID        string
+	ValidationErrors []ValidationError
+	Messages         []string
+}
+
+// htmlErrorTemplate renders a minimal error page; html/template escapes every value
+var htmlErrorTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>{{.Status}} {{.StatusText}}</title></head>
+<body>
+<h1>{{.Status}} {{.StatusText}}</h1>
+<p>{{.Message}}</p>
+{{- if .ValidationErrors}}
+<ul>
+{{- range .ValidationErrors}}
+<li><strong>{{.Field}}</strong>: {{.Message}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Messages}}
+<ul>
+{{- range .Messages}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<p><small>{{.Code}}{{if .RequestID}} &middot; request {{.RequestID}}{{end}}</small></p>
+</body>
+</html>
+`))
+
+// formatHTML writes a minimal HTML error page
+// Collections list their validation errors, and outside production mode the
+// client-safe messages of their members
+func formatHTML(w io.Writer, err error) error {
+	var page htmlErrorPage
+	switch typed := err.(type) {
+	case *ErrorCollection:
+		page.Status = typed.ToHTTPStatus()
+		page.Message = typed.Error()
+		page.Code = ERROR_CODE_MULTIPLE_ERRORS
+
+		typed.mu.RLock()
+		page.RequestID = typed.RequestID
+		page.ValidationErrors = append([]ValidationError(nil), typed.ValidationErrors...)
+		if !GetConfig().ProductionMode {
+			for _, member := range typed.Errors {
+				page.Messages = append(page.Messages, member.ClientSafeMessage())
+			}
+		}
+		typed.mu.RUnlock()
+	case *CustomError:
+		page.Status = typed.ToHTTPStatus()
+		page.Message = typed.ClientSafeMessage()
+		page.Code = typed.Code
+		page.RequestID = typed.RequestID
+	default:
+		return fmt.Errorf("%w: unsupported error type %T", ErrInvalidResponseFormatter, err)
+	}
+	page.StatusText = http.StatusText(page.Status)
+
+	return htmlErrorTemplate.Execute(w, page)
+}
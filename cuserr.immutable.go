@@ -0,0 +1,104 @@
+// Package cuserr provides immutable, copy-on-write error values.
+// This file contains Freeze, Clone and the derivation helpers used by With* methods.
+package cuserr
+
+import "fmt"
+
+// Freeze marks the error as immutable and returns it
+// With* methods on a frozen error leave it untouched and return a frozen copy
+// carrying the change, so prototypes can be cached in package variables and
+// enriched by different layers concurrently. Exported fields of a frozen error
+// must not be assigned directly.
+func (e *CustomError) Freeze() *CustomError {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.frozen = true
+	return e
+}
+
+// IsFrozen reports whether the error is immutable
+func (e *CustomError) IsFrozen() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.frozen
+}
+
+// Clone returns a mutable copy of the error
+// Metadata is copied; the stack trace, wrapped error and sentinel are shared
+// since they are never modified in place
+func (e *CustomError) Clone() *CustomError {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.cloneLocked()
+}
+
+// WithCode sets the error code
+func (e *CustomError) WithCode(code string) *CustomError {
+	target := e.lockWritable()
+	defer target.mu.Unlock()
+
+	target.Code = code
+	return target
+}
+
+// WithMessage sets the error message
+func (e *CustomError) WithMessage(message string) *CustomError {
+	target := e.lockWritable()
+	defer target.mu.Unlock()
+
+	target.Message = message
+	return target
+}
+
+// WithMessagef sets the error message with formatting
+func (e *CustomError) WithMessagef(format string, args ...interface{}) *CustomError {
+	return e.WithMessage(fmt.Sprintf(format, args...))
+}
+
+// lockWritable returns the error to modify with its write lock held
+// That is the receiver itself, or a frozen copy when frozen. The frozen check
+// happens under the receiver's write lock, so a concurrent Freeze cannot slip
+// in between the check and the write. Callers must unlock the returned error.
+func (e *CustomError) lockWritable() *CustomError {
+	e.mu.Lock()
+	if !e.frozen {
+		return e
+	}
+
+	derived := e.cloneLocked()
+	e.mu.Unlock()
+
+	derived.frozen = true
+	derived.mu.Lock()
+	return derived
+}
+
+// cloneLocked copies the error; caller must hold at least a read lock
+func (e *CustomError) cloneLocked() *CustomError {
+	clone := &CustomError{
+		Category:          e.Category,
+		Code:              e.Code,
+		Message:           e.Message,
+		RequestID:         e.RequestID,
+		Timestamp:         e.Timestamp,
+		stackTrace:        e.stackTrace,
+		stackTraceCleared: e.stackTraceCleared,
+		Wrapped:           e.Wrapped,
+		Sentinel:          e.Sentinel,
+		severity:          e.severity,
+		retryable:         e.retryable,
+		retryAfter:        e.retryAfter,
+	}
+
+	if e.metadata != nil {
+		clone.metadata = make(map[string]interface{}, len(e.metadata))
+		for k, v := range e.metadata {
+			clone.metadata[k] = v
+		}
+	}
+
+	return clone
+}
@@ -0,0 +1,537 @@
+// Package cuserr provides a streaming JSON encoder for errors.
+// This file contains WriteJSON for CustomError and ErrorCollection and the writer behind ToJSONString.
+package cuserr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONOption configures WriteJSON
+type JSONOption func(*jsonWriter)
+
+// WithJSONIndent pretty-prints the output, indenting nested values with indent
+// The layout matches json.MarshalIndent(v, "", indent)
+func WithJSONIndent(indent string) JSONOption {
+	return func(jw *jsonWriter) {
+		jw.indent = indent
+	}
+}
+
+// jsonWriter appends JSON tokens to a reusable buffer
+type jsonWriter struct {
+	buf       []byte
+	indent    string
+	depth     int
+	needComma bool
+}
+
+// jsonWriterPool recycles writers and their buffers
+var jsonWriterPool = sync.Pool{
+	New: func() interface{} {
+		return &jsonWriter{buf: make([]byte, 0, JSON_WRITER_INITIAL_BUFFER)}
+	},
+}
+
+// getJSONWriter returns a reset writer from the pool
+func getJSONWriter(opts []JSONOption) *jsonWriter {
+	jw := jsonWriterPool.Get().(*jsonWriter)
+	jw.buf = jw.buf[:0]
+	jw.indent = ""
+	jw.depth = 0
+	jw.needComma = false
+	for _, opt := range opts {
+		opt(jw)
+	}
+	return jw
+}
+
+// putJSONWriter returns a writer to the pool unless its buffer grew too large
+func putJSONWriter(jw *jsonWriter) {
+	if cap(jw.buf) > JSON_WRITER_MAX_POOLED_BUFFER {
+		return
+	}
+	jsonWriterPool.Put(jw)
+}
+
+// WriteJSON writes the error to w in the ToJSON format
+// Keys are written in sorted order, strings are escaped exactly like
+// encoding/json, and the output is byte-identical to json.Marshal(e.ToJSON())
+// (or json.MarshalIndent with WithJSONIndent). Non-finite floats in metadata
+// are written as strings instead of failing the encoding.
+func (e *CustomError) WriteJSON(w io.Writer, opts ...JSONOption) error {
+	jw := getJSONWriter(opts)
+	defer putJSONWriter(jw)
+
+	jw.beginObject()
+	jw.key(JSON_FIELD_ERROR)
+	e.appendJSON(jw, 1)
+	jw.endObject()
+
+	_, err := w.Write(jw.buf)
+	return err
+}
+
+// ToJSONString converts error to JSON string format
+// The output is the compact form written by WriteJSON
+func (e *CustomError) ToJSONString() string {
+	jw := getJSONWriter(nil)
+	defer putJSONWriter(jw)
+
+	jw.beginObject()
+	jw.key(JSON_FIELD_ERROR)
+	e.appendJSON(jw, 1)
+	jw.endObject()
+
+	return string(jw.buf)
+}
+
+// appendJSON writes the error object; depth bounds recursion into nested causes
+func (e *CustomError) appendJSON(jw *jsonWriter, depth int) {
+	retryable := e.IsRetryable()
+	retryAfter, hasRetryAfter := e.RetryAfter()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	jw.beginObject()
+	jw.stringField(JSON_FIELD_CATEGORY, string(e.Category))
+	if causes := splitCauses(e.Wrapped); len(causes) > 0 {
+		jw.key(JSON_FIELD_CAUSES)
+		appendCausesJSON(jw, causes, depth)
+	}
+	jw.stringField(JSON_FIELD_CODE, e.Code)
+	jw.stringField(JSON_FIELD_MESSAGE, e.Message)
+	if len(e.metadata) > 0 {
+		jw.key(JSON_FIELD_METADATA)
+		jw.mapValue(e.metadata)
+	}
+	if e.RequestID != "" {
+		jw.stringField(JSON_FIELD_REQUEST_ID, e.RequestID)
+	}
+	if hasRetryAfter {
+		jw.key(JSON_FIELD_RETRY_AFTER_SECONDS)
+		jw.intValue(retryAfterSeconds(retryAfter))
+	}
+	jw.key(JSON_FIELD_RETRYABLE)
+	jw.boolValue(retryable)
+	jw.key(JSON_FIELD_TIMESTAMP)
+	jw.timeValue(e.Timestamp, time.RFC3339)
+	jw.endObject()
+}
+
+// appendCausesJSON writes causes in the causesJSON format
+func appendCausesJSON(jw *jsonWriter, causes []error, depth int) {
+	jw.beginArray()
+	for _, cause := range causes {
+		jw.element()
+		jw.beginObject()
+		if customErr, ok := cause.(*CustomError); ok {
+			jw.stringField(JSON_FIELD_CATEGORY, string(customErr.Category))
+			if nested := customErr.Causes(); len(nested) > 0 && depth < MAX_ERROR_CHAIN_DEPTH {
+				jw.key(JSON_FIELD_CAUSES)
+				appendCausesJSON(jw, nested, depth+1)
+			}
+			jw.stringField(JSON_FIELD_CODE, customErr.Code)
+			jw.stringField(JSON_FIELD_MESSAGE, customErr.Message)
+		} else {
+			jw.stringField(JSON_FIELD_MESSAGE, cause.Error())
+		}
+		jw.endObject()
+	}
+	jw.endArray()
+}
+
+// WriteJSON writes the collection to w in the ToJSON format
+// The output is byte-identical to json.Marshal(ec.ToJSON()), or
+// json.MarshalIndent with WithJSONIndent
+func (ec *ErrorCollection) WriteJSON(w io.Writer, opts ...JSONOption) error {
+	jw := getJSONWriter(opts)
+	defer putJSONWriter(jw)
+
+	ec.appendJSON(jw)
+
+	_, err := w.Write(jw.buf)
+	return err
+}
+
+// appendJSON writes the collection envelope
+func (ec *ErrorCollection) appendJSON(jw *jsonWriter) {
+	message := ec.Error()
+
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	jw.beginObject()
+	jw.key(JSON_FIELD_ERROR)
+	jw.beginObject()
+	jw.stringField(JSON_FIELD_CATEGORY, string(ec.categoryLocked()))
+	jw.stringField(JSON_FIELD_CODE, ERROR_CODE_MULTIPLE_ERRORS)
+	if len(ec.Context) > 0 {
+		jw.key(JSON_FIELD_CONTEXT)
+		jw.stringMapValue(ec.Context)
+	}
+	jw.key(JSON_FIELD_ERROR_COUNT)
+	jw.intValue(int64(len(ec.Errors) + len(ec.ValidationErrors)))
+	if len(ec.Errors) > 0 {
+		jw.key(JSON_FIELD_ERRORS)
+		jw.beginArray()
+		for _, err := range ec.Errors {
+			jw.element()
+			err.appendJSON(jw, 1)
+		}
+		jw.endArray()
+	}
+	jw.stringField(JSON_FIELD_MESSAGE, message)
+	if ec.RequestID != "" {
+		jw.stringField(JSON_FIELD_REQUEST_ID, ec.RequestID)
+	}
+	jw.stringField(JSON_FIELD_SUMMARY, ec.Summary)
+	if len(ec.ValidationErrors) > 0 {
+		jw.key(JSON_FIELD_VALIDATION_COUNT)
+		jw.intValue(int64(len(ec.ValidationErrors)))
+		jw.key(JSON_FIELD_VALIDATION_ERRORS)
+		jw.beginArray()
+		for _, validationErr := range ec.ValidationErrors {
+			jw.element()
+			jw.beginObject()
+			jw.stringField(JSON_FIELD_FIELD, validationErr.Field)
+			jw.stringField(JSON_FIELD_MESSAGE, validationErr.Message)
+			if validationErr.Code != "" {
+				jw.stringField(JSON_FIELD_CODE, validationErr.Code)
+			}
+			if validationErr.Value != "" {
+				jw.stringField(JSON_FIELD_VALUE, validationErr.Value)
+			}
+			jw.endObject()
+		}
+		jw.endArray()
+	}
+	jw.endObject()
+	jw.endObject()
+}
+
+// newline starts a new indented line when pretty-printing
+func (jw *jsonWriter) newline() {
+	if jw.indent == "" {
+		return
+	}
+	jw.buf = append(jw.buf, '\n')
+	for i := 0; i < jw.depth; i++ {
+		jw.buf = append(jw.buf, jw.indent...)
+	}
+}
+
+// separate writes the separator before an object member or array element
+func (jw *jsonWriter) separate() {
+	if jw.needComma {
+		jw.buf = append(jw.buf, ',')
+	}
+	jw.newline()
+}
+
+// beginObject opens an object
+func (jw *jsonWriter) beginObject() {
+	jw.buf = append(jw.buf, '{')
+	jw.depth++
+	jw.needComma = false
+}
+
+// endObject closes an object; empty objects stay on one line
+func (jw *jsonWriter) endObject() {
+	jw.depth--
+	if jw.needComma {
+		jw.newline()
+	}
+	jw.buf = append(jw.buf, '}')
+	jw.needComma = true
+}
+
+// beginArray opens an array
+func (jw *jsonWriter) beginArray() {
+	jw.buf = append(jw.buf, '[')
+	jw.depth++
+	jw.needComma = false
+}
+
+// endArray closes an array; empty arrays stay on one line
+func (jw *jsonWriter) endArray() {
+	jw.depth--
+	if jw.needComma {
+		jw.newline()
+	}
+	jw.buf = append(jw.buf, ']')
+	jw.needComma = true
+}
+
+// key writes an object member name
+func (jw *jsonWriter) key(name string) {
+	jw.separate()
+	jw.buf = appendJSONString(jw.buf, name)
+	jw.buf = append(jw.buf, ':')
+	if jw.indent != "" {
+		jw.buf = append(jw.buf, ' ')
+	}
+	jw.needComma = false
+}
+
+// element prepares an array element
+func (jw *jsonWriter) element() {
+	jw.separate()
+	jw.needComma = false
+}
+
+// stringField writes a string member
+func (jw *jsonWriter) stringField(name, value string) {
+	jw.key(name)
+	jw.stringValue(value)
+}
+
+// stringValue writes an escaped string
+func (jw *jsonWriter) stringValue(value string) {
+	jw.buf = appendJSONString(jw.buf, value)
+	jw.needComma = true
+}
+
+// intValue writes an integer
+func (jw *jsonWriter) intValue(value int64) {
+	jw.buf = strconv.AppendInt(jw.buf, value, 10)
+	jw.needComma = true
+}
+
+// uintValue writes an unsigned integer
+func (jw *jsonWriter) uintValue(value uint64) {
+	jw.buf = strconv.AppendUint(jw.buf, value, 10)
+	jw.needComma = true
+}
+
+// boolValue writes a boolean
+func (jw *jsonWriter) boolValue(value bool) {
+	jw.buf = strconv.AppendBool(jw.buf, value)
+	jw.needComma = true
+}
+
+// floatValue writes a float using the encoding/json number format
+func (jw *jsonWriter) floatValue(value float64, bits int) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		jw.stringValue(strconv.FormatFloat(value, 'g', -1, bits))
+		return
+	}
+	jw.buf = appendJSONFloat(jw.buf, value, bits)
+	jw.needComma = true
+}
+
+// timeValue writes a time as a string in the given layout
+func (jw *jsonWriter) timeValue(value time.Time, layout string) {
+	jw.buf = append(jw.buf, '"')
+	jw.buf = value.AppendFormat(jw.buf, layout)
+	jw.buf = append(jw.buf, '"')
+	jw.needComma = true
+}
+
+// mapValue writes a metadata map with sorted keys
+func (jw *jsonWriter) mapValue(values map[string]interface{}) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	jw.beginObject()
+	for _, key := range keys {
+		jw.key(key)
+		jw.value(values[key])
+	}
+	jw.endObject()
+}
+
+// stringMapValue writes a string map with sorted keys
+func (jw *jsonWriter) stringMapValue(values map[string]string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	jw.beginObject()
+	for _, key := range keys {
+		jw.stringField(key, values[key])
+	}
+	jw.endObject()
+}
+
+// value writes a metadata value
+// Common types are written directly; anything else goes through encoding/json
+func (jw *jsonWriter) value(value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		jw.buf = append(jw.buf, "null"...)
+		jw.needComma = true
+	case string:
+		jw.stringValue(v)
+	case bool:
+		jw.boolValue(v)
+	case int:
+		jw.intValue(int64(v))
+	case int8:
+		jw.intValue(int64(v))
+	case int16:
+		jw.intValue(int64(v))
+	case int32:
+		jw.intValue(int64(v))
+	case int64:
+		jw.intValue(v)
+	case uint:
+		jw.uintValue(uint64(v))
+	case uint8:
+		jw.uintValue(uint64(v))
+	case uint16:
+		jw.uintValue(uint64(v))
+	case uint32:
+		jw.uintValue(uint64(v))
+	case uint64:
+		jw.uintValue(v)
+	case float32:
+		jw.floatValue(float64(v), 32)
+	case float64:
+		jw.floatValue(v, 64)
+	case time.Duration:
+		jw.intValue(int64(v))
+	case time.Time:
+		jw.timeValue(v, time.RFC3339Nano)
+	case []string:
+		jw.beginArray()
+		for _, item := range v {
+			jw.element()
+			jw.stringValue(item)
+		}
+		jw.endArray()
+	case []interface{}:
+		jw.beginArray()
+		for _, item := range v {
+			jw.element()
+			jw.value(item)
+		}
+		jw.endArray()
+	case map[string]interface{}:
+		jw.mapValue(v)
+	case map[string]string:
+		jw.stringMapValue(v)
+	default:
+		jw.encoded(value)
+	}
+}
+
+// encoded writes a value through encoding/json, re-indented when pretty-printing
+// Values encoding/json rejects are written as their FormatMetadataValue string
+func (jw *jsonWriter) encoded(value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		jw.stringValue(FormatMetadataValue(value))
+		return
+	}
+	if jw.indent == "" {
+		jw.buf = append(jw.buf, data...)
+		jw.needComma = true
+		return
+	}
+
+	var prefix []byte
+	for i := 0; i < jw.depth; i++ {
+		prefix = append(prefix, jw.indent...)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, string(prefix), jw.indent); err != nil {
+		jw.buf = append(jw.buf, data...)
+	} else {
+		jw.buf = append(jw.buf, indented.Bytes()...)
+	}
+	jw.needComma = true
+}
+
+// appendJSONFloat appends a finite float the way encoding/json formats it
+func appendJSONFloat(buf []byte, value float64, bits int) []byte {
+	format := byte('f')
+	if abs := math.Abs(value); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, value, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
+
+// jsonHex is used to write \u00XX escapes
+const jsonHex = "0123456789abcdef"
+
+// appendJSONString appends s as a quoted JSON string
+// Escaping matches encoding/json: control characters, quotes, backslashes,
+// HTML-sensitive characters and U+2028/U+2029 are escaped, and invalid UTF-8
+// bytes are replaced with U+FFFD
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', jsonHex[c>>4], jsonHex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', jsonHex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
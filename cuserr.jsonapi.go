@@ -0,0 +1,211 @@
+// Package cuserr provides JSON:API error object support.
+// This file contains the JSON:API renderer and the decoder of JSON:API error documents.
+package cuserr
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// JSONAPIDocument is a JSON:API top-level document carrying errors
+type JSONAPIDocument struct {
+	Errors []JSONAPIError         `json:"errors"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// JSONAPIError is a JSON:API error object
+type JSONAPIError struct {
+	ID     string                 `json:"id,omitempty"`
+	Status string                 `json:"status,omitempty"`
+	Code   string                 `json:"code,omitempty"`
+	Title  string                 `json:"title,omitempty"`
+	Detail string                 `json:"detail,omitempty"`
+	Source *JSONAPIErrorSource    `json:"source,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// JSONAPIErrorSource points to the part of the request that caused the error
+type JSONAPIErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+// JSONAPIPointer converts a field path to a pointer into the request's resource attributes
+// "items[0].name" becomes "/data/attributes/items/0/name"
+func JSONAPIPointer(field string) string {
+	if field == "" {
+		return ""
+	}
+	return JSONAPI_ATTRIBUTES_POINTER + JSONPointer(field)
+}
+
+// FieldFromJSONAPIPointer converts a JSON:API source pointer back to a field path
+// The "/data/attributes" and "/data/relationships" prefixes are removed
+func FieldFromJSONAPIPointer(pointer string) string {
+	for _, prefix := range []string{JSONAPI_ATTRIBUTES_POINTER, "/data/relationships"} {
+		if strings.HasPrefix(pointer, prefix+"/") {
+			return FieldFromJSONPointer(strings.TrimPrefix(pointer, prefix))
+		}
+	}
+	return FieldFromJSONPointer(pointer)
+}
+
+// ToJSONAPI converts the error to a JSON:API errors document
+// Detail is the client-safe message and meta is the metadata filtered as in
+// ToClientJSON plus the request ID. Validation errors with a field get a
+// source pointer, and the parameter metadata becomes source.parameter. The id
+// member is left empty since it names one occurrence, not the request.
+func (e *CustomError) ToJSONAPI() *JSONAPIDocument {
+	return &JSONAPIDocument{Errors: []JSONAPIError{e.jsonAPIError()}}
+}
+
+// jsonAPIError converts the error to a JSON:API error object
+func (e *CustomError) jsonAPIError() JSONAPIError {
+	status := e.ToHTTPStatus()
+	entry := JSONAPIError{
+		Status: strconv.Itoa(status),
+		Code:   e.Code,
+		Title:  problemTitle(LookupProblemType(e.Code), status),
+		Detail: e.ClientSafeMessage(),
+	}
+
+	field, _ := e.GetMetadata(MetaField)
+	parameter, _ := e.GetMetadata(MetaParameter)
+	if e.Category != ErrorCategoryValidation {
+		field = ""
+	}
+	if field != "" || parameter != "" {
+		entry.Source = &JSONAPIErrorSource{Pointer: JSONAPIPointer(field), Parameter: parameter}
+	}
+
+	metadata := e.clientMetadata()
+	if e.RequestID != "" {
+		metadata[JSON_FIELD_REQUEST_ID] = e.RequestID
+	}
+	if len(metadata) > 0 {
+		entry.Meta = metadata
+	}
+	return entry
+}
+
+// ToJSONAPI converts the collection to a JSON:API errors document
+// Validation errors come first, each with a source pointer; the request ID
+// and summary are written to the top-level meta. Rejected values are only
+// included outside production mode.
+func (ec *ErrorCollection) ToJSONAPI() *JSONAPIDocument {
+	productionMode := GetConfig().ProductionMode
+
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	doc := &JSONAPIDocument{
+		Errors: make([]JSONAPIError, 0, len(ec.ValidationErrors)+len(ec.Errors)),
+		Meta:   map[string]interface{}{JSON_FIELD_SUMMARY: ec.Summary},
+	}
+	if ec.RequestID != "" {
+		doc.Meta[JSON_FIELD_REQUEST_ID] = ec.RequestID
+	}
+
+	status := CategoryToHTTPStatus(ErrorCategoryValidation)
+	for _, validationErr := range ec.ValidationErrors {
+		code := validationErr.Code
+		if code == "" {
+			code = ERROR_CODE_INVALID_INPUT
+		}
+		entry := JSONAPIError{
+			Status: strconv.Itoa(status),
+			Code:   code,
+			Title:  problemTitle(LookupProblemType(code), status),
+			Detail: validationErr.Message,
+			Source: &JSONAPIErrorSource{Pointer: JSONAPIPointer(validationErr.Field)},
+		}
+		if validationErr.Value != "" && !productionMode {
+			entry.Meta = map[string]interface{}{JSON_FIELD_VALUE: validationErr.Value}
+		}
+		doc.Errors = append(doc.Errors, entry)
+	}
+	for _, err := range ec.Errors {
+		doc.Errors = append(doc.Errors, err.jsonAPIError())
+	}
+
+	return doc
+}
+
+// ParseJSONAPIErrors decodes a JSON:API errors document into an ErrorCollection
+// Integral meta numbers decode as int64 and other numbers as float64
+func ParseJSONAPIErrors(data []byte) (*ErrorCollection, error) {
+	var doc JSONAPIDocument
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.ToErrorCollection(), nil
+}
+
+// ToErrorCollection converts the document into an ErrorCollection
+// Error objects that resolve to the validation category, point at a field and
+// carry no meta besides a rejected value become ValidationErrors. All others
+// become CustomErrors whose category comes from a registered code or the
+// status, with meta as metadata and meta.request_id as the request ID. The
+// top-level meta restores the summary and request ID. Error ids are ignored.
+func (doc *JSONAPIDocument) ToErrorCollection() *ErrorCollection {
+	summary, _ := doc.Meta[JSON_FIELD_SUMMARY].(string)
+	collection := NewErrorCollection(summary)
+	if requestID, ok := doc.Meta[JSON_FIELD_REQUEST_ID].(string); ok {
+		collection.WithRequestID(requestID)
+	}
+
+	for _, entry := range doc.Errors {
+		message := entry.Detail
+		if message == "" {
+			message = entry.Title
+		}
+		status, _ := strconv.Atoi(entry.Status)
+		err := codeStatusError(entry.Code, status, message)
+
+		var field, parameter string
+		if entry.Source != nil {
+			field = FieldFromJSONAPIPointer(entry.Source.Pointer)
+			parameter = entry.Source.Parameter
+		}
+
+		if validationErr, ok := jsonAPIValidationError(entry, err, field, parameter); ok {
+			collection.ValidationErrors = append(collection.ValidationErrors, validationErr)
+			continue
+		}
+
+		if field != "" {
+			err = err.WithMetadata(MetaField, field)
+		}
+		if parameter != "" {
+			err = err.WithMetadata(MetaParameter, parameter)
+		}
+		for key, value := range entry.Meta {
+			if requestID, ok := value.(string); ok && key == JSON_FIELD_REQUEST_ID {
+				err = err.WithRequestID(requestID)
+				continue
+			}
+			err = err.WithMetadataValue(key, normalizeJSONValue(value))
+		}
+		collection.Add(err)
+	}
+
+	return collection
+}
+
+// jsonAPIValidationError converts an error object into a ValidationError when nothing would be lost
+func jsonAPIValidationError(entry JSONAPIError, err *CustomError, field, parameter string) (ValidationError, bool) {
+	if err.Category != ErrorCategoryValidation || field == "" || parameter != "" {
+		return ValidationError{}, false
+	}
+
+	value, hasValue := entry.Meta[JSON_FIELD_VALUE].(string)
+	if len(entry.Meta) > 1 || (len(entry.Meta) == 1 && !hasValue) {
+		return ValidationError{}, false
+	}
+
+	return ValidationError{Field: field, Message: err.Message, Code: entry.Code, Value: value}, true
+}
@@ -86,17 +86,29 @@ func (l *DefaultSlogLogger) LogError(ctx context.Context, err *CustomError) {
 	}
 
 	fields := err.ToLogFields()
-	l.Log(ctx, LogLevelError, err.Message, fields)
+	l.LogSeverity(ctx, err.Severity(), err.Message, fields)
 }
 
 // LogErrorCollection logs an ErrorCollection with structured fields
+// The most severe member determines the level
 func (l *DefaultSlogLogger) LogErrorCollection(ctx context.Context, collection *ErrorCollection) {
 	if collection == nil || collection.IsEmpty() {
 		return
 	}
 
 	fields := collection.ToLogFields()
-	l.Log(ctx, LogLevelError, collection.Error(), fields)
+	l.LogSeverity(ctx, collection.Severity(), collection.Error(), fields)
+}
+
+// LogSeverity logs a message at the slog level matching the severity
+// Notice, critical and fatal use the custom SlogLevel* levels
+func (l *DefaultSlogLogger) LogSeverity(ctx context.Context, severity Severity, message string, fields map[string]interface{}) {
+	args := make([]any, 0, len(fields)*2)
+	for key, value := range fields {
+		args = append(args, key, value)
+	}
+
+	l.logger.LogAttrs(ctx, severity.SlogLevel(), message, argsToAttrs(args)...)
 }
 
 // Helper function to convert args to slog.Attr
@@ -118,6 +130,7 @@ func (e *CustomError) ToLogFields() map[string]interface{} {
 		"error_category": string(e.Category),
 		"error_code":     e.Code,
 		"error_message":  e.Message,
+		"severity":       e.Severity().String(),
 		"timestamp":      e.Timestamp.Format(time.RFC3339),
 	}
 
@@ -132,9 +145,12 @@ func (e *CustomError) ToLogFields() map[string]interface{} {
 		fields[fmt.Sprintf("meta_%s", key)] = value
 	}
 
-	// Add wrapped error if present
-	if e.Wrapped != nil {
-		fields["wrapped_error"] = e.Wrapped.Error()
+	// Add wrapped error if present; several causes are also listed individually
+	if causes := e.Causes(); len(causes) == 1 {
+		fields["wrapped_error"] = causes[0].Error()
+	} else if len(causes) > 1 {
+		fields["wrapped_error"] = joinCauseMessages(causes)
+		fields["causes"] = causeMessages(causes)
 	}
 
 	// Add stack trace info if available
@@ -192,13 +208,14 @@ func (ec *ErrorCollection) ToLogFields() map[string]interface{} {
 	defer ec.mu.RUnlock()
 
 	fields := map[string]interface{}{
-		"error_category":         string(ErrorCategoryValidation),
-		"error_code":             "MULTIPLE_ERRORS",
+		"error_category":         string(ec.categoryLocked()),
+		"error_code":             ERROR_CODE_MULTIPLE_ERRORS,
 		"error_message":          ec.Error(),
 		"error_summary":          ec.Summary,
 		"total_error_count":      ec.Count(),
 		"validation_error_count": len(ec.ValidationErrors),
 		"custom_error_count":     len(ec.Errors),
+		"severity":               ec.severityLocked().String(),
 		"timestamp":              time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -318,7 +335,7 @@ func (l *ZapLogger) Log(ctx context.Context, level LogLevel, message string, fie
 func (l *ZapLogger) LogError(ctx context.Context, err *CustomError) {
 	if err != nil {
 		fields := err.ToLogFields()
-		l.Log(ctx, LogLevelError, err.Message, fields)
+		l.Log(ctx, err.Severity().LogLevel(), err.Message, fields)
 	}
 }
 
@@ -326,7 +343,7 @@ func (l *ZapLogger) LogError(ctx context.Context, err *CustomError) {
 func (l *ZapLogger) LogErrorCollection(ctx context.Context, collection *ErrorCollection) {
 	if collection != nil && !collection.IsEmpty() {
 		fields := collection.ToLogFields()
-		l.Log(ctx, LogLevelError, collection.Error(), fields)
+		l.Log(ctx, collection.Severity().LogLevel(), collection.Error(), fields)
 	}
 }
 
@@ -353,7 +370,7 @@ func (l *LogrusLogger) Log(ctx context.Context, level LogLevel, message string,
 func (l *LogrusLogger) LogError(ctx context.Context, err *CustomError) {
 	if err != nil {
 		fields := err.ToLogFields()
-		l.Log(ctx, LogLevelError, err.Message, fields)
+		l.Log(ctx, err.Severity().LogLevel(), err.Message, fields)
 	}
 }
 
@@ -361,7 +378,7 @@ func (l *LogrusLogger) LogError(ctx context.Context, err *CustomError) {
 func (l *LogrusLogger) LogErrorCollection(ctx context.Context, collection *ErrorCollection) {
 	if collection != nil && !collection.IsEmpty() {
 		fields := collection.ToLogFields()
-		l.Log(ctx, LogLevelError, collection.Error(), fields)
+		l.Log(ctx, collection.Severity().LogLevel(), collection.Error(), fields)
 	}
 }
 
@@ -380,10 +397,22 @@ func NewLoggingErrorHandler(logger StructuredLogger, level LogLevel) *LoggingErr
 }
 
 // Handle logs the error
+// The configured level acts as a floor: errors more severe than it are logged
+// at their own severity
 func (h *LoggingErrorHandler) Handle(ctx context.Context, err *CustomError) {
 	if h.logger != nil && err != nil {
 		fields := err.ToLogFields()
-		h.logger.Log(ctx, h.level, err.Message, fields)
+
+		severity := err.Severity()
+		if floor := severityForLogLevel(h.level); severity < floor {
+			severity = floor
+		}
+
+		if severityLogger, ok := h.logger.(SeverityLogger); ok {
+			severityLogger.LogSeverity(ctx, severity, err.Message, fields)
+			return
+		}
+		h.logger.Log(ctx, severity.LogLevel(), err.Message, fields)
 	}
 }
 
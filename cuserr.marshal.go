@@ -0,0 +1,271 @@
+// Package cuserr provides lossless JSON encoding and decoding of errors.
+// This file contains MarshalJSON and UnmarshalJSON for CustomError.
+package cuserr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// customErrorJSON is the wire format used by MarshalJSON and UnmarshalJSON
+type customErrorJSON struct {
+	Category     ErrorCategory          `json:"category"`
+	Code         string                 `json:"code"`
+	SentinelCode string                 `json:"sentinel_code,omitempty"`
+	Message      string                 `json:"message"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	RequestID    string                 `json:"request_id,omitempty"`
+	Timestamp    time.Time              `json:"timestamp"`
+	Severity     string                 `json:"severity,omitempty"`
+	Retryable    *bool                  `json:"retryable,omitempty"`
+	RetryAfter   string                 `json:"retry_after,omitempty"`
+	Frozen       bool                   `json:"frozen,omitempty"`
+	StackTrace   []StackFrame           `json:"stack_trace,omitempty"`
+	Causes       []json.RawMessage      `json:"causes,omitempty"`
+}
+
+// causeJSON is the wire format of a cause that is not a CustomError
+type causeJSON struct {
+	Message string            `json:"message"`
+	Causes  []json.RawMessage `json:"causes,omitempty"`
+}
+
+// decodedError stands in for a foreign error after decoding
+// It keeps the original message and the decoded errors it wrapped
+type decodedError struct {
+	message string
+	causes  []error
+}
+
+// Error returns the original error message
+func (d *decodedError) Error() string {
+	return d.message
+}
+
+// Unwrap returns the decoded errors the original error wrapped
+func (d *decodedError) Unwrap() []error {
+	return d.causes
+}
+
+// MarshalJSON encodes the error losslessly
+// Unlike ToJSON, the output keeps the stack trace, explicit severity and
+// retry settings, the frozen flag, the registered code of the sentinel and the
+// full cause chain: nested CustomErrors are encoded recursively and other
+// errors become message nodes. UnmarshalJSON restores it.
+func (e *CustomError) MarshalJSON() ([]byte, error) {
+	return e.marshalJSON(0)
+}
+
+// marshalJSON encodes the error; depth bounds recursion into nested causes
+func (e *CustomError) marshalJSON(depth int) ([]byte, error) {
+	e.mu.RLock()
+	wire := customErrorJSON{
+		Category:   e.Category,
+		Code:       e.Code,
+		Message:    e.Message,
+		RequestID:  e.RequestID,
+		Timestamp:  e.Timestamp,
+		Retryable:  e.retryable,
+		Frozen:     e.frozen,
+		StackTrace: e.stackTrace,
+	}
+	if e.Sentinel != nil {
+		if spec, registered := LookupSentinel(e.Sentinel); registered {
+			wire.SentinelCode = spec.Code
+		}
+	}
+	if len(e.metadata) > 0 {
+		wire.Metadata = make(map[string]interface{}, len(e.metadata))
+		for key, value := range e.metadata {
+			wire.Metadata[key] = value
+		}
+	}
+	if e.severity != 0 {
+		wire.Severity = e.severity.String()
+	}
+	if e.retryAfter > 0 {
+		wire.RetryAfter = e.retryAfter.String()
+	}
+	causes := splitCauses(e.Wrapped)
+	e.mu.RUnlock()
+
+	if depth < MAX_ERROR_CHAIN_DEPTH {
+		for _, cause := range causes {
+			encoded, err := marshalCause(cause, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			wire.Causes = append(wire.Causes, encoded)
+		}
+	}
+
+	return json.Marshal(wire)
+}
+
+// marshalCause encodes a cause as a nested CustomError or a message node
+func marshalCause(cause error, depth int) (json.RawMessage, error) {
+	if customErr, ok := cause.(*CustomError); ok {
+		return customErr.marshalJSON(depth)
+	}
+
+	node := causeJSON{Message: cause.Error()}
+	if depth < MAX_ERROR_CHAIN_DEPTH {
+		var inner []error
+		switch unwrapper := cause.(type) {
+		case interface{ Unwrap() error }:
+			if unwrapped := unwrapper.Unwrap(); unwrapped != nil {
+				inner = []error{unwrapped}
+			}
+		case interface{ Unwrap() []error }:
+			inner = unwrapper.Unwrap()
+		}
+		for _, innerErr := range inner {
+			encoded, err := marshalCause(innerErr, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Causes = append(node.Causes, encoded)
+		}
+	}
+	return json.Marshal(node)
+}
+
+// UnmarshalJSON decodes an error produced by MarshalJSON
+// The sentinel is restored from the sentinel registry by its registered code,
+// falling back to the error code, so errors.Is keeps working for registered
+// sentinels even after WithCode. Frozen errors decode frozen. Foreign causes are restored as opaque
+// errors carrying the original message. Integral metadata numbers decode as
+// int64 and other numbers as float64.
+func (e *CustomError) UnmarshalJSON(data []byte) error {
+	var wire customErrorJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&wire); err != nil {
+		return err
+	}
+
+	var severity Severity
+	if wire.Severity != "" {
+		parsed, err := ParseSeverity(wire.Severity)
+		if err != nil {
+			return err
+		}
+		severity = parsed
+	}
+
+	var retryAfter time.Duration
+	if wire.RetryAfter != "" {
+		parsed, err := time.ParseDuration(wire.RetryAfter)
+		if err != nil {
+			return fmt.Errorf("invalid retry_after %q: %w", wire.RetryAfter, err)
+		}
+		retryAfter = parsed
+	}
+
+	causes, err := unmarshalCauses(wire.Causes)
+	if err != nil {
+		return err
+	}
+
+	var sentinel error
+	for _, code := range []string{wire.SentinelCode, wire.Code} {
+		if spec, registered := LookupSentinelByCode(code); code != "" && registered {
+			sentinel = spec.Sentinel
+			break
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.Category = wire.Category
+	e.Code = wire.Code
+	e.Message = wire.Message
+	e.RequestID = wire.RequestID
+	e.Timestamp = wire.Timestamp
+	e.Sentinel = sentinel
+	e.Wrapped = joinCauses(causes)
+	e.stackTrace = wire.StackTrace
+	e.stackTraceCleared = false
+	e.severity = severity
+	e.retryable = wire.Retryable
+	e.retryAfter = retryAfter
+	e.frozen = wire.Frozen
+	e.metadata = nil
+	if len(wire.Metadata) > 0 {
+		e.metadata = make(map[string]interface{}, len(wire.Metadata))
+		for key, value := range wire.Metadata {
+			e.metadata[key] = normalizeJSONValue(value)
+		}
+	}
+	return nil
+}
+
+// unmarshalCauses decodes cause nodes; nodes with a code or category are CustomErrors
+func unmarshalCauses(nodes []json.RawMessage) ([]error, error) {
+	causes := make([]error, 0, len(nodes))
+	for _, node := range nodes {
+		var probe struct {
+			Category string            `json:"category"`
+			Code     string            `json:"code"`
+			Message  string            `json:"message"`
+			Causes   []json.RawMessage `json:"causes"`
+		}
+		if err := json.Unmarshal(node, &probe); err != nil {
+			return nil, err
+		}
+
+		if probe.Code != "" || probe.Category != "" {
+			customErr := &CustomError{}
+			if err := customErr.UnmarshalJSON(node); err != nil {
+				return nil, err
+			}
+			causes = append(causes, customErr)
+			continue
+		}
+
+		inner, err := unmarshalCauses(probe.Causes)
+		if err != nil {
+			return nil, err
+		}
+		causes = append(causes, &decodedError{message: probe.Message, causes: inner})
+	}
+	return causes, nil
+}
+
+// normalizeJSONValue converts json.Number values to int64 or float64 and
+// string lists to []string, recursively
+func normalizeJSONValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case json.Number:
+		if i, err := typed.Int64(); err == nil {
+			return i
+		}
+		if f, err := typed.Float64(); err == nil {
+			return f
+		}
+		return typed.String()
+	case map[string]interface{}:
+		for key, nested := range typed {
+			typed[key] = normalizeJSONValue(nested)
+		}
+		return typed
+	case []interface{}:
+		strs := make([]string, 0, len(typed))
+		for i, nested := range typed {
+			typed[i] = normalizeJSONValue(nested)
+			if str, ok := typed[i].(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		// String lists are stored as []string by the package, so restore that type
+		if len(typed) > 0 && len(strs) == len(typed) {
+			return strs
+		}
+		return typed
+	default:
+		return value
+	}
+}
@@ -1,8 +1,9 @@
 package cuserr
 
 import (
+	"encoding/json"
 	"fmt"
-	"strconv"
+	"strings"
 	"time"
 )
 
@@ -27,6 +28,7 @@ const (
 	MetaResourceID = "resource_id"
 	MetaField      = "field"
 	MetaEntity     = "entity"
+	MetaParameter  = "parameter"
 
 	// Error context
 	MetaErrorType    = "error_type"
@@ -39,6 +41,22 @@ const (
 	MetaURL             = "url"
 	MetaStatusCode      = "status_code"
 	MetaResponseTime    = "response_time"
+	MetaRemote          = "remote"
+	MetaOriginService   = "origin_service"
+
+	// HTTP protocol context
+	MetaAllowedMethods      = "allowed_methods"
+	MetaMediaType           = "media_type"
+	MetaSupportedMediaTypes = "supported_media_types"
+	MetaPayloadSize         = "payload_size"
+	MetaPayloadLimit        = "payload_limit"
+	MetaPrecondition        = "precondition"
+	MetaFeature             = "feature"
+	MetaReason              = "reason"
+	MetaRateLimit           = "limit"
+	MetaRateLimitWindow     = "window"
+	MetaRateLimitRemaining  = "rate_limit_remaining"
+	MetaAuthChallenge       = "auth_challenge"
 
 	// Validation context
 	MetaValidationField = "validation_field"
@@ -63,9 +81,19 @@ const (
 	MetaOrganizationID = "organization_id"
 	MetaAccountID      = "account_id"
 	MetaProjectID      = "project_id"
+
+	// Problem details
+	MetaProblemType     = "problem_type"
+	MetaProblemInstance = "problem_instance"
+
+	// GraphQL
+	MetaGraphQLPath      = "graphql_path"
+	MetaGraphQLLocations = "graphql_locations"
 )
 
 // TypedMetadata provides type-safe metadata operations
+// Each method is a thin wrapper around the matching built-in Key; use NewKey for domain fields.
+// When the wrapped error is frozen, Error returns the derived copy carrying the changes.
 type TypedMetadata struct {
 	err *CustomError
 }
@@ -84,18 +112,18 @@ func (e *CustomError) GetTypedMetadata() *TypedMetadata {
 
 // WithUserID adds user ID metadata with type safety
 func (tm *TypedMetadata) WithUserID(userID string) *TypedMetadata {
-	tm.err.WithMetadata(MetaUserID, userID)
+	tm.err = KeyUserID.Set(tm.err, userID)
 	return tm
 }
 
 // GetUserID retrieves user ID from metadata
 func (tm *TypedMetadata) GetUserID() (string, bool) {
-	return tm.err.GetMetadata(MetaUserID)
+	return KeyUserID.Get(tm.err)
 }
 
 // WithRequestID adds request ID metadata
 func (tm *TypedMetadata) WithRequestID(requestID string) *TypedMetadata {
-	tm.err.WithRequestID(requestID) // Use existing method
+	tm.err = tm.err.WithRequestID(requestID) // Use existing method
 	return tm
 }
 
@@ -106,411 +134,381 @@ func (tm *TypedMetadata) GetRequestID() string {
 
 // WithSessionID adds session ID metadata
 func (tm *TypedMetadata) WithSessionID(sessionID string) *TypedMetadata {
-	tm.err.WithMetadata(MetaSessionID, sessionID)
+	tm.err = KeySessionID.Set(tm.err, sessionID)
 	return tm
 }
 
 // GetSessionID retrieves session ID from metadata
 func (tm *TypedMetadata) GetSessionID() (string, bool) {
-	return tm.err.GetMetadata(MetaSessionID)
+	return KeySessionID.Get(tm.err)
 }
 
 // WithTraceID adds distributed tracing ID
 func (tm *TypedMetadata) WithTraceID(traceID string) *TypedMetadata {
-	tm.err.WithMetadata(MetaTraceID, traceID)
+	tm.err = KeyTraceID.Set(tm.err, traceID)
 	return tm
 }
 
 // GetTraceID retrieves trace ID from metadata
 func (tm *TypedMetadata) GetTraceID() (string, bool) {
-	return tm.err.GetMetadata(MetaTraceID)
+	return KeyTraceID.Get(tm.err)
 }
 
 // Operation context methods
 
 // WithOperation adds operation context
 func (tm *TypedMetadata) WithOperation(operation string) *TypedMetadata {
-	tm.err.WithMetadata(MetaOperation, operation)
+	tm.err = KeyOperation.Set(tm.err, operation)
 	return tm
 }
 
 // GetOperation retrieves operation from metadata
 func (tm *TypedMetadata) GetOperation() (string, bool) {
-	return tm.err.GetMetadata(MetaOperation)
+	return KeyOperation.Get(tm.err)
 }
 
 // WithComponent adds component context
 func (tm *TypedMetadata) WithComponent(component string) *TypedMetadata {
-	tm.err.WithMetadata(MetaComponent, component)
+	tm.err = KeyComponent.Set(tm.err, component)
 	return tm
 }
 
 // GetComponent retrieves component from metadata
 func (tm *TypedMetadata) GetComponent() (string, bool) {
-	return tm.err.GetMetadata(MetaComponent)
+	return KeyComponent.Get(tm.err)
 }
 
 // WithService adds service context
 func (tm *TypedMetadata) WithService(service string) *TypedMetadata {
-	tm.err.WithMetadata(MetaService, service)
+	tm.err = KeyService.Set(tm.err, service)
 	return tm
 }
 
 // GetService retrieves service from metadata
 func (tm *TypedMetadata) GetService() (string, bool) {
-	return tm.err.GetMetadata(MetaService)
+	return KeyService.Get(tm.err)
 }
 
 // WithEndpoint adds HTTP endpoint context
 func (tm *TypedMetadata) WithEndpoint(endpoint string) *TypedMetadata {
-	tm.err.WithMetadata(MetaEndpoint, endpoint)
+	tm.err = KeyEndpoint.Set(tm.err, endpoint)
 	return tm
 }
 
 // GetEndpoint retrieves endpoint from metadata
 func (tm *TypedMetadata) GetEndpoint() (string, bool) {
-	return tm.err.GetMetadata(MetaEndpoint)
+	return KeyEndpoint.Get(tm.err)
 }
 
 // WithHTTPMethod adds HTTP method context
 func (tm *TypedMetadata) WithHTTPMethod(method string) *TypedMetadata {
-	tm.err.WithMetadata(MetaMethod, method)
+	tm.err = KeyMethod.Set(tm.err, method)
 	return tm
 }
 
 // GetHTTPMethod retrieves HTTP method from metadata
 func (tm *TypedMetadata) GetHTTPMethod() (string, bool) {
-	return tm.err.GetMetadata(MetaMethod)
+	return KeyMethod.Get(tm.err)
 }
 
 // Resource identification methods
 
 // WithResource adds resource type context
 func (tm *TypedMetadata) WithResource(resource string) *TypedMetadata {
-	tm.err.WithMetadata(MetaResource, resource)
+	tm.err = KeyResource.Set(tm.err, resource)
 	return tm
 }
 
 // GetResource retrieves resource from metadata
 func (tm *TypedMetadata) GetResource() (string, bool) {
-	return tm.err.GetMetadata(MetaResource)
+	return KeyResource.Get(tm.err)
 }
 
 // WithResourceID adds resource ID context
 func (tm *TypedMetadata) WithResourceID(resourceID string) *TypedMetadata {
-	tm.err.WithMetadata(MetaResourceID, resourceID)
+	tm.err = KeyResourceID.Set(tm.err, resourceID)
 	return tm
 }
 
 // GetResourceID retrieves resource ID from metadata
 func (tm *TypedMetadata) GetResourceID() (string, bool) {
-	return tm.err.GetMetadata(MetaResourceID)
+	return KeyResourceID.Get(tm.err)
 }
 
 // WithField adds field context for validation errors
 func (tm *TypedMetadata) WithField(field string) *TypedMetadata {
-	tm.err.WithMetadata(MetaField, field)
+	tm.err = KeyField.Set(tm.err, field)
 	return tm
 }
 
 // GetField retrieves field from metadata
 func (tm *TypedMetadata) GetField() (string, bool) {
-	return tm.err.GetMetadata(MetaField)
+	return KeyField.Get(tm.err)
 }
 
 // WithEntity adds entity context
 func (tm *TypedMetadata) WithEntity(entity string) *TypedMetadata {
-	tm.err.WithMetadata(MetaEntity, entity)
+	tm.err = KeyEntity.Set(tm.err, entity)
 	return tm
 }
 
 // GetEntity retrieves entity from metadata
 func (tm *TypedMetadata) GetEntity() (string, bool) {
-	return tm.err.GetMetadata(MetaEntity)
+	return KeyEntity.Get(tm.err)
 }
 
 // Error context methods
 
 // WithErrorType adds error type classification
 func (tm *TypedMetadata) WithErrorType(errorType string) *TypedMetadata {
-	tm.err.WithMetadata(MetaErrorType, errorType)
+	tm.err = KeyErrorType.Set(tm.err, errorType)
 	return tm
 }
 
 // GetErrorType retrieves error type from metadata
 func (tm *TypedMetadata) GetErrorType() (string, bool) {
-	return tm.err.GetMetadata(MetaErrorType)
+	return KeyErrorType.Get(tm.err)
 }
 
 // WithFailurePoint adds failure point context
 func (tm *TypedMetadata) WithFailurePoint(point string) *TypedMetadata {
-	tm.err.WithMetadata(MetaFailurePoint, point)
+	tm.err = KeyFailurePoint.Set(tm.err, point)
 	return tm
 }
 
 // GetFailurePoint retrieves failure point from metadata
 func (tm *TypedMetadata) GetFailurePoint() (string, bool) {
-	return tm.err.GetMetadata(MetaFailurePoint)
+	return KeyFailurePoint.Get(tm.err)
 }
 
 // WithRetryCount adds retry count information
 func (tm *TypedMetadata) WithRetryCount(count int) *TypedMetadata {
-	tm.err.WithMetadata(MetaRetryCount, strconv.Itoa(count))
+	tm.err = KeyRetryCount.Set(tm.err, count)
 	return tm
 }
 
 // GetRetryCount retrieves retry count from metadata
 func (tm *TypedMetadata) GetRetryCount() (int, bool) {
-	if value, exists := tm.err.GetMetadata(MetaRetryCount); exists {
-		if count, err := strconv.Atoi(value); err == nil {
-			return count, true
-		}
-	}
-	return 0, false
+	return KeyRetryCount.Get(tm.err)
 }
 
 // WithAttempt adds attempt number
 func (tm *TypedMetadata) WithAttempt(attempt int) *TypedMetadata {
-	tm.err.WithMetadata(MetaAttempt, strconv.Itoa(attempt))
+	tm.err = KeyAttempt.Set(tm.err, attempt)
 	return tm
 }
 
 // GetAttempt retrieves attempt number from metadata
 func (tm *TypedMetadata) GetAttempt() (int, bool) {
-	if value, exists := tm.err.GetMetadata(MetaAttempt); exists {
-		if attempt, err := strconv.Atoi(value); err == nil {
-			return attempt, true
-		}
-	}
-	return 0, false
+	return KeyAttempt.Get(tm.err)
 }
 
 // External service context methods
 
 // WithExternalService adds external service context
 func (tm *TypedMetadata) WithExternalService(service string) *TypedMetadata {
-	tm.err.WithMetadata(MetaExternalService, service)
+	tm.err = KeyExternalService.Set(tm.err, service)
 	return tm
 }
 
 // GetExternalService retrieves external service from metadata
 func (tm *TypedMetadata) GetExternalService() (string, bool) {
-	return tm.err.GetMetadata(MetaExternalService)
+	return KeyExternalService.Get(tm.err)
 }
 
 // WithURL adds URL context
 func (tm *TypedMetadata) WithURL(url string) *TypedMetadata {
-	tm.err.WithMetadata(MetaURL, url)
+	tm.err = KeyURL.Set(tm.err, url)
 	return tm
 }
 
 // GetURL retrieves URL from metadata
 func (tm *TypedMetadata) GetURL() (string, bool) {
-	return tm.err.GetMetadata(MetaURL)
+	return KeyURL.Get(tm.err)
 }
 
 // WithStatusCode adds HTTP status code context
 func (tm *TypedMetadata) WithStatusCode(statusCode int) *TypedMetadata {
-	tm.err.WithMetadata(MetaStatusCode, strconv.Itoa(statusCode))
+	tm.err = KeyStatusCode.Set(tm.err, statusCode)
 	return tm
 }
 
 // GetStatusCode retrieves HTTP status code from metadata
 func (tm *TypedMetadata) GetStatusCode() (int, bool) {
-	if value, exists := tm.err.GetMetadata(MetaStatusCode); exists {
-		if code, err := strconv.Atoi(value); err == nil {
-			return code, true
-		}
-	}
-	return 0, false
+	return KeyStatusCode.Get(tm.err)
 }
 
 // WithResponseTime adds response time context
 func (tm *TypedMetadata) WithResponseTime(duration time.Duration) *TypedMetadata {
-	tm.err.WithMetadata(MetaResponseTime, duration.String())
+	tm.err = KeyResponseTime.Set(tm.err, duration)
 	return tm
 }
 
 // GetResponseTime retrieves response time from metadata
 func (tm *TypedMetadata) GetResponseTime() (time.Duration, bool) {
-	if value, exists := tm.err.GetMetadata(MetaResponseTime); exists {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration, true
-		}
-	}
-	return 0, false
+	return KeyResponseTime.Get(tm.err)
 }
 
 // Validation context methods
 
 // WithValidationField adds validation field context
 func (tm *TypedMetadata) WithValidationField(field string) *TypedMetadata {
-	tm.err.WithMetadata(MetaValidationField, field)
+	tm.err = KeyValidationField.Set(tm.err, field)
 	return tm
 }
 
 // GetValidationField retrieves validation field from metadata
 func (tm *TypedMetadata) GetValidationField() (string, bool) {
-	return tm.err.GetMetadata(MetaValidationField)
+	return KeyValidationField.Get(tm.err)
 }
 
 // WithValidationValue adds the invalid value for context
 func (tm *TypedMetadata) WithValidationValue(value string) *TypedMetadata {
-	tm.err.WithMetadata(MetaValidationValue, value)
+	tm.err = KeyValidationValue.Set(tm.err, value)
 	return tm
 }
 
 // GetValidationValue retrieves validation value from metadata
 func (tm *TypedMetadata) GetValidationValue() (string, bool) {
-	return tm.err.GetMetadata(MetaValidationValue)
+	return KeyValidationValue.Get(tm.err)
 }
 
 // WithValidationRule adds the violated validation rule
 func (tm *TypedMetadata) WithValidationRule(rule string) *TypedMetadata {
-	tm.err.WithMetadata(MetaValidationRule, rule)
+	tm.err = KeyValidationRule.Set(tm.err, rule)
 	return tm
 }
 
 // GetValidationRule retrieves validation rule from metadata
 func (tm *TypedMetadata) GetValidationRule() (string, bool) {
-	return tm.err.GetMetadata(MetaValidationRule)
+	return KeyValidationRule.Get(tm.err)
 }
 
 // Security context methods
 
 // WithPermission adds required permission context
 func (tm *TypedMetadata) WithPermission(permission string) *TypedMetadata {
-	tm.err.WithMetadata(MetaPermission, permission)
+	tm.err = KeyPermission.Set(tm.err, permission)
 	return tm
 }
 
 // GetPermission retrieves permission from metadata
 func (tm *TypedMetadata) GetPermission() (string, bool) {
-	return tm.err.GetMetadata(MetaPermission)
+	return KeyPermission.Get(tm.err)
 }
 
 // WithRole adds user role context
 func (tm *TypedMetadata) WithRole(role string) *TypedMetadata {
-	tm.err.WithMetadata(MetaRole, role)
+	tm.err = KeyRole.Set(tm.err, role)
 	return tm
 }
 
 // GetRole retrieves role from metadata
 func (tm *TypedMetadata) GetRole() (string, bool) {
-	return tm.err.GetMetadata(MetaRole)
+	return KeyRole.Get(tm.err)
 }
 
 // WithScope adds authorization scope context
 func (tm *TypedMetadata) WithScope(scope string) *TypedMetadata {
-	tm.err.WithMetadata(MetaScope, scope)
+	tm.err = KeyScope.Set(tm.err, scope)
 	return tm
 }
 
 // GetScope retrieves scope from metadata
 func (tm *TypedMetadata) GetScope() (string, bool) {
-	return tm.err.GetMetadata(MetaScope)
+	return KeyScope.Get(tm.err)
 }
 
 // WithIPAddress adds client IP address
 func (tm *TypedMetadata) WithIPAddress(ipAddress string) *TypedMetadata {
-	tm.err.WithMetadata(MetaIPAddress, ipAddress)
+	tm.err = KeyIPAddress.Set(tm.err, ipAddress)
 	return tm
 }
 
 // GetIPAddress retrieves IP address from metadata
 func (tm *TypedMetadata) GetIPAddress() (string, bool) {
-	return tm.err.GetMetadata(MetaIPAddress)
+	return KeyIPAddress.Get(tm.err)
 }
 
 // WithUserAgent adds user agent string
 func (tm *TypedMetadata) WithUserAgent(userAgent string) *TypedMetadata {
-	tm.err.WithMetadata(MetaUserAgent, userAgent)
+	tm.err = KeyUserAgent.Set(tm.err, userAgent)
 	return tm
 }
 
 // GetUserAgent retrieves user agent from metadata
 func (tm *TypedMetadata) GetUserAgent() (string, bool) {
-	return tm.err.GetMetadata(MetaUserAgent)
+	return KeyUserAgent.Get(tm.err)
 }
 
 // Performance context methods
 
 // WithDuration adds operation duration
 func (tm *TypedMetadata) WithDuration(duration time.Duration) *TypedMetadata {
-	tm.err.WithMetadata(MetaDuration, duration.String())
+	tm.err = KeyDuration.Set(tm.err, duration)
 	return tm
 }
 
 // GetDuration retrieves duration from metadata
 func (tm *TypedMetadata) GetDuration() (time.Duration, bool) {
-	if value, exists := tm.err.GetMetadata(MetaDuration); exists {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration, true
-		}
-	}
-	return 0, false
+	return KeyDuration.Get(tm.err)
 }
 
 // WithMemoryUsage adds memory usage context
 func (tm *TypedMetadata) WithMemoryUsage(bytes int64) *TypedMetadata {
-	tm.err.WithMetadata(MetaMemoryUsage, fmt.Sprintf("%d", bytes))
+	tm.err = KeyMemoryUsage.Set(tm.err, bytes)
 	return tm
 }
 
 // GetMemoryUsage retrieves memory usage from metadata
 func (tm *TypedMetadata) GetMemoryUsage() (int64, bool) {
-	if value, exists := tm.err.GetMetadata(MetaMemoryUsage); exists {
-		if bytes, err := strconv.ParseInt(value, 10, 64); err == nil {
-			return bytes, true
-		}
-	}
-	return 0, false
+	return KeyMemoryUsage.Get(tm.err)
 }
 
 // Business context methods
 
 // WithTenantID adds tenant/organization context for multi-tenancy
 func (tm *TypedMetadata) WithTenantID(tenantID string) *TypedMetadata {
-	tm.err.WithMetadata(MetaTenantID, tenantID)
+	tm.err = KeyTenantID.Set(tm.err, tenantID)
 	return tm
 }
 
 // GetTenantID retrieves tenant ID from metadata
 func (tm *TypedMetadata) GetTenantID() (string, bool) {
-	return tm.err.GetMetadata(MetaTenantID)
+	return KeyTenantID.Get(tm.err)
 }
 
 // WithOrganizationID adds organization context
 func (tm *TypedMetadata) WithOrganizationID(orgID string) *TypedMetadata {
-	tm.err.WithMetadata(MetaOrganizationID, orgID)
+	tm.err = KeyOrganizationID.Set(tm.err, orgID)
 	return tm
 }
 
 // GetOrganizationID retrieves organization ID from metadata
 func (tm *TypedMetadata) GetOrganizationID() (string, bool) {
-	return tm.err.GetMetadata(MetaOrganizationID)
+	return KeyOrganizationID.Get(tm.err)
 }
 
 // WithAccountID adds account context
 func (tm *TypedMetadata) WithAccountID(accountID string) *TypedMetadata {
-	tm.err.WithMetadata(MetaAccountID, accountID)
+	tm.err = KeyAccountID.Set(tm.err, accountID)
 	return tm
 }
 
 // GetAccountID retrieves account ID from metadata
 func (tm *TypedMetadata) GetAccountID() (string, bool) {
-	return tm.err.GetMetadata(MetaAccountID)
+	return KeyAccountID.Get(tm.err)
 }
 
 // WithProjectID adds project context
 func (tm *TypedMetadata) WithProjectID(projectID string) *TypedMetadata {
-	tm.err.WithMetadata(MetaProjectID, projectID)
+	tm.err = KeyProjectID.Set(tm.err, projectID)
 	return tm
 }
 
 // GetProjectID retrieves project ID from metadata
 func (tm *TypedMetadata) GetProjectID() (string, bool) {
-	return tm.err.GetMetadata(MetaProjectID)
+	return KeyProjectID.Get(tm.err)
 }
 
 // Fluent interface methods that return the original error for chaining
@@ -563,3 +561,34 @@ func NewExternalErrorWithTypedMetadata(service, operation string, wrapped error)
 	tm := err.GetTypedMetadata().WithExternalService(service).WithOperation(operation).WithErrorType("external")
 	return err, tm
 }
+
+// Typed value conversion helpers
+
+// FormatMetadataValue formats a typed metadata value as a string
+// Times use RFC 3339, durations use time.Duration.String, string slices are
+// comma-separated and other composite values are JSON encoded
+func FormatMetadataValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case []string:
+		return strings.Join(v, METADATA_LIST_SEPARATOR)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	default:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,360 @@
+// Package cuserr provides generic typed metadata keys.
+// This file contains Key[T], codecs and the built-in keys behind TypedMetadata.
+package cuserr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Metadata key errors returned by Key.Lookup
+var (
+	// ErrMetadataNotFound indicates the metadata key is not set on the error
+	ErrMetadataNotFound = errors.New(METADATA_MSG_NOT_FOUND)
+	// ErrMetadataDecode indicates a stored metadata value could not be decoded into the key type
+	ErrMetadataDecode = errors.New(METADATA_MSG_DECODE_FAILED)
+)
+
+// Codec converts metadata values between their Go type and the stored representation
+// The stored representation is what ToJSON, ToLogFields and GetAllMetadata expose,
+// so it should be a JSON-friendly value such as a string, number, slice or map.
+type Codec[T any] interface {
+	// Encode converts a value into its stored representation
+	Encode(value T) interface{}
+	// Decode converts a stored value back into the key type
+	Decode(stored interface{}) (T, error)
+}
+
+// codecFuncs adapts a pair of functions to the Codec interface
+type codecFuncs[T any] struct {
+	encode func(T) interface{}
+	decode func(interface{}) (T, error)
+}
+
+// Encode implements Codec
+func (c codecFuncs[T]) Encode(value T) interface{} {
+	return c.encode(value)
+}
+
+// Decode implements Codec
+func (c codecFuncs[T]) Decode(stored interface{}) (T, error) {
+	return c.decode(stored)
+}
+
+// NewCodec creates a codec from encode and decode functions
+func NewCodec[T any](encode func(T) interface{}, decode func(interface{}) (T, error)) Codec[T] {
+	return codecFuncs[T]{encode: encode, decode: decode}
+}
+
+// defaultCodec stores values natively and converts common representations on decode
+// Strings written through the legacy string API, numbers decoded from JSON as
+// float64 and maps decoded into structs are all accepted
+type defaultCodec[T any] struct{}
+
+// Encode implements Codec by storing the value unchanged
+func (defaultCodec[T]) Encode(value T) interface{} {
+	return value
+}
+
+// Decode implements Codec
+func (defaultCodec[T]) Decode(stored interface{}) (T, error) {
+	var zero T
+	if value, ok := stored.(T); ok {
+		return value, nil
+	}
+
+	var converted interface{}
+	var ok bool
+	switch interface{}(zero).(type) {
+	case string:
+		converted, ok = FormatMetadataValue(stored), true
+	case int:
+		var n int64
+		n, ok = convertInt64(stored)
+		converted = int(n)
+	case int64:
+		converted, ok = convertInt64(stored)
+	case float64:
+		converted, ok = convertFloat64(stored)
+	case bool:
+		converted, ok = convertBool(stored)
+	case time.Duration:
+		converted, ok = convertDuration(stored)
+	case time.Time:
+		converted, ok = convertTime(stored)
+	}
+	if ok {
+		return converted.(T), nil
+	}
+
+	// Round-trip through JSON for composite values such as decoded maps and slices
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return zero, fmt.Errorf("%w: %v", ErrMetadataDecode, err)
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return zero, fmt.Errorf("%w: %T into %T", ErrMetadataDecode, stored, zero)
+	}
+	return value, nil
+}
+
+// Key is a typed metadata key
+// Keys give domain metadata the same compile-time safety as the built-ins:
+//
+//	var KeyOrderID = cuserr.NewKey[string]("order_id")
+//	var KeyAmount = cuserr.NewKey[Money]("amount", cuserr.WithCodec(moneyCodec))
+//
+//	KeyOrderID.Set(err, "ord_123")
+//	orderID, ok := KeyOrderID.Get(err)
+type Key[T any] struct {
+	name  string
+	codec Codec[T]
+}
+
+// KeyOption configures a Key
+type KeyOption[T any] func(*Key[T])
+
+// WithCodec sets the codec used to store and read values for the key
+func WithCodec[T any](codec Codec[T]) KeyOption[T] {
+	return func(k *Key[T]) {
+		k.codec = codec
+	}
+}
+
+// NewKey creates a typed metadata key
+// Values are stored natively unless a codec is configured with WithCodec
+func NewKey[T any](name string, opts ...KeyOption[T]) Key[T] {
+	key := Key[T]{name: name, codec: defaultCodec[T]{}}
+	for _, opt := range opts {
+		opt(&key)
+	}
+	return key
+}
+
+// Name returns the metadata key name
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Set stores a value on the error and returns the error for chaining
+func (k Key[T]) Set(err *CustomError, value T) *CustomError {
+	if err == nil {
+		return nil
+	}
+	return err.WithMetadataValue(k.name, k.codec.Encode(value))
+}
+
+// Get reads the value from the first CustomError in the error chain
+// Returns false when the key is missing or the stored value cannot be decoded
+func (k Key[T]) Get(err error) (T, bool) {
+	value, decodeErr := k.Lookup(err)
+	return value, decodeErr == nil
+}
+
+// Lookup reads the value like Get but reports why it is unavailable
+// Returns ErrMetadataDecode when the stored value does not match the key type
+func (k Key[T]) Lookup(err error) (T, error) {
+	var zero T
+	var customErr *CustomError
+	if !errors.As(err, &customErr) || customErr == nil {
+		return zero, fmt.Errorf("%s: %w", k.name, ErrMetadataNotFound)
+	}
+
+	stored, exists := customErr.GetMetadataValue(k.name)
+	if !exists {
+		return zero, fmt.Errorf("%s: %w", k.name, ErrMetadataNotFound)
+	}
+	value, decodeErr := k.codec.Decode(stored)
+	if decodeErr != nil {
+		return zero, fmt.Errorf("%s: %w", k.name, decodeErr)
+	}
+	return value, nil
+}
+
+// Arg creates a template parameter for Definition.New using the key's codec
+func (k Key[T]) Arg(value T) Param {
+	return Param{Key: k.name, Value: k.codec.Encode(value)}
+}
+
+// Built-in typed keys for the common metadata fields
+var (
+	// Identity keys
+	KeyUserID    = NewKey[string](MetaUserID)
+	KeySessionID = NewKey[string](MetaSessionID)
+	KeyTraceID   = NewKey[string](MetaTraceID)
+	KeySpanID    = NewKey[string](MetaSpanID)
+
+	// Operation keys
+	KeyOperation = NewKey[string](MetaOperation)
+	KeyComponent = NewKey[string](MetaComponent)
+	KeyService   = NewKey[string](MetaService)
+	KeyEndpoint  = NewKey[string](MetaEndpoint)
+	KeyMethod    = NewKey[string](MetaMethod)
+
+	// Resource keys
+	KeyResource   = NewKey[string](MetaResource)
+	KeyResourceID = NewKey[string](MetaResourceID)
+	KeyField      = NewKey[string](MetaField)
+	KeyEntity     = NewKey[string](MetaEntity)
+
+	// Error context keys
+	KeyErrorType    = NewKey[string](MetaErrorType)
+	KeyFailurePoint = NewKey[string](MetaFailurePoint)
+	KeyRetryCount   = NewKey[int](MetaRetryCount)
+	KeyAttempt      = NewKey[int](MetaAttempt)
+
+	// External service keys
+	KeyExternalService = NewKey[string](MetaExternalService)
+	KeyURL             = NewKey[string](MetaURL)
+	KeyStatusCode      = NewKey[int](MetaStatusCode)
+	KeyResponseTime    = NewKey[time.Duration](MetaResponseTime)
+	KeyRemote          = NewKey[bool](MetaRemote)
+	KeyOriginService   = NewKey[string](MetaOriginService)
+
+	// HTTP protocol keys
+	KeyAllowedMethods      = NewKey[string](MetaAllowedMethods)
+	KeyMediaType           = NewKey[string](MetaMediaType)
+	KeySupportedMediaTypes = NewKey[string](MetaSupportedMediaTypes)
+	KeyPayloadSize         = NewKey[int64](MetaPayloadSize)
+	KeyPayloadLimit        = NewKey[int64](MetaPayloadLimit)
+	KeyPrecondition        = NewKey[string](MetaPrecondition)
+	KeyFeature             = NewKey[string](MetaFeature)
+	KeyReason              = NewKey[string](MetaReason)
+
+	// Validation keys
+	KeyValidationField = NewKey[string](MetaValidationField)
+	KeyValidationValue = NewKey[string](MetaValidationValue)
+	KeyValidationRule  = NewKey[string](MetaValidationRule)
+
+	// Security keys
+	KeyPermission = NewKey[string](MetaPermission)
+	KeyRole       = NewKey[string](MetaRole)
+	KeyScope      = NewKey[string](MetaScope)
+	KeyIPAddress  = NewKey[string](MetaIPAddress)
+	KeyUserAgent  = NewKey[string](MetaUserAgent)
+
+	// Performance keys
+	KeyDuration    = NewKey[time.Duration](MetaDuration)
+	KeyMemoryUsage = NewKey[int64](MetaMemoryUsage)
+	KeyCPUUsage    = NewKey[float64](MetaCPUUsage)
+	KeyQueueSize   = NewKey[int](MetaQueueSize)
+
+	// Business keys
+	KeyTenantID       = NewKey[string](MetaTenantID)
+	KeyOrganizationID = NewKey[string](MetaOrganizationID)
+	KeyAccountID      = NewKey[string](MetaAccountID)
+	KeyProjectID      = NewKey[string](MetaProjectID)
+)
+
+// Value conversion helpers used by the default codec
+
+// convertInt64 converts integers, integral floats, json.Number and numeric strings
+func convertInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case float64:
+		// JSON decoding produces float64 for all numbers
+		if v == float64(int64(v)) {
+			return int64(v), true
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, true
+		}
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// convertFloat64 converts numbers, json.Number and numeric strings
+func convertFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, true
+		}
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, true
+		}
+	default:
+		if n, ok := convertInt64(v); ok {
+			return float64(n), true
+		}
+	}
+	return 0, false
+}
+
+// convertBool converts booleans and boolean strings
+func convertBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, true
+		}
+	}
+	return false, false
+}
+
+// convertDuration converts durations, duration strings and nanosecond counts
+func convertDuration(value interface{}) (time.Duration, bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, true
+	case string:
+		if duration, err := time.ParseDuration(v); err == nil {
+			return duration, true
+		}
+		return 0, false
+	}
+
+	if n, ok := convertInt64(value); ok {
+		return time.Duration(n), true
+	}
+	return 0, false
+}
+
+// convertTime converts times and RFC 3339 strings
+func convertTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
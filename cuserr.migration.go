@@ -1,6 +1,7 @@
 package cuserr
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -15,8 +16,55 @@ func FromStdError(err error, message string) *CustomError {
 		return nil
 	}
 
-	// Determine category based on error content
-	errorText := strings.ToLower(err.Error())
+	// Prefer registered sentinels in the chain, then fall back to error content
+	var sentinel error
+	if spec, registered := LookupSentinel(err); registered {
+		sentinel = spec.Sentinel
+	} else if errors.Is(err, context.Canceled) {
+		sentinel = ErrClientClosed
+	} else {
+		sentinel = sentinelFromErrorText(strings.ToLower(err.Error()))
+	}
+
+	if message == "" {
+		message = err.Error()
+	}
+
+	return NewCustomError(sentinel, err, message).
+		WithMetadata("migrated_from", "stdlib").
+		WithMetadata("original_error", err.Error())
+}
+
+// FromError returns a CustomError for any error
+// A CustomError in the chain is returned as is and a non-empty ErrorCollection
+// becomes its ToCustomError. Other errors become internal errors unless they
+// wrap a registered sentinel; the message is the sentinel's own text and err
+// is only kept as the cause, so their text never reaches clients. Unlike
+// FromStdError, the category is never guessed from the error text.
+func FromError(err error) *CustomError {
+	if err == nil {
+		return nil
+	}
+
+	customErr, collection := inspectError(err)
+	if customErr != nil {
+		return customErr
+	}
+	if collection != nil {
+		if converted := collection.ToCustomError(); converted != nil {
+			return converted
+		}
+	}
+
+	sentinel := ErrInternal
+	if spec, registered := LookupSentinel(err); registered {
+		sentinel = spec.Sentinel
+	}
+	return NewCustomError(sentinel, err, sentinel.Error())
+}
+
+// sentinelFromErrorText guesses a built-in sentinel from lower-cased error text
+func sentinelFromErrorText(errorText string) error {
 	var sentinel error
 
 	switch {
@@ -34,17 +82,15 @@ func FromStdError(err error, message string) *CustomError {
 		sentinel = ErrAlreadyExists
 	case strings.Contains(errorText, "connection") || strings.Contains(errorText, "network"):
 		sentinel = ErrExternal
+	case strings.Contains(errorText, "unavailable"):
+		sentinel = ErrUnavailable
+	case strings.Contains(errorText, "not implemented"):
+		sentinel = ErrNotImplemented
 	default:
 		sentinel = ErrInternal
 	}
 
-	if message == "" {
-		message = err.Error()
-	}
-
-	return NewCustomError(sentinel, err, message).
-		WithMetadata("migrated_from", "stdlib").
-		WithMetadata("original_error", err.Error())
+	return sentinel
 }
 
 // FromStdErrorWithCategory converts a standard error with explicit category
@@ -244,7 +290,16 @@ func MigrateErrorsInMap(errorMap map[string]error) map[string]*CustomError {
 // HTTP error migration utilities
 
 // FromHTTPStatus creates a CustomError from an HTTP status code
+// Errors for 429 and 503 are always marked retryable; use WithRetryAfter to
+// attach the server's Retry-After hint (see ParseRetryAfter)
 func FromHTTPStatus(statusCode int, message string) *CustomError {
+	return statusError(statusCode, message).
+		WithMetadata("migrated_from", "http_status").
+		WithMetadata("original_status_code", fmt.Sprintf("%d", statusCode))
+}
+
+// statusError creates the error for an HTTP status without migration metadata
+func statusError(statusCode int, message string) *CustomError {
 	var sentinel error
 
 	switch statusCode {
@@ -256,17 +311,38 @@ func FromHTTPStatus(statusCode int, message string) *CustomError {
 		sentinel = ErrForbidden
 	case 404:
 		sentinel = ErrNotFound
+	case 405:
+		sentinel = ErrMethodNotAllowed
 	case 408:
 		sentinel = ErrTimeout
 	case 409:
 		sentinel = ErrAlreadyExists
+	case 410:
+		sentinel = ErrGone
+	case 412:
+		sentinel = ErrPreconditionFailed
+	case 413:
+		sentinel = ErrPayloadTooLarge
+	case 415:
+		sentinel = ErrUnsupportedMediaType
+	case 422:
+		sentinel = ErrUnprocessable
 	case 429:
 		sentinel = ErrRateLimit
+	case 499:
+		sentinel = ErrClientClosed
 	case 500:
 		sentinel = ErrInternal
-	case 502, 503, 504:
+	case 501:
+		sentinel = ErrNotImplemented
+	case 503:
+		sentinel = ErrUnavailable
+	case 502, 504:
 		sentinel = ErrExternal
 	default:
+		if category, defined := customCategoryForHTTPStatus(statusCode); defined {
+			return fromCustomCategoryStatus(category, statusCode, message)
+		}
 		if statusCode >= 400 && statusCode < 500 {
 			sentinel = ErrInvalidInput
 		} else if statusCode >= 500 {
@@ -280,9 +356,54 @@ func FromHTTPStatus(statusCode int, message string) *CustomError {
 		message = fmt.Sprintf("HTTP %d error", statusCode)
 	}
 
-	return NewCustomError(sentinel, nil, message).
-		WithMetadata("migrated_from", "http_status").
-		WithMetadata("original_status_code", fmt.Sprintf("%d", statusCode))
+	err := NewCustomError(sentinel, nil, message)
+
+	// 429 and 503 signal a transient condition regardless of how their
+	// categories are configured
+	if statusCode == HTTP_STATUS_TOO_MANY_REQUESTS || statusCode == HTTP_STATUS_SERVICE_UNAVAILABLE {
+		err = err.WithRetryable(true)
+	}
+
+	return err
+}
+
+// codeStatusError creates the error for an error code received from another service
+// A registered code restores its sentinel; otherwise the HTTP status decides
+// the category and the code is kept. Statuses outside 400-599 are treated as 500.
+func codeStatusError(code string, statusCode int, message string) *CustomError {
+	if spec, registered := LookupSentinelByCode(code); registered {
+		return NewCustomError(spec.Sentinel, nil, message)
+	}
+
+	if statusCode < HTTP_STATUS_BAD_REQUEST || statusCode > HTTP_STATUS_MAX_ERROR {
+		statusCode = HTTP_STATUS_INTERNAL_SERVER_ERROR
+	}
+	err := statusError(statusCode, message)
+	if code != "" {
+		err = err.WithCode(code)
+	}
+	return err
+}
+
+// fromCustomCategoryStatus builds an error for a status owned by a user-defined category
+// A sentinel registered for the category is used when available
+func fromCustomCategoryStatus(category ErrorCategory, statusCode int, message string) *CustomError {
+	if message == "" {
+		message = fmt.Sprintf("HTTP %d error", statusCode)
+	}
+
+	var err *CustomError
+	for _, spec := range RegisteredSentinels() {
+		if spec.Category == category {
+			err = NewCustomError(spec.Sentinel, nil, message)
+			break
+		}
+	}
+	if err == nil {
+		err = NewCustomErrorWithCategory(category, strings.ToUpper(string(category)), message)
+	}
+
+	return err
 }
 
 // Framework-specific migration helpers
@@ -0,0 +1,515 @@
+// Package cuserr provides RFC 9457 problem details support.
+// This file contains ProblemDetails, the problem type registry and the problem+json parser.
+package cuserr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidProblemType indicates a problem type registration was rejected
+var ErrInvalidProblemType = errors.New(REGISTRY_MSG_INVALID_PROBLEM_TYPE)
+
+// ProblemDetails is an RFC 9457 problem details object
+// Code, RequestID and Errors are extension members; any other extension
+// members are kept in Extensions and written at the top level
+type ProblemDetails struct {
+	// Type is a URI reference identifying the problem type
+	Type string
+	// Title is a short summary of the problem type
+	Title string
+	// Status is the HTTP status code
+	Status int
+	// Detail explains this occurrence of the problem
+	Detail string
+	// Instance is a URI reference identifying this occurrence
+	Instance string
+	// Code is the error code
+	Code string
+	// RequestID is the request ID of the failed request
+	RequestID string
+	// Errors lists the individual problems, such as invalid fields
+	Errors []ProblemFieldError
+	// Extensions holds any other extension members
+	Extensions map[string]interface{}
+}
+
+// ProblemFieldError is an entry of the errors extension member
+type ProblemFieldError struct {
+	// Pointer is an RFC 6901 JSON pointer to the offending field, if any
+	Pointer string `json:"pointer,omitempty"`
+	// Detail explains the problem
+	Detail string `json:"detail"`
+	// Code is the error code
+	Code string `json:"code,omitempty"`
+}
+
+// ProblemType describes the problem type used for an error code
+type ProblemType struct {
+	// URI identifies the problem type and should resolve to documentation
+	URI string
+	// Title is a short summary of the problem type; defaults to the HTTP status text
+	Title string
+}
+
+// problemTypeStore stores problem types with thread-safe access
+type problemTypeStore struct {
+	mu     sync.RWMutex
+	base   string
+	byCode map[string]ProblemType
+}
+
+// Package-level problem type registry instance
+var problemTypeRegistry = &problemTypeStore{byCode: make(map[string]ProblemType)}
+
+// SetProblemTypeBase sets the base URI used to derive problem types from error codes
+// With a base of "https://errors.example.com/", NOT_FOUND becomes
+// "https://errors.example.com/not-found". An empty base restores "about:blank".
+func SetProblemTypeBase(base string) error {
+	if base != "" {
+		if _, err := url.Parse(base); err != nil {
+			return fmt.Errorf("%w: base %q: %v", ErrInvalidProblemType, base, err)
+		}
+	}
+
+	problemTypeRegistry.mu.Lock()
+	defer problemTypeRegistry.mu.Unlock()
+
+	problemTypeRegistry.base = base
+	return nil
+}
+
+// RegisterProblemType sets the problem type for an error code, overriding the base URI
+func RegisterProblemType(code string, problemType ProblemType) error {
+	if code == "" {
+		return fmt.Errorf("%w: error code is required", ErrInvalidProblemType)
+	}
+	if problemType.URI == "" {
+		return fmt.Errorf("%w: %s has no type URI", ErrInvalidProblemType, code)
+	}
+	if _, err := url.Parse(problemType.URI); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidProblemType, code, err)
+	}
+
+	problemTypeRegistry.mu.Lock()
+	defer problemTypeRegistry.mu.Unlock()
+
+	problemTypeRegistry.byCode[code] = problemType
+	return nil
+}
+
+// UnregisterProblemType removes the problem type registered for an error code
+func UnregisterProblemType(code string) bool {
+	problemTypeRegistry.mu.Lock()
+	defer problemTypeRegistry.mu.Unlock()
+
+	_, exists := problemTypeRegistry.byCode[code]
+	delete(problemTypeRegistry.byCode, code)
+	return exists
+}
+
+// LookupProblemType returns the problem type for an error code
+// Registered types win, then the base URI plus the code in kebab case,
+// then "about:blank"
+func LookupProblemType(code string) ProblemType {
+	problemTypeRegistry.mu.RLock()
+	defer problemTypeRegistry.mu.RUnlock()
+
+	if problemType, exists := problemTypeRegistry.byCode[code]; exists {
+		return problemType
+	}
+	if problemTypeRegistry.base != "" && code != "" {
+		slug := strings.ReplaceAll(strings.ToLower(code), "_", "-")
+		return ProblemType{URI: problemTypeRegistry.base + slug}
+	}
+	return ProblemType{URI: PROBLEM_TYPE_DEFAULT}
+}
+
+// problemTitle returns the title of a problem type, defaulting to the HTTP status text
+func problemTitle(problemType ProblemType, status int) string {
+	if problemType.Title != "" {
+		return problemType.Title
+	}
+	return http.StatusText(status)
+}
+
+// ToProblemDetails converts the error to an RFC 9457 problem details object
+// Detail is the client-safe message and metadata is filtered as in
+// ToClientJSON, so production mode redacts internal details. Validation
+// errors with a field add an errors entry with a JSON pointer. Instance is
+// derived from the request ID; set it to the request path if preferred.
+func (e *CustomError) ToProblemDetails() *ProblemDetails {
+	status := e.ToHTTPStatus()
+	problemType := LookupProblemType(e.Code)
+
+	pd := &ProblemDetails{
+		Type:       problemType.URI,
+		Title:      problemTitle(problemType, status),
+		Status:     status,
+		Detail:     e.ClientSafeMessage(),
+		Code:       e.Code,
+		RequestID:  e.RequestID,
+		Extensions: map[string]interface{}{JSON_FIELD_CATEGORY: string(e.Category)},
+	}
+	if e.RequestID != "" {
+		pd.Instance = PROBLEM_INSTANCE_PREFIX + e.RequestID
+	}
+	if entry, ok := e.problemFieldError(); ok {
+		pd.Errors = []ProblemFieldError{entry}
+	}
+	if metadata := e.clientMetadata(); len(metadata) > 0 {
+		pd.Extensions[JSON_FIELD_METADATA] = metadata
+	}
+	e.addRetryFields(pd.Extensions)
+
+	return pd
+}
+
+// problemFieldError returns the errors entry for a validation error with a field
+func (e *CustomError) problemFieldError() (ProblemFieldError, bool) {
+	field, hasField := e.GetMetadata(MetaField)
+	if e.Category != ErrorCategoryValidation || !hasField || field == "" {
+		return ProblemFieldError{}, false
+	}
+	return ProblemFieldError{Pointer: JSONPointer(field), Detail: e.ClientSafeMessage(), Code: e.Code}, true
+}
+
+// ToProblemDetails converts the collection to an RFC 9457 problem details object
+// Every validation error and every error becomes an errors entry; status and
+// category follow the most severe member. Context is omitted in production mode.
+func (ec *ErrorCollection) ToProblemDetails() *ProblemDetails {
+	status := ec.ToHTTPStatus()
+	problemType := LookupProblemType(ERROR_CODE_MULTIPLE_ERRORS)
+	detail := ec.Error()
+	category := ec.category()
+
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	pd := &ProblemDetails{
+		Type:       problemType.URI,
+		Title:      problemTitle(problemType, status),
+		Status:     status,
+		Detail:     detail,
+		Code:       ERROR_CODE_MULTIPLE_ERRORS,
+		RequestID:  ec.RequestID,
+		Extensions: map[string]interface{}{JSON_FIELD_CATEGORY: string(category)},
+	}
+	if ec.RequestID != "" {
+		pd.Instance = PROBLEM_INSTANCE_PREFIX + ec.RequestID
+	}
+
+	for _, validationErr := range ec.ValidationErrors {
+		code := validationErr.Code
+		if code == "" {
+			code = ERROR_CODE_INVALID_INPUT
+		}
+		pd.Errors = append(pd.Errors, ProblemFieldError{
+			Pointer: JSONPointer(validationErr.Field),
+			Detail:  validationErr.Message,
+			Code:    code,
+		})
+	}
+	for _, err := range ec.Errors {
+		entry, ok := err.problemFieldError()
+		if !ok {
+			entry = ProblemFieldError{Detail: err.ClientSafeMessage(), Code: err.Code}
+		}
+		pd.Errors = append(pd.Errors, entry)
+	}
+
+	if len(ec.Context) > 0 && !GetConfig().ProductionMode {
+		context := make(map[string]string, len(ec.Context))
+		for key, value := range ec.Context {
+			context[key] = value
+		}
+		pd.Extensions[JSON_FIELD_CONTEXT] = context
+	}
+
+	return pd
+}
+
+// isProblemMember reports whether name is a member with a dedicated ProblemDetails field
+func isProblemMember(name string) bool {
+	switch name {
+	case JSON_FIELD_TYPE, JSON_FIELD_TITLE, JSON_FIELD_STATUS, JSON_FIELD_DETAIL, JSON_FIELD_INSTANCE,
+		JSON_FIELD_CODE, JSON_FIELD_REQUEST_ID, JSON_FIELD_ERRORS:
+		return true
+	default:
+		return false
+	}
+}
+
+// MarshalJSON writes the standard members first, then extensions in sorted order
+// Extensions named like a standard member are ignored
+func (pd *ProblemDetails) MarshalJSON() ([]byte, error) {
+	jw := getJSONWriter(nil)
+	defer putJSONWriter(jw)
+
+	jw.beginObject()
+	if pd.Type != "" {
+		jw.stringField(JSON_FIELD_TYPE, pd.Type)
+	}
+	if pd.Title != "" {
+		jw.stringField(JSON_FIELD_TITLE, pd.Title)
+	}
+	if pd.Status != 0 {
+		jw.key(JSON_FIELD_STATUS)
+		jw.intValue(int64(pd.Status))
+	}
+	if pd.Detail != "" {
+		jw.stringField(JSON_FIELD_DETAIL, pd.Detail)
+	}
+	if pd.Instance != "" {
+		jw.stringField(JSON_FIELD_INSTANCE, pd.Instance)
+	}
+	if pd.Code != "" {
+		jw.stringField(JSON_FIELD_CODE, pd.Code)
+	}
+	if pd.RequestID != "" {
+		jw.stringField(JSON_FIELD_REQUEST_ID, pd.RequestID)
+	}
+	if len(pd.Errors) > 0 {
+		jw.key(JSON_FIELD_ERRORS)
+		jw.beginArray()
+		for _, entry := range pd.Errors {
+			jw.element()
+			jw.beginObject()
+			if entry.Pointer != "" {
+				jw.stringField(JSON_FIELD_POINTER, entry.Pointer)
+			}
+			jw.stringField(JSON_FIELD_DETAIL, entry.Detail)
+			if entry.Code != "" {
+				jw.stringField(JSON_FIELD_CODE, entry.Code)
+			}
+			jw.endObject()
+		}
+		jw.endArray()
+	}
+
+	names := make([]string, 0, len(pd.Extensions))
+	for name := range pd.Extensions {
+		if !isProblemMember(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		jw.key(name)
+		jw.value(pd.Extensions[name])
+	}
+	jw.endObject()
+
+	return append([]byte(nil), jw.buf...), nil
+}
+
+// UnmarshalJSON decodes a problem details object
+// As RFC 9457 requires, members whose value has the wrong type are ignored.
+// Unknown members are kept in Extensions.
+func (pd *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var members map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&members); err != nil {
+		return err
+	}
+
+	*pd = ProblemDetails{}
+	for name, value := range members {
+		switch name {
+		case JSON_FIELD_TYPE:
+			pd.Type, _ = value.(string)
+		case JSON_FIELD_TITLE:
+			pd.Title, _ = value.(string)
+		case JSON_FIELD_DETAIL:
+			pd.Detail, _ = value.(string)
+		case JSON_FIELD_INSTANCE:
+			pd.Instance, _ = value.(string)
+		case JSON_FIELD_CODE:
+			pd.Code, _ = value.(string)
+		case JSON_FIELD_REQUEST_ID:
+			pd.RequestID, _ = value.(string)
+		case JSON_FIELD_STATUS:
+			if number, ok := value.(json.Number); ok {
+				if status, err := strconv.Atoi(number.String()); err == nil {
+					pd.Status = status
+				}
+			}
+		case JSON_FIELD_ERRORS:
+			entries, _ := value.([]interface{})
+			for _, entry := range entries {
+				object, ok := entry.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				fieldErr := ProblemFieldError{}
+				fieldErr.Pointer, _ = object[JSON_FIELD_POINTER].(string)
+				fieldErr.Detail, _ = object[JSON_FIELD_DETAIL].(string)
+				fieldErr.Code, _ = object[JSON_FIELD_CODE].(string)
+				pd.Errors = append(pd.Errors, fieldErr)
+			}
+		default:
+			if pd.Extensions == nil {
+				pd.Extensions = make(map[string]interface{})
+			}
+			pd.Extensions[name] = normalizeJSONValue(value)
+		}
+	}
+	return nil
+}
+
+// ParseProblemDetails decodes an application/problem+json body into a CustomError
+func ParseProblemDetails(data []byte) (*CustomError, error) {
+	var pd ProblemDetails
+	if err := json.Unmarshal(data, &pd); err != nil {
+		return nil, err
+	}
+	return pd.ToCustomError(), nil
+}
+
+// ToCustomError converts the problem details into a CustomError
+// A registered code restores its sentinel, otherwise the status decides the
+// category as in FromHTTPStatus. The category, metadata and retry extensions
+// written by ToProblemDetails are restored, other extensions become metadata,
+// a non-default type and the instance are kept as problem_type and
+// problem_instance metadata, and errors entries become validation causes.
+func (pd *ProblemDetails) ToCustomError() *CustomError {
+	message := pd.Detail
+	if message == "" {
+		message = pd.Title
+	}
+
+	err := codeStatusError(pd.Code, pd.Status, message)
+
+	if category, ok := pd.Extensions[JSON_FIELD_CATEGORY].(string); ok && category != "" {
+		if _, defined := LookupCategory(ErrorCategory(category)); defined {
+			err.Category = ErrorCategory(category)
+		}
+	}
+	if pd.RequestID != "" {
+		err = err.WithRequestID(pd.RequestID)
+	}
+	if pd.Type != "" && pd.Type != PROBLEM_TYPE_DEFAULT {
+		err = err.WithMetadata(MetaProblemType, pd.Type)
+	}
+	if pd.Instance != "" {
+		err = err.WithMetadata(MetaProblemInstance, pd.Instance)
+	}
+
+	for name, value := range pd.Extensions {
+		switch name {
+		case JSON_FIELD_CATEGORY:
+		case JSON_FIELD_METADATA:
+			if metadata, ok := value.(map[string]interface{}); ok {
+				for key, metaValue := range metadata {
+					err = err.WithMetadataValue(key, metaValue)
+				}
+			}
+		case JSON_FIELD_RETRYABLE:
+			if retryable, ok := value.(bool); ok {
+				err = err.WithRetryable(retryable)
+			}
+		case JSON_FIELD_RETRY_AFTER_SECONDS:
+			if seconds, ok := value.(int64); ok && seconds > 0 {
+				err = err.WithRetryAfter(time.Duration(seconds) * time.Second)
+			}
+		default:
+			err = err.WithMetadataValue(name, value)
+		}
+	}
+
+	causes := make([]error, 0, len(pd.Errors))
+	for _, entry := range pd.Errors {
+		cause := NewCustomError(ErrInvalidInput, nil, entry.Detail)
+		if entry.Code != "" && entry.Code != cause.Code {
+			cause = cause.WithCode(entry.Code)
+		}
+		if field := FieldFromJSONPointer(entry.Pointer); field != "" {
+			cause = cause.WithMetadata(MetaField, field)
+		}
+		causes = append(causes, cause)
+	}
+	if len(causes) > 0 {
+		err = err.WithCauses(causes...)
+	}
+
+	return err
+}
+
+// jsonPointerEscaper escapes JSON pointer reference tokens (RFC 6901)
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPointerUnescaper reverses jsonPointerEscaper
+var jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// JSONPointer converts a field path to an RFC 6901 JSON pointer
+// Dots separate object members and brackets index arrays, so
+// "items[0].name" becomes "/items/0/name"
+func JSONPointer(field string) string {
+	if field == "" {
+		return ""
+	}
+
+	var pointer strings.Builder
+	var token strings.Builder
+	flush := func() {
+		if token.Len() > 0 {
+			pointer.WriteByte('/')
+			pointer.WriteString(jsonPointerEscaper.Replace(token.String()))
+			token.Reset()
+		}
+	}
+
+	for i := 0; i < len(field); i++ {
+		switch field[i] {
+		case '.':
+			flush()
+		case '[':
+			end := strings.IndexByte(field[i:], ']')
+			if end < 0 {
+				token.WriteString(field[i:])
+				i = len(field)
+				continue
+			}
+			flush()
+			token.WriteString(field[i+1 : i+end])
+			flush()
+			i += end
+		default:
+			token.WriteByte(field[i])
+		}
+	}
+	flush()
+	return pointer.String()
+}
+
+// FieldFromJSONPointer converts an RFC 6901 JSON pointer back to a field path
+// Numeric tokens become array indexes, so "/items/0/name" becomes "items[0].name"
+func FieldFromJSONPointer(pointer string) string {
+	if pointer == "" || pointer == "/" {
+		return ""
+	}
+
+	var field strings.Builder
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = jsonPointerUnescaper.Replace(token)
+		if _, err := strconv.Atoi(token); err == nil && field.Len() > 0 {
+			field.WriteString("[" + token + "]")
+			continue
+		}
+		if field.Len() > 0 {
+			field.WriteByte('.')
+		}
+		field.WriteString(token)
+	}
+	return field.String()
+}
@@ -0,0 +1,222 @@
+// Package cuserr provides a registry for user-defined error categories.
+// This file contains the category registry that drives HTTP and client-safety mapping.
+package cuserr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrInvalidCategory indicates a category definition was rejected
+var ErrInvalidCategory = errors.New(REGISTRY_MSG_INVALID_CATEGORY)
+
+// CategorySpec describes how errors of a category are mapped and exposed
+type CategorySpec struct {
+	// HTTPStatus is the HTTP status code returned for the category (400-599)
+	HTTPStatus int
+	// ExposeMessage controls whether error messages reach clients in production mode
+	ExposeMessage bool
+	// SafeMessage replaces the error message in production when ExposeMessage is false
+	SafeMessage string
+	// LogLevel is the level used when logging errors of the category
+	//
+	// Deprecated: use Severity. LogLevel is only consulted when Severity is zero;
+	// LogLevelDebug cannot be told apart from unset and means SeverityError.
+	LogLevel LogLevel
+	// Severity is the default severity of errors in the category; it drives the
+	// log level and collection status selection (zero falls back to LogLevel,
+	// then SeverityError)
+	Severity Severity
+	// Retryable marks errors of the category as safe to retry
+	Retryable bool
+	// Description documents the category for generated error catalogs
+	Description string
+}
+
+// categoryStore stores category specs with thread-safe access
+type categoryStore struct {
+	mu       sync.RWMutex
+	specs    map[ErrorCategory]CategorySpec
+	builtins map[ErrorCategory]bool
+}
+
+// newCategoryStore creates a registry pre-populated with the built-in categories
+func newCategoryStore() *categoryStore {
+	builtins := map[ErrorCategory]CategorySpec{
+		ErrorCategoryValidation: {
+			HTTPStatus: HTTP_STATUS_BAD_REQUEST, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryNotFound: {
+			HTTPStatus: HTTP_STATUS_NOT_FOUND, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryConflict: {
+			HTTPStatus: HTTP_STATUS_CONFLICT, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryUnauthorized: {
+			HTTPStatus: HTTP_STATUS_UNAUTHORIZED, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryForbidden: {
+			HTTPStatus: HTTP_STATUS_FORBIDDEN, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryInternal: {
+			HTTPStatus: HTTP_STATUS_INTERNAL_SERVER_ERROR, SafeMessage: SAFE_MSG_INTERNAL, Severity: SeverityError,
+		},
+		ErrorCategoryTimeout: {
+			HTTPStatus: HTTP_STATUS_REQUEST_TIMEOUT, ExposeMessage: true, Severity: SeverityError, Retryable: true,
+		},
+		ErrorCategoryRateLimit: {
+			HTTPStatus: HTTP_STATUS_TOO_MANY_REQUESTS, ExposeMessage: true, Severity: SeverityWarning, Retryable: true,
+		},
+		ErrorCategoryExternal: {
+			HTTPStatus: HTTP_STATUS_BAD_GATEWAY, SafeMessage: SAFE_MSG_UNAVAILABLE, Severity: SeverityError, Retryable: true,
+		},
+		ErrorCategoryUnavailable: {
+			HTTPStatus: HTTP_STATUS_SERVICE_UNAVAILABLE, SafeMessage: SAFE_MSG_UNAVAILABLE, Severity: SeverityError, Retryable: true,
+		},
+		ErrorCategoryNotImplemented: {
+			HTTPStatus: HTTP_STATUS_NOT_IMPLEMENTED, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryGone: {
+			HTTPStatus: HTTP_STATUS_GONE, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryPreconditionFailed: {
+			HTTPStatus: HTTP_STATUS_PRECONDITION_FAILED, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryPayloadTooLarge: {
+			HTTPStatus: HTTP_STATUS_PAYLOAD_TOO_LARGE, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryUnsupportedMediaType: {
+			HTTPStatus: HTTP_STATUS_UNSUPPORTED_MEDIA_TYPE, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryUnprocessable: {
+			HTTPStatus: HTTP_STATUS_UNPROCESSABLE_ENTITY, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryMethodNotAllowed: {
+			HTTPStatus: HTTP_STATUS_METHOD_NOT_ALLOWED, ExposeMessage: true, Severity: SeverityWarning,
+		},
+		ErrorCategoryClientClosed: {
+			HTTPStatus: HTTP_STATUS_CLIENT_CLOSED_REQUEST, ExposeMessage: true, Severity: SeverityInfo,
+		},
+	}
+
+	s := &categoryStore{
+		specs:    make(map[ErrorCategory]CategorySpec, len(builtins)),
+		builtins: make(map[ErrorCategory]bool, len(builtins)),
+	}
+	for category, spec := range builtins {
+		s.specs[category] = spec
+		s.builtins[category] = true
+	}
+	return s
+}
+
+// Package-level category registry instance
+var categoryRegistry = newCategoryStore()
+
+// DefineCategory registers a category or replaces an existing definition
+// CategoryToHTTPStatus, ClientSafeMessage, severity-based logging and
+// ErrorCollection status selection all respect the definition, so teams can extend the taxonomy with
+// categories such as "payment_required" without forking the package.
+// Built-in categories may be redefined to tune their defaults.
+func DefineCategory(category ErrorCategory, spec CategorySpec) error {
+	if category == "" {
+		return fmt.Errorf("%w: category name is required", ErrInvalidCategory)
+	}
+	if spec.HTTPStatus < HTTP_STATUS_BAD_REQUEST || spec.HTTPStatus > HTTP_STATUS_MAX_ERROR {
+		return fmt.Errorf("%w: %s has HTTP status %d outside 400-599", ErrInvalidCategory, category, spec.HTTPStatus)
+	}
+
+	categoryRegistry.mu.Lock()
+	defer categoryRegistry.mu.Unlock()
+
+	categoryRegistry.specs[category] = spec
+	return nil
+}
+
+// MustDefineCategory is like DefineCategory but panics on failure
+// Intended for package-level variable initialization
+func MustDefineCategory(category ErrorCategory, spec CategorySpec) ErrorCategory {
+	if err := DefineCategory(category, spec); err != nil {
+		panic(err)
+	}
+	return category
+}
+
+// UndefineCategory removes a user-defined category
+// Built-in categories cannot be removed; returns true if a definition was removed
+func UndefineCategory(category ErrorCategory) bool {
+	categoryRegistry.mu.Lock()
+	defer categoryRegistry.mu.Unlock()
+
+	if categoryRegistry.builtins[category] {
+		return false
+	}
+	if _, exists := categoryRegistry.specs[category]; !exists {
+		return false
+	}
+	delete(categoryRegistry.specs, category)
+	return true
+}
+
+// LookupCategory returns the definition for a category
+func LookupCategory(category ErrorCategory) (CategorySpec, bool) {
+	categoryRegistry.mu.RLock()
+	defer categoryRegistry.mu.RUnlock()
+
+	spec, exists := categoryRegistry.specs[category]
+	return spec, exists
+}
+
+// RegisteredCategories returns all defined categories in sorted order
+func RegisteredCategories() []ErrorCategory {
+	categoryRegistry.mu.RLock()
+	defer categoryRegistry.mu.RUnlock()
+
+	categories := make([]ErrorCategory, 0, len(categoryRegistry.specs))
+	for category := range categoryRegistry.specs {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+	return categories
+}
+
+// IsBuiltinCategory reports whether a category ships with the package
+func IsBuiltinCategory(category ErrorCategory) bool {
+	categoryRegistry.mu.RLock()
+	defer categoryRegistry.mu.RUnlock()
+
+	return categoryRegistry.builtins[category]
+}
+
+// categorySpec returns the spec for a category, falling back to the internal
+// category so unknown categories are never exposed to clients by accident
+func categorySpec(category ErrorCategory) CategorySpec {
+	categoryRegistry.mu.RLock()
+	defer categoryRegistry.mu.RUnlock()
+
+	if spec, exists := categoryRegistry.specs[category]; exists {
+		return spec
+	}
+	return categoryRegistry.specs[ErrorCategoryInternal]
+}
+
+// customCategoryForHTTPStatus finds a user-defined category mapped to an HTTP status
+// Categories are checked in sorted order so the result is deterministic
+func customCategoryForHTTPStatus(statusCode int) (ErrorCategory, bool) {
+	for _, category := range RegisteredCategories() {
+		if IsBuiltinCategory(category) {
+			continue
+		}
+		if spec, exists := LookupCategory(category); exists && spec.HTTPStatus == statusCode {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// CategoryLogLevel returns the log level for a category's default severity
+func CategoryLogLevel(category ErrorCategory) LogLevel {
+	return CategorySeverity(category).LogLevel()
+}
@@ -0,0 +1,338 @@
+// Package cuserr provides a pluggable registry for sentinel errors.
+// This file contains the registry that maps sentinels to categories and codes.
+package cuserr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Registry errors returned by RegisterSentinel
+var (
+	// ErrDuplicateErrorCode indicates the error code is already owned by another sentinel
+	ErrDuplicateErrorCode = errors.New(REGISTRY_MSG_DUPLICATE_CODE)
+	// ErrSentinelAlreadyRegistered indicates the sentinel is already registered with a different mapping
+	ErrSentinelAlreadyRegistered = errors.New(REGISTRY_MSG_SENTINEL_REGISTERED)
+	// ErrInvalidSentinel indicates the sentinel cannot be registered
+	ErrInvalidSentinel = errors.New(REGISTRY_MSG_INVALID_SENTINEL)
+)
+
+// SentinelSpec describes how a sentinel error maps to a category and error code
+type SentinelSpec struct {
+	// Sentinel is the registered sentinel error
+	Sentinel error
+	// Category is the category assigned to errors created from the sentinel
+	Category ErrorCategory
+	// Code is the unique error code assigned to errors created from the sentinel
+	Code string
+	// Description documents the sentinel for generated error catalogs
+	Description string
+}
+
+// SentinelOption configures a sentinel registration
+type SentinelOption func(*sentinelOptions)
+
+// sentinelOptions holds optional registration settings
+type sentinelOptions struct {
+	description string
+	replace     bool
+}
+
+// WithSentinelDescription attaches a human-readable description to the registration
+func WithSentinelDescription(description string) SentinelOption {
+	return func(o *sentinelOptions) {
+		o.description = description
+	}
+}
+
+// ReplaceExisting allows a registration to take over an existing sentinel or code
+// Use sparingly - replacing built-in codes changes behavior for every caller
+func ReplaceExisting() SentinelOption {
+	return func(o *sentinelOptions) {
+		o.replace = true
+	}
+}
+
+// sentinelStore stores sentinel mappings with thread-safe access
+type sentinelStore struct {
+	mu         sync.RWMutex
+	bySentinel map[error]*SentinelSpec
+	byCode     map[string]*SentinelSpec
+	// ordered keeps registration order for errors.Is fallback matching
+	ordered []*SentinelSpec
+}
+
+// builtinSentinels lists the sentinels every registry starts with
+var builtinSentinels = []SentinelSpec{
+	{Sentinel: ErrNotFound, Category: ErrorCategoryNotFound, Code: ERROR_CODE_NOT_FOUND},
+	{Sentinel: ErrAlreadyExists, Category: ErrorCategoryConflict, Code: ERROR_CODE_ALREADY_EXISTS},
+	{Sentinel: ErrInvalidInput, Category: ErrorCategoryValidation, Code: ERROR_CODE_INVALID_INPUT},
+	{Sentinel: ErrUnauthorized, Category: ErrorCategoryUnauthorized, Code: ERROR_CODE_UNAUTHORIZED},
+	{Sentinel: ErrForbidden, Category: ErrorCategoryForbidden, Code: ERROR_CODE_FORBIDDEN},
+	{Sentinel: ErrInternal, Category: ErrorCategoryInternal, Code: ERROR_CODE_INTERNAL_ERROR},
+	{Sentinel: ErrTimeout, Category: ErrorCategoryTimeout, Code: ERROR_CODE_TIMEOUT},
+	{Sentinel: ErrRateLimit, Category: ErrorCategoryRateLimit, Code: ERROR_CODE_RATE_LIMIT},
+	{Sentinel: ErrExternal, Category: ErrorCategoryExternal, Code: ERROR_CODE_EXTERNAL_ERROR},
+	{Sentinel: ErrUnavailable, Category: ErrorCategoryUnavailable, Code: ERROR_CODE_UNAVAILABLE},
+	{Sentinel: ErrNotImplemented, Category: ErrorCategoryNotImplemented, Code: ERROR_CODE_NOT_IMPLEMENTED},
+	{Sentinel: ErrGone, Category: ErrorCategoryGone, Code: ERROR_CODE_GONE},
+	{Sentinel: ErrPreconditionFailed, Category: ErrorCategoryPreconditionFailed, Code: ERROR_CODE_PRECONDITION_FAILED},
+	{Sentinel: ErrPayloadTooLarge, Category: ErrorCategoryPayloadTooLarge, Code: ERROR_CODE_PAYLOAD_TOO_LARGE},
+	{Sentinel: ErrUnsupportedMediaType, Category: ErrorCategoryUnsupportedMediaType, Code: ERROR_CODE_UNSUPPORTED_MEDIA_TYPE},
+	{Sentinel: ErrUnprocessable, Category: ErrorCategoryUnprocessable, Code: ERROR_CODE_UNPROCESSABLE_ENTITY},
+	{Sentinel: ErrMethodNotAllowed, Category: ErrorCategoryMethodNotAllowed, Code: ERROR_CODE_METHOD_NOT_ALLOWED},
+	{Sentinel: ErrClientClosed, Category: ErrorCategoryClientClosed, Code: ERROR_CODE_CLIENT_CLOSED_REQUEST},
+	{Sentinel: ErrCircuitOpen, Category: ErrorCategoryUnavailable, Code: ERROR_CODE_CIRCUIT_OPEN},
+}
+
+// builtinCategorySentinel returns the first built-in sentinel of a category
+// Returns nil for user-defined categories
+func builtinCategorySentinel(category ErrorCategory) error {
+	for _, spec := range builtinSentinels {
+		if spec.Category == category {
+			return spec.Sentinel
+		}
+	}
+	return nil
+}
+
+// newSentinelStore creates a registry pre-populated with the built-in sentinels
+func newSentinelStore() *sentinelStore {
+	r := &sentinelStore{
+		bySentinel: make(map[error]*SentinelSpec),
+		byCode:     make(map[string]*SentinelSpec),
+	}
+
+	for i := range builtinSentinels {
+		spec := builtinSentinels[i]
+		r.store(&spec)
+	}
+
+	return r
+}
+
+// Package-level sentinel registry instance
+var sentinelRegistry = newSentinelStore()
+
+// RegisterSentinel maps a sentinel error to a category and error code
+// Constructors, FromStdError and ErrorBuilder consult the registry, so domain
+// sentinels passed to NewCustomError get their own category and code instead of
+// falling back to INTERNAL_ERROR. Registering a code that is already owned by a
+// different sentinel fails with ErrDuplicateErrorCode unless ReplaceExisting is given.
+// Sentinels must be pointers or values whose type holds no interfaces, maps,
+// slices or funcs, so that comparing them can never panic.
+func RegisterSentinel(sentinel error, category ErrorCategory, code string, opts ...SentinelOption) error {
+	if sentinel == nil || !isComparableError(sentinel) {
+		return fmt.Errorf("%w: sentinel must be a non-nil comparable error", ErrInvalidSentinel)
+	}
+	if category == "" || code == "" {
+		return fmt.Errorf("%w: category and code are required", ErrInvalidSentinel)
+	}
+
+	options := &sentinelOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	spec := &SentinelSpec{
+		Sentinel:    sentinel,
+		Category:    category,
+		Code:        code,
+		Description: options.description,
+	}
+
+	return sentinelRegistry.register(spec, options.replace)
+}
+
+// MustRegisterSentinel is like RegisterSentinel but panics on failure
+// Intended for package-level variable initialization
+func MustRegisterSentinel(sentinel error, category ErrorCategory, code string, opts ...SentinelOption) error {
+	if err := RegisterSentinel(sentinel, category, code, opts...); err != nil {
+		panic(err)
+	}
+	return sentinel
+}
+
+// UnregisterSentinel removes a sentinel from the registry
+// Returns true if the sentinel was registered
+func UnregisterSentinel(sentinel error) bool {
+	if sentinel == nil || !isComparableError(sentinel) {
+		return false
+	}
+	return sentinelRegistry.unregister(sentinel)
+}
+
+// LookupSentinel finds the registration matching an error
+// Exact matches anywhere in the error chain win, closest first; registered
+// sentinels reachable only through custom Is methods are matched afterwards
+func LookupSentinel(err error) (SentinelSpec, bool) {
+	spec := sentinelRegistry.lookup(err)
+	if spec == nil {
+		return SentinelSpec{}, false
+	}
+	return *spec, true
+}
+
+// LookupSentinelByCode finds the registration owning an error code
+func LookupSentinelByCode(code string) (SentinelSpec, bool) {
+	sentinelRegistry.mu.RLock()
+	defer sentinelRegistry.mu.RUnlock()
+
+	spec, exists := sentinelRegistry.byCode[code]
+	if !exists {
+		return SentinelSpec{}, false
+	}
+	return *spec, true
+}
+
+// RegisteredSentinels returns all registrations in registration order
+func RegisteredSentinels() []SentinelSpec {
+	sentinelRegistry.mu.RLock()
+	defer sentinelRegistry.mu.RUnlock()
+
+	specs := make([]SentinelSpec, len(sentinelRegistry.ordered))
+	for i, spec := range sentinelRegistry.ordered {
+		specs[i] = *spec
+	}
+	return specs
+}
+
+// register validates and stores a spec
+func (r *sentinelStore) register(spec *SentinelSpec, replace bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, exists := r.bySentinel[spec.Sentinel]; exists && !replace {
+		if existing.Category == spec.Category && existing.Code == spec.Code {
+			// Idempotent re-registration
+			return nil
+		}
+		return fmt.Errorf("%w: %q is registered as %s", ErrSentinelAlreadyRegistered, spec.Sentinel.Error(), existing.Code)
+	}
+
+	if owner, exists := r.byCode[spec.Code]; exists && owner.Sentinel != spec.Sentinel && !replace {
+		return fmt.Errorf("%w: %s is owned by %q", ErrDuplicateErrorCode, spec.Code, owner.Sentinel.Error())
+	}
+
+	r.remove(spec.Sentinel)
+	if owner, exists := r.byCode[spec.Code]; exists {
+		r.remove(owner.Sentinel)
+	}
+	r.store(spec)
+	return nil
+}
+
+// unregister removes a sentinel with locking
+func (r *sentinelStore) unregister(sentinel error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.remove(sentinel)
+}
+
+// store adds a spec without validation; caller must hold the lock
+func (r *sentinelStore) store(spec *SentinelSpec) {
+	r.bySentinel[spec.Sentinel] = spec
+	r.byCode[spec.Code] = spec
+	r.ordered = append(r.ordered, spec)
+}
+
+// remove deletes a spec by sentinel; caller must hold the lock
+func (r *sentinelStore) remove(sentinel error) bool {
+	spec, exists := r.bySentinel[sentinel]
+	if !exists {
+		return false
+	}
+
+	delete(r.bySentinel, sentinel)
+	if r.byCode[spec.Code] == spec {
+		delete(r.byCode, spec.Code)
+	}
+	for i, candidate := range r.ordered {
+		if candidate == spec {
+			r.ordered = append(r.ordered[:i:i], r.ordered[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// lookup resolves the spec for an error, returning nil when unregistered
+func (r *sentinelStore) lookup(err error) *SentinelSpec {
+	if err == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Closest exact match in the chain wins
+	if spec := r.lookupChain(err, 0); spec != nil {
+		return spec
+	}
+
+	// Fall back to errors.Is for sentinels matched through custom Is methods,
+	// preferring the most recently registered (usually most specific) sentinel
+	for i := len(r.ordered) - 1; i >= 0; i-- {
+		if errors.Is(err, r.ordered[i].Sentinel) {
+			return r.ordered[i]
+		}
+	}
+
+	return nil
+}
+
+// lookupChain walks the error tree depth-first looking for exact matches
+func (r *sentinelStore) lookupChain(err error, depth int) *SentinelSpec {
+	if err == nil || depth > MAX_ERROR_CHAIN_DEPTH {
+		return nil
+	}
+
+	if isComparableError(err) {
+		if spec, exists := r.bySentinel[err]; exists {
+			return spec
+		}
+	}
+
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		return r.lookupChain(x.Unwrap(), depth+1)
+	case interface{ Unwrap() []error }:
+		for _, cause := range x.Unwrap() {
+			if spec := r.lookupChain(cause, depth+1); spec != nil {
+				return spec
+			}
+		}
+	}
+
+	return nil
+}
+
+// isComparableError reports whether an error can be used as a map key
+// reflect's Comparable is not enough: == on a struct or array holding an
+// interface panics when the dynamic value is a slice, map or func, so only
+// types that cannot contain interfaces are used with the exact-match map
+func isComparableError(err error) bool {
+	return isHashableType(reflect.TypeOf(err))
+}
+
+// isHashableType reports whether == on values of t can never panic
+func isHashableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Map, reflect.Slice, reflect.Func:
+		return false
+	case reflect.Array:
+		return isHashableType(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isHashableType(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
@@ -0,0 +1,223 @@
+// Package cuserr provides a retry executor driven by error classification.
+// This file contains Retry, RetryPolicy and the injectable Clock.
+package cuserr
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Clock abstracts time so that retries and circuit breakers can be tested offline
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Sleep waits for the duration or until the context is done, returning the context error
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// SystemClock is the Clock backed by the time package
+var SystemClock Clock = systemClock{}
+
+// systemClock implements Clock with real time
+type systemClock struct{}
+
+// Now returns time.Now()
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep waits on a timer or the context, whichever finishes first
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetryPolicy configures Retry
+// Zero numeric fields fall back to the DefaultRetryPolicy values, except
+// Jitter and MaxElapsedTime where zero disables the feature
+type RetryPolicy struct {
+	// MaxAttempts limits the number of calls, including the first one
+	MaxAttempts int
+	// InitialDelay is the backoff before the first retry
+	InitialDelay time.Duration
+	// MaxDelay caps the exponential backoff; Retry-After hints may exceed it
+	MaxDelay time.Duration
+	// Multiplier grows the delay after every retry
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction in either direction (0 to 1)
+	Jitter float64
+	// MaxElapsedTime stops retrying when the next attempt would start after this budget
+	MaxElapsedTime time.Duration
+	// ShouldRetry decides whether an error is worth retrying; defaults to IsRetryable
+	ShouldRetry func(err error) bool
+	// CollectAttempts makes Retry return an ErrorCollection holding every attempt's error
+	CollectAttempts bool
+	// Clock provides time and sleeping; defaults to SystemClock
+	Clock Clock
+	// Rand returns values in [0, 1) for jitter; defaults to math/rand
+	Rand func() float64
+}
+
+// DefaultRetryPolicy returns a policy with three attempts and jittered exponential backoff
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  RETRY_DEFAULT_MAX_ATTEMPTS,
+		InitialDelay: RETRY_DEFAULT_INITIAL_DELAY_MS * time.Millisecond,
+		MaxDelay:     RETRY_DEFAULT_MAX_DELAY_MS * time.Millisecond,
+		Multiplier:   RETRY_DEFAULT_MULTIPLIER,
+		Jitter:       RETRY_DEFAULT_JITTER,
+	}
+}
+
+// Retry calls fn until it succeeds, returns a non-retryable error, or the
+// policy's attempt or elapsed-time limits are reached
+// Delays grow exponentially with jitter and are extended to honour any
+// RetryAfter hint on the error. Every returned attempt error is a CustomError
+// carrying MetaAttempt and MetaRetryCount; plain errors are converted with
+// FromStdError. If ctx ends while waiting, the context error is returned as a
+// timeout or client_closed error caused by the last attempt's error. With CollectAttempts the result is an
+// *ErrorCollection of all attempt errors instead.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+	start := policy.Clock.Now()
+
+	var collection *ErrorCollection
+	if policy.CollectAttempts {
+		collection = NewErrorCollection(RETRY_MSG_FAILED)
+	}
+
+	var last *CustomError
+	for attempt := 1; ; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return policy.interrupted(collection, ctxErr, attempt-1, last)
+		}
+
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		last = recordAttempt(asCustomError(err), attempt)
+		if collection != nil {
+			collection.Add(last)
+		}
+
+		if attempt >= policy.MaxAttempts || !policy.ShouldRetry(err) {
+			break
+		}
+
+		delay := policy.backoff(attempt)
+		if hint, ok := RetryAfter(err); ok && hint > delay {
+			delay = hint
+		}
+		if policy.MaxElapsedTime > 0 && policy.Clock.Now().Sub(start)+delay > policy.MaxElapsedTime {
+			break
+		}
+
+		if sleepErr := policy.Clock.Sleep(ctx, delay); sleepErr != nil {
+			return policy.interrupted(collection, sleepErr, attempt, last)
+		}
+	}
+
+	return policy.result(collection, last)
+}
+
+// withDefaults fills unset policy fields
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = defaults.InitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaults.MaxDelay
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaults.Multiplier
+	}
+	p.Jitter = math.Max(0, math.Min(1, p.Jitter))
+	if p.ShouldRetry == nil {
+		p.ShouldRetry = IsRetryable
+	}
+	if p.Clock == nil {
+		p.Clock = SystemClock
+	}
+	if p.Rand == nil {
+		p.Rand = rand.Float64
+	}
+	return p
+}
+
+// backoff returns the jittered delay before the given retry (1-based)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(retry-1))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*p.Rand() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// result returns the collection when attempts are collected, otherwise the final error
+func (p RetryPolicy) result(collection *ErrorCollection, final *CustomError) error {
+	if collection != nil {
+		return collection
+	}
+	return final
+}
+
+// interrupted builds the result for a context that ended after the given number of attempts
+// The failure of the last attempt, if any, becomes the cause so it is not lost
+func (p RetryPolicy) interrupted(collection *ErrorCollection, ctxErr error, attempts int, last *CustomError) error {
+	cancelled := recordAttempt(contextError(ctxErr), attempts)
+	if last != nil {
+		cancelled = cancelled.WithCauses(last)
+	}
+	if collection != nil {
+		collection.Add(cancelled)
+	}
+	return p.result(collection, cancelled)
+}
+
+// recordAttempt stores the attempt number and retry count on the error
+func recordAttempt(err *CustomError, attempt int) *CustomError {
+	err = KeyAttempt.Set(err, attempt)
+	return KeyRetryCount.Set(err, max(attempt-1, 0))
+}
+
+// asCustomError returns a clone of the error when it is a CustomError and converts it otherwise
+// Cloning keeps attempt metadata off errors that fn shares between calls or
+// goroutines. Wrapped CustomErrors are converted so the wrapping context is kept
+func asCustomError(err error) *CustomError {
+	if customErr, ok := err.(*CustomError); ok {
+		return customErr.Clone()
+	}
+	return FromStdError(err, "")
+}
+
+// contextError converts a context error into a timeout or client_closed error
+func contextError(err error) *CustomError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewCustomError(ErrTimeout, err, err.Error())
+	}
+	return NewCustomError(ErrClientClosed, err, err.Error())
+}
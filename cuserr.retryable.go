@@ -0,0 +1,156 @@
+// Package cuserr provides retryability classification for errors.
+// This file contains IsRetryable, RetryAfter and the classifier override hook.
+package cuserr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetryClassifier decides whether an error is retryable
+// Return decided=false to fall through to the default classification.
+type RetryClassifier func(err error) (retryable bool, decided bool)
+
+// Package-level retry classifier with thread safety
+var (
+	retryClassifier   RetryClassifier
+	retryClassifierMu sync.RWMutex
+)
+
+// SetRetryClassifier installs a hook consulted before the category defaults
+// Explicit WithRetryable overrides on an error still take precedence.
+// Pass nil to remove the hook.
+func SetRetryClassifier(classifier RetryClassifier) {
+	retryClassifierMu.Lock()
+	defer retryClassifierMu.Unlock()
+
+	retryClassifier = classifier
+}
+
+// currentRetryClassifier returns the installed hook in a thread-safe manner
+func currentRetryClassifier() RetryClassifier {
+	retryClassifierMu.RLock()
+	defer retryClassifierMu.RUnlock()
+
+	return retryClassifier
+}
+
+// WithRetryable explicitly marks the error as retryable or not
+func (e *CustomError) WithRetryable(retryable bool) *CustomError {
+	target := e.lockWritable()
+	defer target.mu.Unlock()
+
+	target.retryable = &retryable
+	return target
+}
+
+// WithRetryAfter attaches a hint for how long clients should wait before retrying
+// An error with a retry hint is retryable unless explicitly marked otherwise
+func (e *CustomError) WithRetryAfter(delay time.Duration) *CustomError {
+	target := e.lockWritable()
+	defer target.mu.Unlock()
+
+	target.retryAfter = delay
+	return target
+}
+
+// IsRetryable reports whether retrying the operation may succeed
+// Precedence: WithRetryable override, the SetRetryClassifier hook, a retry
+// hint, then the category default
+func (e *CustomError) IsRetryable() bool {
+	e.mu.RLock()
+	override, retryAfter := e.retryable, e.retryAfter
+	e.mu.RUnlock()
+
+	if override != nil {
+		return *override
+	}
+	if classifier := currentRetryClassifier(); classifier != nil {
+		if retryable, decided := classifier(e); decided {
+			return retryable
+		}
+	}
+	if retryAfter > 0 {
+		return true
+	}
+	return categorySpec(e.Category).Retryable
+}
+
+// RetryAfter returns the retry hint attached with WithRetryAfter
+func (e *CustomError) RetryAfter() (time.Duration, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.retryAfter, e.retryAfter > 0
+}
+
+// IsRetryable reports whether an error is retryable
+// The first CustomError in the chain decides; other errors are retryable when
+// the classifier says so or when they are deadline or timeout errors
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var customErr *CustomError
+	if errors.As(err, &customErr) {
+		return customErr.IsRetryable()
+	}
+
+	if classifier := currentRetryClassifier(); classifier != nil {
+		if retryable, decided := classifier(err); decided {
+			return retryable
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var timeoutErr interface{ Timeout() bool }
+	if errors.As(err, &timeoutErr) {
+		return timeoutErr.Timeout()
+	}
+	return false
+}
+
+// RetryAfter extracts the retry hint from the first CustomError in the chain
+func RetryAfter(err error) (time.Duration, bool) {
+	var customErr *CustomError
+	if errors.As(err, &customErr) {
+		return customErr.RetryAfter()
+	}
+	return 0, false
+}
+
+// ParseRetryAfter parses a Retry-After header value
+// Both delay-seconds and HTTP-date forms are accepted; dates in the past yield false
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
+		}
+	}
+	return 0, false
+}
+
+// retryAfterSeconds rounds a retry hint up to whole seconds for serialization
+func retryAfterSeconds(delay time.Duration) int64 {
+	return int64((delay + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,571 @@
+// Package cuserr provides RPC error support without gRPC dependencies.
+// This file contains canonical RPC codes, Twirp and Connect error bodies and google.rpc.Status JSON.
+package cuserr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidRPCCode indicates an RPC code name could not be parsed
+var ErrInvalidRPCCode = errors.New(RPC_MSG_INVALID_CODE)
+
+// RPCCode is a canonical RPC status code, numbered as in google.rpc.Code
+type RPCCode int
+
+// Canonical RPC codes
+const (
+	RPCCodeOK RPCCode = iota
+	RPCCodeCanceled
+	RPCCodeUnknown
+	RPCCodeInvalidArgument
+	RPCCodeDeadlineExceeded
+	RPCCodeNotFound
+	RPCCodeAlreadyExists
+	RPCCodePermissionDenied
+	RPCCodeResourceExhausted
+	RPCCodeFailedPrecondition
+	RPCCodeAborted
+	RPCCodeOutOfRange
+	RPCCodeUnimplemented
+	RPCCodeInternal
+	RPCCodeUnavailable
+	RPCCodeDataLoss
+	RPCCodeUnauthenticated
+)
+
+// rpcCodeNames holds the canonical name of every code
+var rpcCodeNames = [...]string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND",
+	"ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION",
+	"ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// rpcCodeHTTPStatus holds the HTTP status of every code
+var rpcCodeHTTPStatus = [...]int{
+	200, 499, 500, 400, 504, 404, 409, 403, 429, 412, 409, 400, 501, 500, 503, 500, 401,
+}
+
+// rpcCodeAliases maps Twirp-specific code names to canonical codes
+var rpcCodeAliases = map[string]RPCCode{
+	"dataloss":  RPCCodeDataLoss,
+	"malformed": RPCCodeInvalidArgument,
+	"bad_route": RPCCodeNotFound,
+}
+
+// String returns the canonical name, such as NOT_FOUND
+func (c RPCCode) String() string {
+	if c < 0 || int(c) >= len(rpcCodeNames) {
+		return "CODE(" + strconv.Itoa(int(c)) + ")"
+	}
+	return rpcCodeNames[c]
+}
+
+// ConnectName returns the lower-case name used by Connect and Twirp, such as not_found
+func (c RPCCode) ConnectName() string {
+	if c == RPCCodeCanceled {
+		return "canceled"
+	}
+	return strings.ToLower(c.String())
+}
+
+// HTTPStatus returns the HTTP status conventionally used for the code
+func (c RPCCode) HTTPStatus() int {
+	if c < 0 || int(c) >= len(rpcCodeHTTPStatus) {
+		return HTTP_STATUS_INTERNAL_SERVER_ERROR
+	}
+	return rpcCodeHTTPStatus[c]
+}
+
+// ParseRPCCode parses a canonical name (NOT_FOUND), a Connect or Twirp name
+// (not_found, canceled, dataloss) or a number
+func ParseRPCCode(name string) (RPCCode, error) {
+	if number, err := strconv.Atoi(name); err == nil {
+		if number >= 0 && number < len(rpcCodeNames) {
+			return RPCCode(number), nil
+		}
+		return RPCCodeUnknown, fmt.Errorf("%w: %q", ErrInvalidRPCCode, name)
+	}
+	if code, exists := rpcCodeAliases[name]; exists {
+		return code, nil
+	}
+	if strings.EqualFold(name, "canceled") {
+		return RPCCodeCanceled, nil
+	}
+	for i, canonical := range rpcCodeNames {
+		if strings.EqualFold(name, canonical) {
+			return RPCCode(i), nil
+		}
+	}
+	return RPCCodeUnknown, fmt.Errorf("%w: %q", ErrInvalidRPCCode, name)
+}
+
+// categoryRPCCodes maps built-in categories to RPC codes
+var categoryRPCCodes = map[ErrorCategory]RPCCode{
+	ErrorCategoryValidation:           RPCCodeInvalidArgument,
+	ErrorCategoryNotFound:             RPCCodeNotFound,
+	ErrorCategoryConflict:             RPCCodeAlreadyExists,
+	ErrorCategoryUnauthorized:         RPCCodeUnauthenticated,
+	ErrorCategoryForbidden:            RPCCodePermissionDenied,
+	ErrorCategoryInternal:             RPCCodeInternal,
+	ErrorCategoryTimeout:              RPCCodeDeadlineExceeded,
+	ErrorCategoryRateLimit:            RPCCodeResourceExhausted,
+	ErrorCategoryExternal:             RPCCodeUnavailable,
+	ErrorCategoryUnavailable:          RPCCodeUnavailable,
+	ErrorCategoryNotImplemented:       RPCCodeUnimplemented,
+	ErrorCategoryGone:                 RPCCodeNotFound,
+	ErrorCategoryPreconditionFailed:   RPCCodeFailedPrecondition,
+	ErrorCategoryPayloadTooLarge:      RPCCodeResourceExhausted,
+	ErrorCategoryUnsupportedMediaType: RPCCodeInvalidArgument,
+	ErrorCategoryUnprocessable:        RPCCodeInvalidArgument,
+	ErrorCategoryMethodNotAllowed:     RPCCodeUnimplemented,
+	ErrorCategoryClientClosed:         RPCCodeCanceled,
+}
+
+// CategoryToRPCCode maps error categories to canonical RPC codes
+// Categories registered with DefineCategory map through their HTTP status
+func CategoryToRPCCode(category ErrorCategory) RPCCode {
+	if code, exists := categoryRPCCodes[category]; exists {
+		return code
+	}
+	return RPCCodeFromHTTPStatus(CategoryToHTTPStatus(category))
+}
+
+// RPCCodeFromHTTPStatus maps an HTTP status to the closest RPC code
+func RPCCodeFromHTTPStatus(statusCode int) RPCCode {
+	switch statusCode {
+	case 400:
+		return RPCCodeInvalidArgument
+	case 401:
+		return RPCCodeUnauthenticated
+	case 403:
+		return RPCCodePermissionDenied
+	case 404, 410:
+		return RPCCodeNotFound
+	case 408, 504:
+		return RPCCodeDeadlineExceeded
+	case 409:
+		return RPCCodeAlreadyExists
+	case 412:
+		return RPCCodeFailedPrecondition
+	case 429:
+		return RPCCodeResourceExhausted
+	case 499:
+		return RPCCodeCanceled
+	case 501:
+		return RPCCodeUnimplemented
+	case 502, 503:
+		return RPCCodeUnavailable
+	}
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return RPCCodeOK
+	case statusCode >= 400 && statusCode < 500:
+		return RPCCodeFailedPrecondition
+	case statusCode >= 500:
+		return RPCCodeInternal
+	default:
+		return RPCCodeUnknown
+	}
+}
+
+// ToRPCCode maps the error category to a canonical RPC code
+func (e *CustomError) ToRPCCode() RPCCode {
+	return CategoryToRPCCode(e.Category)
+}
+
+// rpcDomain holds the ErrorInfo domain
+var rpcDomain = struct {
+	mu     sync.RWMutex
+	domain string
+}{}
+
+// SetRPCErrorDomain sets the domain reported in google.rpc.ErrorInfo details
+// Use the service name, such as "orders.example.com"
+func SetRPCErrorDomain(domain string) {
+	rpcDomain.mu.Lock()
+	defer rpcDomain.mu.Unlock()
+
+	rpcDomain.domain = domain
+}
+
+// rpcErrorDomain returns the ErrorInfo domain
+func rpcErrorDomain() string {
+	rpcDomain.mu.RLock()
+	defer rpcDomain.mu.RUnlock()
+
+	return rpcDomain.domain
+}
+
+// rpcMetadata returns the client-safe metadata as strings plus the error code and request ID
+func (e *CustomError) rpcMetadata() map[string]string {
+	metadata := make(map[string]string)
+	for key, value := range e.clientMetadata() {
+		metadata[key] = FormatMetadataValue(value)
+	}
+	metadata[JSON_FIELD_ERROR_CODE] = e.Code
+	if e.RequestID != "" {
+		metadata[JSON_FIELD_REQUEST_ID] = e.RequestID
+	}
+	return metadata
+}
+
+// TwirpError is a Twirp error body
+type TwirpError struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// ToTwirpError converts the error to a Twirp error body
+// Meta carries the client-safe metadata as strings plus error_code and request_id
+func (e *CustomError) ToTwirpError() *TwirpError {
+	return &TwirpError{
+		Code: e.ToRPCCode().ConnectName(),
+		Msg:  e.ClientSafeMessage(),
+		Meta: e.rpcMetadata(),
+	}
+}
+
+// ConnectError is a Connect error body
+// Details are not produced because Connect encodes them as binary protobuf
+type ConnectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// ToConnectError converts the error to a Connect error body
+func (e *CustomError) ToConnectError() *ConnectError {
+	return &ConnectError{
+		Code:    e.ToRPCCode().ConnectName(),
+		Message: e.ClientSafeMessage(),
+	}
+}
+
+// ToTwirpError converts the collection to a Twirp error body
+// The code follows the most severe member. Meta carries error_code
+// MULTIPLE_ERRORS, the request_id and, when there are any, the field
+// violations as a JSON list under field_violations
+func (ec *ErrorCollection) ToTwirpError() *TwirpError {
+	message := ec.Error()
+	category := ec.category()
+
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	twirpErr := &TwirpError{
+		Code: CategoryToRPCCode(category).ConnectName(),
+		Msg:  message,
+		Meta: map[string]string{JSON_FIELD_ERROR_CODE: ERROR_CODE_MULTIPLE_ERRORS},
+	}
+	if ec.RequestID != "" {
+		twirpErr.Meta[JSON_FIELD_REQUEST_ID] = ec.RequestID
+	}
+	if violations := ec.rpcFieldViolations(); len(violations) > 0 {
+		// Encoding a slice of string-only structs cannot fail
+		encoded, _ := json.Marshal(violations)
+		twirpErr.Meta[JSON_FIELD_FIELD_VIOLATIONS] = string(encoded)
+	}
+	return twirpErr
+}
+
+// ToConnectError converts the collection to a Connect error body
+// The code follows the most severe member; field violations are not
+// produced because Connect encodes details as binary protobuf
+func (ec *ErrorCollection) ToConnectError() *ConnectError {
+	return &ConnectError{
+		Code:    CategoryToRPCCode(ec.category()).ConnectName(),
+		Message: ec.Error(),
+	}
+}
+
+// rpcError creates the error for a parsed RPC error
+// The error_code metadata restores registered codes; otherwise the RPC code
+// decides the category. Field violations in meta become validation causes
+func rpcError(code RPCCode, message string, metadata map[string]string) *CustomError {
+	if message == "" {
+		message = strings.ToLower(strings.ReplaceAll(code.String(), "_", " "))
+	}
+
+	err := codeStatusError(metadata[JSON_FIELD_ERROR_CODE], code.HTTPStatus(), message)
+	for key, value := range metadata {
+		switch key {
+		case JSON_FIELD_ERROR_CODE:
+		case JSON_FIELD_REQUEST_ID:
+			err = err.WithRequestID(value)
+		case JSON_FIELD_FIELD_VIOLATIONS:
+			var violations []RPCFieldViolation
+			if json.Unmarshal([]byte(value), &violations) == nil {
+				err = err.WithCauses(fieldViolationCauses(violations)...)
+				continue
+			}
+			err = err.WithMetadata(key, value)
+		default:
+			err = err.WithMetadata(key, value)
+		}
+	}
+	return err
+}
+
+// fieldViolationCauses converts field violations into validation errors
+func fieldViolationCauses(violations []RPCFieldViolation) []error {
+	causes := make([]error, 0, len(violations))
+	for _, violation := range violations {
+		cause := NewCustomError(ErrInvalidInput, nil, violation.Description).WithMetadata(MetaField, violation.Field)
+		if violation.Reason != "" && violation.Reason != cause.Code {
+			cause = cause.WithCode(violation.Reason)
+		}
+		causes = append(causes, cause)
+	}
+	return causes
+}
+
+// ParseRPCError decodes a Twirp, Connect or google.rpc.Status error body into a CustomError
+// Twirp bodies carry msg and meta, Connect bodies carry message and
+// google.rpc.Status bodies a numeric code
+func ParseRPCError(data []byte) (*CustomError, error) {
+	var body struct {
+		Code    json.RawMessage   `json:"code"`
+		Msg     string            `json:"msg"`
+		Message string            `json:"message"`
+		Meta    map[string]string `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	if len(body.Code) > 0 && body.Code[0] != '"' {
+		return ParseRPCStatus(data)
+	}
+
+	var name string
+	if err := json.Unmarshal(body.Code, &name); err != nil {
+		return nil, fmt.Errorf("%w: missing code", ErrInvalidRPCCode)
+	}
+	code, err := ParseRPCCode(name)
+	if err != nil {
+		return nil, err
+	}
+
+	message := body.Msg
+	if message == "" {
+		message = body.Message
+	}
+	return rpcError(code, message, body.Meta), nil
+}
+
+// RPCStatus is a google.rpc.Status with the ErrorInfo, BadRequest and RetryInfo details
+// It marshals to the proto3 JSON form, with "@type" on every detail
+type RPCStatus struct {
+	Code       RPCCode
+	Message    string
+	ErrorInfo  *RPCErrorInfo
+	BadRequest *RPCBadRequest
+	RetryInfo  *RPCRetryInfo
+}
+
+// RPCErrorInfo is a google.rpc.ErrorInfo detail
+type RPCErrorInfo struct {
+	Reason   string            `json:"reason"`
+	Domain   string            `json:"domain,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// RPCBadRequest is a google.rpc.BadRequest detail
+type RPCBadRequest struct {
+	FieldViolations []RPCFieldViolation `json:"fieldViolations"`
+}
+
+// RPCFieldViolation is a field violation of a google.rpc.BadRequest detail
+type RPCFieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// RPCRetryInfo is a google.rpc.RetryInfo detail
+type RPCRetryInfo struct {
+	RetryDelay time.Duration
+}
+
+// ToRPCStatus converts the error to a google.rpc.Status
+// ErrorInfo carries the error code as reason and the client-safe metadata;
+// validation errors with a field add BadRequest and retry hints add RetryInfo
+func (e *CustomError) ToRPCStatus() *RPCStatus {
+	metadata := e.rpcMetadata()
+	delete(metadata, JSON_FIELD_ERROR_CODE)
+
+	status := &RPCStatus{
+		Code:      e.ToRPCCode(),
+		Message:   e.ClientSafeMessage(),
+		ErrorInfo: &RPCErrorInfo{Reason: e.Code, Domain: rpcErrorDomain(), Metadata: metadata},
+	}
+	if field, exists := e.GetMetadata(MetaField); exists && field != "" && e.Category == ErrorCategoryValidation {
+		status.BadRequest = &RPCBadRequest{FieldViolations: []RPCFieldViolation{{
+			Field:       field,
+			Description: e.ClientSafeMessage(),
+			Reason:      e.Code,
+		}}}
+	}
+	if delay, ok := e.RetryAfter(); ok {
+		status.RetryInfo = &RPCRetryInfo{RetryDelay: delay}
+	}
+	return status
+}
+
+// ToRPCStatus converts the collection to a google.rpc.Status
+// Validation errors and field errors become BadRequest field violations; the
+// code follows the most severe member
+func (ec *ErrorCollection) ToRPCStatus() *RPCStatus {
+	message := ec.Error()
+	category := ec.category()
+
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	status := &RPCStatus{
+		Code:      CategoryToRPCCode(category),
+		Message:   message,
+		ErrorInfo: &RPCErrorInfo{Reason: ERROR_CODE_MULTIPLE_ERRORS, Domain: rpcErrorDomain()},
+	}
+	if ec.RequestID != "" {
+		status.ErrorInfo.Metadata = map[string]string{JSON_FIELD_REQUEST_ID: ec.RequestID}
+	}
+	if violations := ec.rpcFieldViolations(); len(violations) > 0 {
+		status.BadRequest = &RPCBadRequest{FieldViolations: violations}
+	}
+	return status
+}
+
+// rpcFieldViolations lists validation errors and field errors as field violations
+// The caller must hold ec.mu
+func (ec *ErrorCollection) rpcFieldViolations() []RPCFieldViolation {
+	var violations []RPCFieldViolation
+	for _, validationErr := range ec.ValidationErrors {
+		violations = append(violations, RPCFieldViolation{
+			Field:       validationErr.Field,
+			Description: validationErr.Message,
+			Reason:      validationErr.Code,
+		})
+	}
+	for _, err := range ec.Errors {
+		if field, exists := err.GetMetadata(MetaField); exists && field != "" && err.Category == ErrorCategoryValidation {
+			violations = append(violations, RPCFieldViolation{Field: field, Description: err.ClientSafeMessage(), Reason: err.Code})
+		}
+	}
+	return violations
+}
+
+// rpcDetailJSON is a google.rpc.Status detail in proto3 JSON form
+type rpcDetailJSON struct {
+	Type            string              `json:"@type"`
+	Reason          string              `json:"reason,omitempty"`
+	Domain          string              `json:"domain,omitempty"`
+	Metadata        map[string]string   `json:"metadata,omitempty"`
+	FieldViolations []RPCFieldViolation `json:"fieldViolations,omitempty"`
+	RetryDelay      string              `json:"retryDelay,omitempty"`
+}
+
+// rpcStatusJSON is a google.rpc.Status in proto3 JSON form
+type rpcStatusJSON struct {
+	Code    RPCCode           `json:"code"`
+	Message string            `json:"message,omitempty"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// MarshalJSON encodes the status in proto3 JSON form
+func (s *RPCStatus) MarshalJSON() ([]byte, error) {
+	var details []rpcDetailJSON
+	if s.ErrorInfo != nil {
+		details = append(details, rpcDetailJSON{
+			Type:     RPC_TYPE_ERROR_INFO,
+			Reason:   s.ErrorInfo.Reason,
+			Domain:   s.ErrorInfo.Domain,
+			Metadata: s.ErrorInfo.Metadata,
+		})
+	}
+	if s.BadRequest != nil {
+		details = append(details, rpcDetailJSON{Type: RPC_TYPE_BAD_REQUEST, FieldViolations: s.BadRequest.FieldViolations})
+	}
+	if s.RetryInfo != nil {
+		seconds := strconv.FormatFloat(s.RetryInfo.RetryDelay.Seconds(), 'f', -1, 64)
+		details = append(details, rpcDetailJSON{Type: RPC_TYPE_RETRY_INFO, RetryDelay: seconds + "s"})
+	}
+
+	wire := rpcStatusJSON{Code: s.Code, Message: s.Message}
+	for _, detail := range details {
+		encoded, err := json.Marshal(detail)
+		if err != nil {
+			return nil, err
+		}
+		wire.Details = append(wire.Details, encoded)
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON decodes a status in proto3 JSON form
+// Details of other types are ignored
+func (s *RPCStatus) UnmarshalJSON(data []byte) error {
+	var wire rpcStatusJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	*s = RPCStatus{Code: wire.Code, Message: wire.Message}
+	for _, raw := range wire.Details {
+		var detail rpcDetailJSON
+		if err := json.Unmarshal(raw, &detail); err != nil {
+			return err
+		}
+
+		switch detail.Type {
+		case RPC_TYPE_ERROR_INFO:
+			s.ErrorInfo = &RPCErrorInfo{Reason: detail.Reason, Domain: detail.Domain, Metadata: detail.Metadata}
+		case RPC_TYPE_BAD_REQUEST:
+			s.BadRequest = &RPCBadRequest{FieldViolations: detail.FieldViolations}
+		case RPC_TYPE_RETRY_INFO:
+			seconds, err := strconv.ParseFloat(strings.TrimSuffix(detail.RetryDelay, "s"), 64)
+			if err != nil {
+				return fmt.Errorf("invalid retryDelay %q: %w", detail.RetryDelay, err)
+			}
+			s.RetryInfo = &RPCRetryInfo{RetryDelay: time.Duration(seconds * float64(time.Second))}
+		}
+	}
+	return nil
+}
+
+// ParseRPCStatus decodes google.rpc.Status JSON into a CustomError
+func ParseRPCStatus(data []byte) (*CustomError, error) {
+	var status RPCStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+	return status.ToCustomError(), nil
+}
+
+// ToCustomError converts the status into a CustomError
+// The ErrorInfo reason restores registered codes, BadRequest field violations
+// become validation causes and RetryInfo becomes the retry hint
+func (s *RPCStatus) ToCustomError() *CustomError {
+	metadata := map[string]string{}
+	if s.ErrorInfo != nil {
+		for key, value := range s.ErrorInfo.Metadata {
+			metadata[key] = value
+		}
+		metadata[JSON_FIELD_ERROR_CODE] = s.ErrorInfo.Reason
+	}
+	err := rpcError(s.Code, s.Message, metadata)
+
+	if s.BadRequest != nil {
+		err = err.WithCauses(fieldViolationCauses(s.BadRequest.FieldViolations)...)
+	}
+	if s.RetryInfo != nil && s.RetryInfo.RetryDelay > 0 {
+		err = err.WithRetryAfter(s.RetryInfo.RetryDelay)
+	}
+	return err
+}
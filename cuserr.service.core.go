@@ -10,8 +10,7 @@ import (
 // This is the primary constructor for creating rich errors with automatic categorization
 // Uses lazy loading for metadata but captures stack traces immediately for accuracy
 func NewCustomError(sentinel error, wrapped error, message string) *CustomError {
-	category := mapSentinelToCategory(sentinel)
-	code := generateErrorCode(sentinel)
+	category, code := resolveSentinel(sentinel)
 
 	err := &CustomError{
 		Category:  category,
@@ -55,62 +54,100 @@ func NewCustomErrorWithCategory(category ErrorCategory, code, message string) *C
 
 // WithMetadata adds metadata to the error in a thread-safe manner
 // Implements lazy loading - map is created only when first metadata is added
+// Frozen errors are left untouched and a modified copy is returned
 func (e *CustomError) WithMetadata(key, value string) *CustomError {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	return e.WithMetadataValue(key, value)
+}
+
+// WithMetadataValue adds a typed metadata value in a thread-safe manner
+// Numbers, booleans, times, durations, string slices and nested maps keep their
+// native type in ToJSON, ToLogFields and GetAllMetadata; GetMetadata returns the
+// value formatted as a string
+func (e *CustomError) WithMetadataValue(key string, value interface{}) *CustomError {
+	target := e.lockWritable()
+	defer target.mu.Unlock()
 
 	// Lazy initialize metadata map
-	if e.metadata == nil {
-		e.metadata = make(map[string]string)
+	if target.metadata == nil {
+		target.metadata = make(map[string]interface{})
 	}
-	e.metadata[key] = value
-	return e
+	target.metadata[key] = value
+	return target
 }
 
-// GetMetadata retrieves metadata value by key in a thread-safe manner
+// GetMetadata retrieves metadata value by key as a string in a thread-safe manner
+// Typed values are formatted with FormatMetadataValue
 func (e *CustomError) GetMetadata(key string) (string, bool) {
+	value, exists := e.GetMetadataValue(key)
+	if !exists {
+		return "", false
+	}
+	return FormatMetadataValue(value), true
+}
+
+// GetMetadataValue retrieves the typed metadata value by key in a thread-safe manner
+func (e *CustomError) GetMetadataValue(key string) (interface{}, bool) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	if e.metadata == nil {
-		return "", false
+		return nil, false
 	}
 	value, exists := e.metadata[key]
 	return value, exists
 }
 
-// GetAllMetadata returns a copy of all metadata in a thread-safe manner
-func (e *CustomError) GetAllMetadata() map[string]string {
+// GetAllMetadata returns a copy of all metadata with native value types in a thread-safe manner
+// The map is copied but slice and map values are shared with the error
+func (e *CustomError) GetAllMetadata() map[string]interface{} {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	if e.metadata == nil {
-		return make(map[string]string)
-	}
-
 	// Return a copy to prevent external modification
-	copyMap := make(map[string]string)
+	copyMap := make(map[string]interface{}, len(e.metadata))
 	for k, v := range e.metadata {
 		copyMap[k] = v
 	}
 	return copyMap
 }
 
+// GetAllMetadataStrings returns a copy of all metadata formatted as strings
+func (e *CustomError) GetAllMetadataStrings() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	copyMap := make(map[string]string, len(e.metadata))
+	for k, v := range e.metadata {
+		copyMap[k] = FormatMetadataValue(v)
+	}
+	return copyMap
+}
+
 // WithRequestID adds request ID for tracing
 func (e *CustomError) WithRequestID(requestID string) *CustomError {
-	e.RequestID = requestID
-	return e
+	target := e.lockWritable()
+	defer target.mu.Unlock()
+
+	target.RequestID = requestID
+	return target
 }
 
 // Error implements the error interface
+// Several causes are rendered as "message: [cause1; cause2]"
 func (e *CustomError) Error() string {
-	if e.Wrapped != nil {
-		return fmt.Sprintf("%s: %v", e.Message, e.Wrapped)
+	causes := e.Causes()
+	switch len(causes) {
+	case 0:
+		return e.Message
+	case 1:
+		return fmt.Sprintf("%s: %v", e.Message, causes[0])
+	default:
+		return fmt.Sprintf("%s: [%s]", e.Message, joinCauseMessages(causes))
 	}
-	return e.Message
 }
 
 // Unwrap implements the errors.Unwrap interface for error chain unwrapping
+// With several causes it returns their errors.Join value; use Causes for the list
 func (e *CustomError) Unwrap() error {
 	return e.Wrapped
 }
@@ -143,46 +180,104 @@ func ErrorWithContext(err error, context string) error {
 // Helper Functions for Error Inspection
 
 // IsErrorCategory checks if an error belongs to a specific category
+// For an ErrorCollection it reports whether any member has the category
 func IsErrorCategory(err error, category ErrorCategory) bool {
-	var customErr *CustomError
-	if errors.As(err, &customErr) {
+	return anyInspectedError(err, func(customErr *CustomError) bool {
 		return customErr.Category == category
-	}
-	return false
+	})
 }
 
 // IsErrorCode checks if an error has a specific code
+// For an ErrorCollection it reports whether any member has the code
 func IsErrorCode(err error, code string) bool {
-	var customErr *CustomError
-	if errors.As(err, &customErr) {
+	return anyInspectedError(err, func(customErr *CustomError) bool {
 		return customErr.Code == code
-	}
-	return false
+	})
 }
 
 // GetErrorCategory extracts the category from an error
+// For an ErrorCollection it returns the category of the most severe member
 func GetErrorCategory(err error) ErrorCategory {
-	var customErr *CustomError
-	if errors.As(err, &customErr) {
+	customErr, collection := inspectError(err)
+	switch {
+	case collection != nil && !collection.IsEmpty():
+		return collection.category()
+	case customErr != nil:
 		return customErr.Category
 	}
 	return ErrorCategoryInternal
 }
 
 // GetErrorCode extracts the error code from an error
+// For an ErrorCollection it returns MULTIPLE_ERRORS
 func GetErrorCode(err error) string {
-	var customErr *CustomError
-	if errors.As(err, &customErr) {
+	customErr, collection := inspectError(err)
+	switch {
+	case collection != nil:
+		return ERROR_CODE_MULTIPLE_ERRORS
+	case customErr != nil:
 		return customErr.Code
 	}
 	return ERROR_CODE_INTERNAL_ERROR
 }
 
 // GetErrorMetadata extracts metadata from an error
+// For an ErrorCollection the first member carrying the key wins
 func GetErrorMetadata(err error, key string) (string, bool) {
-	var customErr *CustomError
-	if errors.As(err, &customErr) {
-		return customErr.GetMetadata(key)
+	value, exists := GetErrorMetadataValue(err, key)
+	if !exists {
+		return "", false
+	}
+	return FormatMetadataValue(value), true
+}
+
+// GetErrorMetadataValue extracts a typed metadata value from an error
+// For an ErrorCollection the first member carrying the key wins
+func GetErrorMetadataValue(err error, key string) (interface{}, bool) {
+	var value interface{}
+	found := anyInspectedError(err, func(customErr *CustomError) bool {
+		var exists bool
+		value, exists = customErr.GetMetadataValue(key)
+		return exists
+	})
+	return value, found
+}
+
+// inspectError finds the first CustomError or ErrorCollection in the error chain
+// The chain is walked depth-first in the same order errors.As uses
+func inspectError(err error) (*CustomError, *ErrorCollection) {
+	for depth := 0; err != nil && depth < MAX_ERROR_CHAIN_DEPTH; depth++ {
+		switch typed := err.(type) {
+		case *CustomError:
+			return typed, nil
+		case *ErrorCollection:
+			return nil, typed
+		case interface{ Unwrap() error }:
+			err = typed.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, member := range typed.Unwrap() {
+				if customErr, collection := inspectError(member); customErr != nil || collection != nil {
+					return customErr, collection
+				}
+			}
+			return nil, nil
+		default:
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// anyInspectedError applies match to the inspected CustomError, or to every member of an inspected collection
+func anyInspectedError(err error, match func(*CustomError) bool) bool {
+	customErr, collection := inspectError(err)
+	if collection != nil {
+		for _, member := range collection.members() {
+			if match(member) {
+				return true
+			}
+		}
+		return false
 	}
-	return "", false
+	return customErr != nil && match(customErr)
 }
@@ -0,0 +1,186 @@
+// Package cuserr provides severity levels for errors.
+// This file contains the Severity type and its mapping to logging levels.
+package cuserr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// ErrInvalidSeverity indicates a severity name could not be parsed
+var ErrInvalidSeverity = errors.New(SEVERITY_MSG_INVALID)
+
+// Severity describes how bad an error is, independent of its category
+// The zero value means unset; errors without an explicit severity use the
+// default configured for their category.
+type Severity int
+
+const (
+	// SeverityDebug indicates diagnostic noise that needs no attention
+	SeverityDebug Severity = iota + 1
+	// SeverityInfo indicates an expected condition such as a client cancelling
+	SeverityInfo
+	// SeverityNotice indicates a normal but noteworthy condition
+	SeverityNotice
+	// SeverityWarning indicates a client mistake or a degraded but working path
+	SeverityWarning
+	// SeverityError indicates a failed operation that needs investigation
+	SeverityError
+	// SeverityCritical indicates a failure that should page someone
+	SeverityCritical
+	// SeverityFatal indicates the process cannot continue safely
+	SeverityFatal
+)
+
+// Custom slog levels for severities without a standard slog equivalent
+const (
+	// SlogLevelNotice sits between info and warn
+	SlogLevelNotice = slog.LevelInfo + 2
+	// SlogLevelCritical sits above error
+	SlogLevelCritical = slog.LevelError + 4
+	// SlogLevelFatal sits above critical
+	SlogLevelFatal = slog.LevelError + 8
+)
+
+// String returns the lowercase name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return SEVERITY_DEBUG
+	case SeverityInfo:
+		return SEVERITY_INFO
+	case SeverityNotice:
+		return SEVERITY_NOTICE
+	case SeverityWarning:
+		return SEVERITY_WARNING
+	case SeverityError:
+		return SEVERITY_ERROR
+	case SeverityCritical:
+		return SEVERITY_CRITICAL
+	case SeverityFatal:
+		return SEVERITY_FATAL
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSeverity converts a severity name back into a Severity
+func ParseSeverity(name string) (Severity, error) {
+	for s := SeverityDebug; s <= SeverityFatal; s++ {
+		if strings.EqualFold(name, s.String()) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidSeverity, name)
+}
+
+// LogLevel maps the severity onto the four StructuredLogger levels
+func (s Severity) LogLevel() LogLevel {
+	switch {
+	case s <= SeverityDebug:
+		return LogLevelDebug
+	case s <= SeverityNotice:
+		return LogLevelInfo
+	case s == SeverityWarning:
+		return LogLevelWarn
+	default:
+		return LogLevelError
+	}
+}
+
+// SlogLevel maps the severity onto slog levels, using custom levels for
+// notice, critical and fatal
+func (s Severity) SlogLevel() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityNotice:
+		return SlogLevelNotice
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return SlogLevelCritical
+	case SeverityFatal:
+		return SlogLevelFatal
+	default:
+		return slog.LevelError
+	}
+}
+
+// severityForLogLevel maps a StructuredLogger level onto the matching severity
+func severityForLogLevel(level LogLevel) Severity {
+	switch level {
+	case LogLevelDebug:
+		return SeverityDebug
+	case LogLevelInfo:
+		return SeverityInfo
+	case LogLevelWarn:
+		return SeverityWarning
+	default:
+		return SeverityError
+	}
+}
+
+// WithSeverity overrides the category default severity for this error
+func (e *CustomError) WithSeverity(severity Severity) *CustomError {
+	target := e.lockWritable()
+	defer target.mu.Unlock()
+
+	target.severity = severity
+	return target
+}
+
+// Severity returns the error's severity, falling back to its category default
+func (e *CustomError) Severity() Severity {
+	e.mu.RLock()
+	severity := e.severity
+	e.mu.RUnlock()
+
+	if severity != 0 {
+		return severity
+	}
+	return CategorySeverity(e.Category)
+}
+
+// CategorySeverity returns the default severity configured for a category
+// Categories defined with only the deprecated LogLevel map it to a severity
+func CategorySeverity(category ErrorCategory) Severity {
+	spec := categorySpec(category)
+	switch {
+	case spec.Severity != 0:
+		return spec.Severity
+	case spec.LogLevel == LogLevelInfo:
+		return SeverityInfo
+	case spec.LogLevel == LogLevelWarn:
+		return SeverityWarning
+	default:
+		return SeverityError
+	}
+}
+
+// GetErrorSeverity extracts the severity from an error
+// Collections report their most severe member; errors that are not
+// CustomErrors are treated as SeverityError
+func GetErrorSeverity(err error) Severity {
+	customErr, collection := inspectError(err)
+	switch {
+	case collection != nil:
+		return collection.Severity()
+	case customErr != nil:
+		return customErr.Severity()
+	}
+	return SeverityError
+}
+
+// SeverityLogger is implemented by loggers that can log at every severity
+// LoggingErrorHandler prefers it over StructuredLogger.Log so that notice,
+// critical and fatal errors keep their level instead of being folded into
+// the four LogLevel values
+type SeverityLogger interface {
+	LogSeverity(ctx context.Context, severity Severity, message string, fields map[string]interface{})
+}
@@ -27,6 +27,24 @@ const (
 	ErrorCategoryRateLimit ErrorCategory = CATEGORY_RATE_LIMIT
 	// ErrorCategoryExternal indicates external service failures (502)
 	ErrorCategoryExternal ErrorCategory = CATEGORY_EXTERNAL
+	// ErrorCategoryUnavailable indicates a temporarily unavailable service (503)
+	ErrorCategoryUnavailable ErrorCategory = CATEGORY_UNAVAILABLE
+	// ErrorCategoryNotImplemented indicates unimplemented functionality (501)
+	ErrorCategoryNotImplemented ErrorCategory = CATEGORY_NOT_IMPLEMENTED
+	// ErrorCategoryGone indicates a permanently removed resource (410)
+	ErrorCategoryGone ErrorCategory = CATEGORY_GONE
+	// ErrorCategoryPreconditionFailed indicates a failed request precondition (412)
+	ErrorCategoryPreconditionFailed ErrorCategory = CATEGORY_PRECONDITION_FAILED
+	// ErrorCategoryPayloadTooLarge indicates an oversized request payload (413)
+	ErrorCategoryPayloadTooLarge ErrorCategory = CATEGORY_PAYLOAD_TOO_LARGE
+	// ErrorCategoryUnsupportedMediaType indicates an unsupported content type (415)
+	ErrorCategoryUnsupportedMediaType ErrorCategory = CATEGORY_UNSUPPORTED_MEDIA_TYPE
+	// ErrorCategoryUnprocessable indicates a well-formed but semantically invalid request (422)
+	ErrorCategoryUnprocessable ErrorCategory = CATEGORY_UNPROCESSABLE
+	// ErrorCategoryMethodNotAllowed indicates an unsupported HTTP method (405)
+	ErrorCategoryMethodNotAllowed ErrorCategory = CATEGORY_METHOD_NOT_ALLOWED
+	// ErrorCategoryClientClosed indicates the client abandoned the request (499)
+	ErrorCategoryClientClosed ErrorCategory = CATEGORY_CLIENT_CLOSED
 )
 
 // StackFrame represents a single frame in the stack trace
@@ -45,8 +63,8 @@ type CustomError struct {
 	Code string `json:"code"`
 	// Message is a human-readable error message
 	Message string `json:"message"`
-	// Metadata contains additional context
-	metadata map[string]string
+	// Metadata contains additional context with native value types
+	metadata map[string]interface{}
 	// RequestID for tracing
 	RequestID string `json:"request_id,omitempty"`
 	// Timestamp when error occurred
@@ -59,6 +77,14 @@ type CustomError struct {
 	Wrapped error `json:"-"`
 	// Sentinel is the base error for categorization
 	Sentinel error `json:"-"`
+	// severity overrides the category default severity when non-zero
+	severity Severity
+	// retryable overrides the category default retryability when set
+	retryable *bool
+	// retryAfter hints how long clients should wait before retrying
+	retryAfter time.Duration
+	// frozen marks the error immutable; With* methods return modified copies
+	frozen bool
 	// mu protects metadata and stackTrace access for thread safety
 	mu sync.RWMutex
 }
@@ -1,7 +1,6 @@
 package cuserr
 
 import (
-	"fmt"
 	"time"
 )
 
@@ -11,29 +10,10 @@ func (e *CustomError) ToHTTPStatus() int {
 }
 
 // CategoryToHTTPStatus maps error categories to HTTP status codes
+// Categories registered with DefineCategory use their configured status;
+// unknown categories map to 500
 func CategoryToHTTPStatus(category ErrorCategory) int {
-	switch category {
-	case ErrorCategoryValidation:
-		return HTTP_STATUS_BAD_REQUEST
-	case ErrorCategoryUnauthorized:
-		return HTTP_STATUS_UNAUTHORIZED
-	case ErrorCategoryForbidden:
-		return HTTP_STATUS_FORBIDDEN
-	case ErrorCategoryNotFound:
-		return HTTP_STATUS_NOT_FOUND
-	case ErrorCategoryTimeout:
-		return HTTP_STATUS_REQUEST_TIMEOUT
-	case ErrorCategoryConflict:
-		return HTTP_STATUS_CONFLICT
-	case ErrorCategoryRateLimit:
-		return HTTP_STATUS_TOO_MANY_REQUESTS
-	case ErrorCategoryExternal:
-		return HTTP_STATUS_BAD_GATEWAY
-	case ErrorCategoryInternal:
-		fallthrough
-	default:
-		return HTTP_STATUS_INTERNAL_SERVER_ERROR
-	}
+	return categorySpec(category).HTTPStatus
 }
 
 // ToJSON converts error to JSON response format
@@ -56,72 +36,70 @@ func (e *CustomError) ToJSON() map[string]interface{} {
 		errorData[JSON_FIELD_REQUEST_ID] = e.RequestID
 	}
 
+	if causes := e.Causes(); len(causes) > 0 {
+		errorData[JSON_FIELD_CAUSES] = causesJSON(causes, 1)
+	}
+
+	e.addRetryFields(errorData)
+
 	return map[string]interface{}{
 		JSON_FIELD_ERROR: errorData,
 	}
 }
 
-// ToJSONString converts error to JSON string format
-func (e *CustomError) ToJSONString() string {
-	jsonData := e.ToJSON()
+// ClientSafeMessage returns a safe message for client consumption
+// In production mode, categories defined without ExposeMessage return their
+// configured safe message instead of the error details
+func (e *CustomError) ClientSafeMessage() string {
+	if !GetConfig().ProductionMode {
+		return e.Message
+	}
 
-	// Simple JSON serialization without external dependencies
-	errorData := jsonData[JSON_FIELD_ERROR].(map[string]interface{})
+	spec := categorySpec(e.Category)
+	if spec.ExposeMessage {
+		return e.Message
+	}
+	if spec.SafeMessage != "" {
+		return spec.SafeMessage
+	}
+	return SAFE_MSG_DEFAULT
+}
 
-	result := fmt.Sprintf(`{"%s":{`, JSON_FIELD_ERROR)
-	result += fmt.Sprintf(`"%s":"%s",`, JSON_FIELD_CODE, errorData[JSON_FIELD_CODE])
-	result += fmt.Sprintf(`"%s":"%s",`, JSON_FIELD_MESSAGE, errorData[JSON_FIELD_MESSAGE])
-	result += fmt.Sprintf(`"%s":"%s",`, JSON_FIELD_CATEGORY, errorData[JSON_FIELD_CATEGORY])
-	result += fmt.Sprintf(`"%s":"%s"`, JSON_FIELD_TIMESTAMP, errorData[JSON_FIELD_TIMESTAMP])
+// ToClientJSON converts error to client-safe JSON format
+func (e *CustomError) ToClientJSON() map[string]interface{} {
+	metadata := e.clientMetadata()
 
-	if errorData[JSON_FIELD_REQUEST_ID] != nil {
-		result += fmt.Sprintf(`,"%s":"%s"`, JSON_FIELD_REQUEST_ID, errorData[JSON_FIELD_REQUEST_ID])
+	errorData := map[string]interface{}{
+		JSON_FIELD_CODE:      e.Code,
+		JSON_FIELD_MESSAGE:   e.ClientSafeMessage(),
+		JSON_FIELD_CATEGORY:  e.Category,
+		JSON_FIELD_TIMESTAMP: e.Timestamp.Format(time.RFC3339),
 	}
 
-	if errorData[JSON_FIELD_METADATA] != nil {
-		metadata := errorData[JSON_FIELD_METADATA].(map[string]string)
-		if len(metadata) > 0 {
-			result += fmt.Sprintf(`,"%s":{`, JSON_FIELD_METADATA)
-			first := true
-			for k, v := range metadata {
-				if !first {
-					result += ","
-				}
-				result += fmt.Sprintf(`"%s":"%s"`, k, v)
-				first = false
-			}
-			result += "}"
-		}
+	// Only include non-empty fields
+	if len(metadata) > 0 {
+		errorData[JSON_FIELD_METADATA] = metadata
 	}
 
-	result += "}}"
-	return result
-}
+	if e.RequestID != "" {
+		errorData[JSON_FIELD_REQUEST_ID] = e.RequestID
+	}
 
-// ClientSafeMessage returns a safe message for client consumption
-// In production mode, it may hide sensitive error details
-func (e *CustomError) ClientSafeMessage() string {
-	if globalConfig.ProductionMode {
-		// In production, return generic messages for internal errors
-		switch e.Category {
-		case ErrorCategoryInternal:
-			return "An internal error occurred"
-		case ErrorCategoryExternal:
-			return "A service is temporarily unavailable"
-		default:
-			return e.Message
-		}
+	e.addRetryFields(errorData)
+
+	return map[string]interface{}{
+		JSON_FIELD_ERROR: errorData,
 	}
-	return e.Message
 }
 
-// ToClientJSON converts error to client-safe JSON format
-func (e *CustomError) ToClientJSON() map[string]interface{} {
+// clientMetadata returns the metadata safe to show clients
+// In production mode only safe identifiers are kept
+func (e *CustomError) clientMetadata() map[string]interface{} {
 	metadata := e.GetAllMetadata() // Thread-safe metadata access
 
 	// Filter sensitive metadata in production
-	if globalConfig.ProductionMode {
-		filteredMetadata := make(map[string]string)
+	if GetConfig().ProductionMode {
+		filteredMetadata := make(map[string]interface{})
 		for k, v := range metadata {
 			// Only include non-sensitive metadata keys
 			switch k {
@@ -136,23 +114,13 @@ func (e *CustomError) ToClientJSON() map[string]interface{} {
 		metadata = filteredMetadata
 	}
 
-	errorData := map[string]interface{}{
-		JSON_FIELD_CODE:      e.Code,
-		JSON_FIELD_MESSAGE:   e.ClientSafeMessage(),
-		JSON_FIELD_CATEGORY:  e.Category,
-		JSON_FIELD_TIMESTAMP: e.Timestamp.Format(time.RFC3339),
-	}
-
-	// Only include non-empty fields
-	if len(metadata) > 0 {
-		errorData[JSON_FIELD_METADATA] = metadata
-	}
-
-	if e.RequestID != "" {
-		errorData[JSON_FIELD_REQUEST_ID] = e.RequestID
-	}
+	return metadata
+}
 
-	return map[string]interface{}{
-		JSON_FIELD_ERROR: errorData,
+// addRetryFields adds the retryable flag and any retry hint to a JSON error object
+func (e *CustomError) addRetryFields(errorData map[string]interface{}) {
+	errorData[JSON_FIELD_RETRYABLE] = e.IsRetryable()
+	if delay, ok := e.RetryAfter(); ok {
+		errorData[JSON_FIELD_RETRY_AFTER_SECONDS] = retryAfterSeconds(delay)
 	}
 }
@@ -65,13 +65,19 @@ func (e *CustomError) DetailedError() string {
 	if len(metadata) > 0 {
 		sb.WriteString("Metadata:\n")
 		for k, v := range metadata {
-			sb.WriteString(fmt.Sprintf("  %s: %s\n", k, v))
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", k, FormatMetadataValue(v)))
 		}
 	}
 
-	if e.Wrapped != nil {
-		sb.WriteString(fmt.Sprintf(LOG_TEMPLATE_WRAPPED_ERROR, e.Wrapped))
+	if causes := e.Causes(); len(causes) == 1 {
+		sb.WriteString(fmt.Sprintf(LOG_TEMPLATE_WRAPPED_ERROR, causes[0]))
 		sb.WriteString("\n")
+	} else if len(causes) > 1 {
+		sb.WriteString("Causes:\n")
+		for i, cause := range causes {
+			sb.WriteString(fmt.Sprintf(LOG_TEMPLATE_CAUSE, i+1, cause))
+			sb.WriteString("\n")
+		}
 	}
 
 	// Add stack trace if available
@@ -133,15 +139,15 @@ func (e *CustomError) GetStackTraceString() string {
 
 // FilterStackTrace removes frames that match the given patterns in a thread-safe manner
 func (e *CustomError) FilterStackTrace(patterns ...string) *CustomError {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	target := e.lockWritable()
+	defer target.mu.Unlock()
 
-	if len(e.stackTrace) == 0 || len(patterns) == 0 {
-		return e
+	if len(target.stackTrace) == 0 || len(patterns) == 0 {
+		return target
 	}
 
 	var filtered []StackFrame
-	for _, frame := range e.stackTrace {
+	for _, frame := range target.stackTrace {
 		shouldFilter := false
 		for _, pattern := range patterns {
 			if strings.Contains(frame.Function, pattern) {
@@ -154,28 +160,28 @@ func (e *CustomError) FilterStackTrace(patterns ...string) *CustomError {
 		}
 	}
 
-	e.stackTrace = filtered
-	return e
+	target.stackTrace = filtered
+	return target
 }
 
 // WithStackTrace manually sets the stack trace (useful for testing) in a thread-safe manner
 func (e *CustomError) WithStackTrace(frames []StackFrame) *CustomError {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	target := e.lockWritable()
+	defer target.mu.Unlock()
 
 	// Make a copy to prevent external modification
-	e.stackTrace = make([]StackFrame, len(frames))
-	copy(e.stackTrace, frames)
-	e.stackTraceCleared = false // Reset cleared flag when manually setting
-	return e
+	target.stackTrace = make([]StackFrame, len(frames))
+	copy(target.stackTrace, frames)
+	target.stackTraceCleared = false // Reset cleared flag when manually setting
+	return target
 }
 
 // ClearStackTrace removes the stack trace to save memory in a thread-safe manner
 func (e *CustomError) ClearStackTrace() *CustomError {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	target := e.lockWritable()
+	defer target.mu.Unlock()
 
-	e.stackTrace = nil
-	e.stackTraceCleared = true // Mark as explicitly cleared
-	return e
+	target.stackTrace = nil
+	target.stackTraceCleared = true // Mark as explicitly cleared
+	return target
 }
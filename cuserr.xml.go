@@ -0,0 +1,303 @@
+// Package cuserr provides XML and plain-text error rendering.
+// This file contains the XML schema, WriteXML and the text/plain renderer of CustomError and ErrorCollection.
+package cuserr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// XMLOption configures WriteXML
+type XMLOption func(*xmlOptions)
+
+// xmlOptions holds the WriteXML settings
+type xmlOptions struct {
+	namespace string
+	indent    string
+	header    bool
+}
+
+// WithXMLNamespace sets the default namespace (xmlns) of the root element
+func WithXMLNamespace(namespace string) XMLOption {
+	return func(o *xmlOptions) {
+		o.namespace = namespace
+	}
+}
+
+// WithXMLIndent pretty-prints the output, indenting nested elements with indent
+func WithXMLIndent(indent string) XMLOption {
+	return func(o *xmlOptions) {
+		o.indent = indent
+	}
+}
+
+// WithXMLHeader writes the <?xml version="1.0" encoding="UTF-8"?> declaration first
+func WithXMLHeader() XMLOption {
+	return func(o *xmlOptions) {
+		o.header = true
+	}
+}
+
+// xmlError is the <error> element
+type xmlError struct {
+	XMLName           xml.Name    `xml:"error"`
+	Namespace         string      `xml:"xmlns,attr,omitempty"`
+	Code              string      `xml:"code,attr"`
+	Category          string      `xml:"category,attr"`
+	Message           string      `xml:"message"`
+	RequestID         string      `xml:"request_id,omitempty"`
+	Timestamp         string      `xml:"timestamp,omitempty"`
+	Retryable         bool        `xml:"retryable"`
+	RetryAfterSeconds *int64      `xml:"retry_after_seconds,omitempty"`
+	Metadata          *xmlEntries `xml:"metadata,omitempty"`
+}
+
+// xmlCollection is the <errors> element
+type xmlCollection struct {
+	XMLName          xml.Name             `xml:"errors"`
+	Namespace        string               `xml:"xmlns,attr,omitempty"`
+	Code             string               `xml:"code,attr"`
+	Category         string               `xml:"category,attr"`
+	Count            int                  `xml:"count,attr,omitempty"`
+	Message          string               `xml:"message"`
+	Summary          string               `xml:"summary,omitempty"`
+	RequestID        string               `xml:"request_id,omitempty"`
+	ValidationErrors []xmlValidationError `xml:"validation_error"`
+	Errors           []xmlError           `xml:"error"`
+	Context          *xmlEntries          `xml:"context,omitempty"`
+}
+
+// xmlValidationError is a <validation_error> element
+// Fields are in ValidationError order so values convert directly
+type xmlValidationError struct {
+	Field   string `xml:"field,attr"`
+	Message string `xml:"message"`
+	Code    string `xml:"code,attr,omitempty"`
+	Value   string `xml:"value,omitempty"`
+}
+
+// xmlEntries holds key/value pairs as <entry key="...">value</entry> elements
+type xmlEntries struct {
+	Entries []xmlEntry `xml:"entry"`
+}
+
+// xmlEntry is an <entry> element
+type xmlEntry struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// newXMLEntries converts a map to entries sorted by key, or nil when empty
+func newXMLEntries[V any](values map[string]V) *xmlEntries {
+	if len(values) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := &xmlEntries{Entries: make([]xmlEntry, 0, len(values))}
+	for _, key := range keys {
+		entries.Entries = append(entries.Entries, xmlEntry{Key: key, Value: FormatMetadataValue(values[key])})
+	}
+	return entries
+}
+
+// xmlError converts the error to its client-safe XML element
+func (e *CustomError) xmlError() xmlError {
+	element := xmlError{
+		Code:      e.Code,
+		Category:  string(e.Category),
+		Message:   e.ClientSafeMessage(),
+		RequestID: e.RequestID,
+		Timestamp: e.Timestamp.Format(time.RFC3339),
+		Retryable: e.IsRetryable(),
+		Metadata:  newXMLEntries(e.clientMetadata()),
+	}
+	if delay, ok := e.RetryAfter(); ok {
+		seconds := retryAfterSeconds(delay)
+		element.RetryAfterSeconds = &seconds
+	}
+	return element
+}
+
+// xmlCollection converts the collection to its client-safe XML element
+// Production mode keeps the same fields as ToClientJSON
+func (ec *ErrorCollection) xmlCollection() xmlCollection {
+	message := ec.Error()
+	category := ec.category()
+	productionMode := GetConfig().ProductionMode
+
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	element := xmlCollection{
+		Code:      ERROR_CODE_MULTIPLE_ERRORS,
+		Category:  string(category),
+		Message:   message,
+		RequestID: ec.RequestID,
+	}
+	for _, validationErr := range ec.ValidationErrors {
+		element.ValidationErrors = append(element.ValidationErrors, xmlValidationError(validationErr))
+	}
+	if productionMode {
+		return element
+	}
+
+	element.Count = len(ec.Errors) + len(ec.ValidationErrors)
+	element.Summary = ec.Summary
+	for _, err := range ec.Errors {
+		element.Errors = append(element.Errors, err.xmlError())
+	}
+	element.Context = newXMLEntries(ec.Context)
+	return element
+}
+
+// MarshalXML implements xml.Marshaler
+// The element is always <error>; use WriteXML for a namespace or indentation
+func (e *CustomError) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.Encode(e.xmlError())
+}
+
+// WriteXML writes the client-safe XML representation of the error to w
+// Messages and metadata are filtered as in ToClientJSON
+func (e *CustomError) WriteXML(w io.Writer, opts ...XMLOption) error {
+	options := newXMLOptions(opts)
+	element := e.xmlError()
+	element.Namespace = options.namespace
+	return options.encode(w, element)
+}
+
+// MarshalXML implements xml.Marshaler
+// The element is always <errors>; use WriteXML for a namespace or indentation
+func (ec *ErrorCollection) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.Encode(ec.xmlCollection())
+}
+
+// WriteXML writes the client-safe XML representation of the collection to w
+// In production mode only the code, category, message, request ID and
+// validation errors are written, as in ToClientJSON
+func (ec *ErrorCollection) WriteXML(w io.Writer, opts ...XMLOption) error {
+	options := newXMLOptions(opts)
+	element := ec.xmlCollection()
+	element.Namespace = options.namespace
+	return options.encode(w, element)
+}
+
+// newXMLOptions applies the options to the defaults
+func newXMLOptions(opts []XMLOption) xmlOptions {
+	var options xmlOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// encode writes the header, if enabled, and the root element
+func (options xmlOptions) encode(w io.Writer, element interface{}) error {
+	if options.header {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+	}
+
+	enc := xml.NewEncoder(w)
+	if options.indent != "" {
+		enc.Indent("", options.indent)
+	}
+	if err := enc.Encode(element); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ToText returns the client-safe text/plain representation of the error
+// The layout follows %+v without stack trace or causes; messages and
+// metadata are filtered as in ToClientJSON
+func (e *CustomError) ToText() string {
+	var sb strings.Builder
+	e.writeText(&sb)
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+// WriteText writes the text/plain representation of the error to w
+func (e *CustomError) WriteText(w io.Writer) error {
+	_, err := io.WriteString(w, e.ToText())
+	return err
+}
+
+// writeText writes the text rendering without a trailing newline
+func (e *CustomError) writeText(w io.Writer) {
+	_, _ = io.WriteString(w, e.ClientSafeMessage())
+	_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_FIELD, JSON_FIELD_CATEGORY, e.Category)
+	_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_FIELD, JSON_FIELD_CODE, e.Code)
+	if e.RequestID != "" {
+		_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_FIELD, JSON_FIELD_REQUEST_ID, e.RequestID)
+	}
+	if e.IsRetryable() {
+		_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_FIELD, JSON_FIELD_RETRYABLE, "true")
+	}
+	if delay, ok := e.RetryAfter(); ok {
+		_, _ = fmt.Fprintf(w, "\n%s: %d", JSON_FIELD_RETRY_AFTER_SECONDS, retryAfterSeconds(delay))
+	}
+
+	metadata := e.clientMetadata()
+	if len(metadata) > 0 {
+		_, _ = fmt.Fprintf(w, "\n%s:", JSON_FIELD_METADATA)
+		for _, key := range sortedMetadataKeys(metadata) {
+			_, _ = fmt.Fprintf(w, FORMAT_TEMPLATE_METADATA, key, FormatMetadataValue(metadata[key]))
+		}
+	}
+}
+
+// ToText returns the client-safe text/plain representation of the collection
+// Validation errors are listed first and members follow as numbered entries;
+// production mode omits the members, as in ToClientJSON
+func (ec *ErrorCollection) ToText() string {
+	var sb strings.Builder
+	sb.WriteString(ec.Error())
+	productionMode := GetConfig().ProductionMode
+
+	category := ec.category()
+
+	ec.mu.RLock()
+	requestID := ec.RequestID
+	validationErrors := append([]ValidationError(nil), ec.ValidationErrors...)
+	errs := append([]*CustomError(nil), ec.Errors...)
+	ec.mu.RUnlock()
+
+	_, _ = fmt.Fprintf(&sb, FORMAT_TEMPLATE_FIELD, JSON_FIELD_CATEGORY, category)
+	_, _ = fmt.Fprintf(&sb, FORMAT_TEMPLATE_FIELD, JSON_FIELD_CODE, ERROR_CODE_MULTIPLE_ERRORS)
+	if requestID != "" {
+		_, _ = fmt.Fprintf(&sb, FORMAT_TEMPLATE_FIELD, JSON_FIELD_REQUEST_ID, requestID)
+	}
+	for _, validationErr := range validationErrors {
+		_, _ = fmt.Fprintf(&sb, FORMAT_TEMPLATE_VALIDATION, validationErr.Field, validationErr.Message)
+		if validationErr.Code != "" {
+			_, _ = fmt.Fprintf(&sb, " (%s)", validationErr.Code)
+		}
+	}
+	if !productionMode {
+		for i, err := range errs {
+			_, _ = fmt.Fprintf(&sb, FORMAT_TEMPLATE_MEMBER, i+1)
+			err.writeText(&sb)
+		}
+	}
+
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+// WriteText writes the text/plain representation of the collection to w
+func (ec *ErrorCollection) WriteText(w io.Writer) error {
+	_, err := io.WriteString(w, ec.ToText())
+	return err
+}
@@ -1,8 +1,10 @@
 package cuserr
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"testing"
 )
 
@@ -139,6 +141,58 @@ func BenchmarkToJSONString(b *testing.B) {
 	}
 }
 
+// BenchmarkJSONEncoding compares WriteJSON with ToJSON plus encoding/json
+func BenchmarkJSONEncoding(b *testing.B) {
+	err := NewCustomError(ErrInternal, nil, "benchmark \"streaming\" JSON")
+	err.WithMetadata("user_id", "123")
+	err.WithMetadata("action", "create_user")
+	err.WithMetadataValue("attempt", 3)
+	err.WithRequestID("req-json-writer-test")
+
+	b.Run("WriteJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = err.WriteJSON(io.Discard)
+		}
+	})
+
+	b.Run("WriteJSONIndent", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = err.WriteJSON(io.Discard, WithJSONIndent("  "))
+		}
+	})
+
+	b.Run("ToJSON+encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = json.NewEncoder(io.Discard).Encode(err.ToJSON())
+		}
+	})
+}
+
+// BenchmarkCollectionJSONEncoding compares ErrorCollection.WriteJSON with ToJSON plus encoding/json
+func BenchmarkCollectionJSONEncoding(b *testing.B) {
+	collection := NewValidationErrorCollection().WithRequestID("req-collection")
+	collection.AddValidationWithCode("email", "must be a valid email address", "INVALID_EMAIL")
+	collection.AddValidation("name", "is required")
+	collection.Add(NewNotFoundError("user", "42"))
+
+	b.Run("WriteJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = collection.WriteJSON(io.Discard)
+		}
+	})
+
+	b.Run("ToJSON+encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = json.NewEncoder(io.Discard).Encode(collection.ToJSON())
+		}
+	})
+}
+
 // BenchmarkToHTTPStatus benchmarks HTTP status code mapping
 func BenchmarkToHTTPStatus(b *testing.B) {
 	err := NewCustomError(ErrNotFound, nil, "benchmark HTTP status")
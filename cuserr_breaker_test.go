@@ -0,0 +1,232 @@
+package cuserr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestBreakerTransitions tests opening, half-open probing and closing
+func TestBreakerTransitions(t *testing.T) {
+	clock := newFakeClock()
+	breaker := NewBreaker("payments", BreakerConfig{
+		Window:           time.Minute,
+		FailureThreshold: 3,
+		FailureRatio:     0.5,
+		OpenTimeout:      30 * time.Second,
+		Clock:            clock,
+	})
+	external := NewExternalError("payments", "charge", nil)
+
+	t.Run("Stays closed below thresholds", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_ = breaker.Do(context.Background(), func(context.Context) error { return external })
+		}
+		for i := 0; i < 3; i++ {
+			_ = breaker.Do(context.Background(), func(context.Context) error { return nil })
+		}
+		_ = breaker.Do(context.Background(), func(context.Context) error { return NewValidationError("amount", "negative") })
+		if breaker.State() != BreakerClosed {
+			t.Fatalf("State = %v, want closed", breaker.State())
+		}
+	})
+
+	t.Run("Opens on failures and fails fast", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_ = breaker.Do(context.Background(), func(context.Context) error { return external })
+		}
+		if breaker.State() != BreakerOpen {
+			t.Fatalf("State = %v, want open", breaker.State())
+		}
+
+		clock.now = clock.now.Add(10 * time.Second)
+		called := false
+		err := breaker.Do(context.Background(), func(context.Context) error { called = true; return nil })
+		if called {
+			t.Error("Open circuit should not call fn")
+		}
+		if !errors.Is(err, ErrCircuitOpen) || !IsErrorCode(err, ERROR_CODE_CIRCUIT_OPEN) {
+			t.Fatalf("Expected CIRCUIT_OPEN, got %v", err)
+		}
+		if !IsErrorCategory(err, ErrorCategoryUnavailable) || !IsRetryable(err) {
+			t.Error("CIRCUIT_OPEN should be a retryable unavailable error")
+		}
+		if delay, ok := RetryAfter(err); !ok || delay != 20*time.Second {
+			t.Errorf("RetryAfter = %v, %v; want time until half-open", delay, ok)
+		}
+		if service, _ := GetErrorMetadata(err, MetaService); service != "payments" {
+			t.Errorf("service = %q", service)
+		}
+	})
+
+	t.Run("Failed probe reopens", func(t *testing.T) {
+		clock.now = clock.now.Add(20 * time.Second)
+		if breaker.State() != BreakerHalfOpen {
+			t.Fatalf("State = %v, want half_open", breaker.State())
+		}
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("First probe should be allowed: %v", err)
+		}
+		if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Error("Only one concurrent probe should be allowed")
+		}
+		breaker.Record(NewTimeoutError("charge", nil))
+		if breaker.State() != BreakerOpen {
+			t.Errorf("State = %v, want open", breaker.State())
+		}
+	})
+
+	t.Run("Panicking probe reopens", func(t *testing.T) {
+		clock.now = clock.now.Add(30 * time.Second)
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != "boom" {
+					t.Errorf("The panic should propagate, got %v", recovered)
+				}
+			}()
+			_ = breaker.Do(context.Background(), func(context.Context) error { panic("boom") })
+		}()
+		if breaker.State() != BreakerOpen {
+			t.Errorf("State = %v, want open", breaker.State())
+		}
+	})
+
+	t.Run("Successful probe closes", func(t *testing.T) {
+		clock.now = clock.now.Add(30 * time.Second)
+		if err := breaker.Do(context.Background(), func(context.Context) error { return nil }); err != nil {
+			t.Fatalf("Probe failed: %v", err)
+		}
+		if breaker.State() != BreakerClosed {
+			t.Errorf("State = %v, want closed", breaker.State())
+		}
+	})
+}
+
+// TestBreakerSlidingWindow tests that old failures expire
+func TestBreakerSlidingWindow(t *testing.T) {
+	clock := newFakeClock()
+	breaker := NewBreaker("search", BreakerConfig{
+		Window:           10 * time.Second,
+		FailureThreshold: 2,
+		FailureRatio:     -1,
+		Clock:            clock,
+	})
+
+	breaker.Record(context.DeadlineExceeded)
+	clock.now = clock.now.Add(11 * time.Second)
+	breaker.Record(NewTimeoutError("query", nil))
+	if breaker.State() != BreakerClosed {
+		t.Error("Expired failures should not count")
+	}
+	if status := breaker.Status(); status.Requests != 1 || status.Failures != 1 {
+		t.Errorf("Status = %+v", status)
+	}
+
+	breaker.Record(NewTimeoutError("query", nil))
+	if breaker.State() != BreakerOpen {
+		t.Error("Two failures in the window should open the circuit")
+	}
+
+	breaker.Reset()
+	if breaker.State() != BreakerClosed {
+		t.Error("Reset should close the circuit")
+	}
+}
+
+// TestBreakerRegistry tests per-service breakers and health check output
+func TestBreakerRegistry(t *testing.T) {
+	clock := newFakeClock()
+	registry := NewBreakerRegistry(BreakerConfig{FailureThreshold: 1, Clock: clock})
+
+	if registry.Breaker("ledger") != registry.Breaker("ledger") {
+		t.Error("Registry should return the same breaker per service")
+	}
+
+	registry.RecordError(NewExternalError("inventory", "reserve", nil))
+	registry.RecordError(NewValidationError("sku", "unknown"))
+	if registry.Breaker("inventory").State() != BreakerOpen {
+		t.Error("RecordError should route failures by the service metadata")
+	}
+
+	statuses := registry.Statuses()
+	if len(statuses) != 2 || statuses[0].Service != "inventory" || statuses[1].Service != "ledger" {
+		t.Fatalf("Statuses = %+v", statuses)
+	}
+	if statuses[0].RetryAt == nil || statuses[1].OpenedAt != nil {
+		t.Error("Only open breakers should report open and retry times")
+	}
+
+	data, err := json.Marshal(statuses[0])
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"state":"open"`) {
+		t.Errorf("Status JSON = %s", data)
+	}
+
+	t.Run("Successes dilute the failure ratio", func(t *testing.T) {
+		ratioRegistry := NewBreakerRegistry(BreakerConfig{FailureThreshold: 2, FailureRatio: 0.5, Clock: clock})
+		for i := 0; i < 6; i++ {
+			ratioRegistry.RecordSuccess("search")
+		}
+		ratioRegistry.RecordError(NewExternalError("search", "query", nil))
+		ratioRegistry.RecordError(NewExternalError("search", "query", nil))
+		if status := ratioRegistry.Breaker("search").Status(); status.State != BreakerClosed || status.Requests != 8 {
+			t.Errorf("Status = %+v, want closed with 8 requests", status)
+		}
+	})
+
+	t.Run("Observed outcomes do not use probes", func(t *testing.T) {
+		probeRegistry := NewBreakerRegistry(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second, Clock: clock})
+		probeRegistry.RecordError(NewExternalError("mail", "send", nil))
+		clock.now = clock.now.Add(time.Second)
+
+		breaker := probeRegistry.Breaker("mail")
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("Probe should be allowed: %v", err)
+		}
+		probeRegistry.RecordSuccess("mail")
+		probeRegistry.RecordError(NewExternalError("mail", "send", nil))
+		if breaker.State() != BreakerHalfOpen || breaker.Allow() == nil {
+			t.Fatalf("Outcomes without Allow should not release or decide the probe: %v", breaker.State())
+		}
+		breaker.Record(nil)
+		if breaker.State() != BreakerClosed {
+			t.Errorf("State = %v, want closed", breaker.State())
+		}
+	})
+
+	custom := registry.Configure("ledger", BreakerConfig{FailureThreshold: 10, Clock: clock})
+	if registry.Breaker("ledger") != custom {
+		t.Error("Configure should replace the service breaker")
+	}
+}
+
+// TestBreakerConcurrentUse tests the breaker under concurrent calls
+func TestBreakerConcurrentUse(t *testing.T) {
+	breaker := NewBreaker("cache", BreakerConfig{FailureThreshold: 1000})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			_ = breaker.Do(context.Background(), func(context.Context) error {
+				if id%2 == 0 {
+					return NewTimeoutError("get", nil)
+				}
+				return nil
+			})
+			_ = breaker.Status()
+		}(i)
+	}
+	wg.Wait()
+
+	if status := breaker.Status(); status.Requests != 50 || status.Failures != 25 {
+		t.Errorf("Status = %+v", status)
+	}
+}
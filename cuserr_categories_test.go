@@ -0,0 +1,132 @@
+package cuserr
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestExtendedCategoryConstructors tests the constructors for the extended HTTP categories
+func TestExtendedCategoryConstructors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          *CustomError
+		sentinel     error
+		wantCategory ErrorCategory
+		wantCode     string
+		wantStatus   int
+	}{
+		{"Unavailable", NewUnavailableError("billing", "maintenance"), ErrUnavailable,
+			ErrorCategoryUnavailable, ERROR_CODE_UNAVAILABLE, 503},
+		{"Not Implemented", NewNotImplementedError("bulk export"), ErrNotImplemented,
+			ErrorCategoryNotImplemented, ERROR_CODE_NOT_IMPLEMENTED, 501},
+		{"Gone", NewGoneError("invite", "inv_1"), ErrGone,
+			ErrorCategoryGone, ERROR_CODE_GONE, 410},
+		{"Precondition Failed", NewPreconditionFailedError("If-Match etag mismatch"), ErrPreconditionFailed,
+			ErrorCategoryPreconditionFailed, ERROR_CODE_PRECONDITION_FAILED, 412},
+		{"Payload Too Large", NewPayloadTooLargeError(1024, 4096), ErrPayloadTooLarge,
+			ErrorCategoryPayloadTooLarge, ERROR_CODE_PAYLOAD_TOO_LARGE, 413},
+		{"Unsupported Media Type", NewUnsupportedMediaTypeError("text/csv", "application/json"), ErrUnsupportedMediaType,
+			ErrorCategoryUnsupportedMediaType, ERROR_CODE_UNSUPPORTED_MEDIA_TYPE, 415},
+		{"Unprocessable", NewUnprocessableError("order", "items out of stock"), ErrUnprocessable,
+			ErrorCategoryUnprocessable, ERROR_CODE_UNPROCESSABLE_ENTITY, 422},
+		{"Method Not Allowed", NewMethodNotAllowedError("DELETE", "GET", "POST"), ErrMethodNotAllowed,
+			ErrorCategoryMethodNotAllowed, ERROR_CODE_METHOD_NOT_ALLOWED, 405},
+		{"Client Closed", NewClientClosedError("search", context.Canceled), ErrClientClosed,
+			ErrorCategoryClientClosed, ERROR_CODE_CLIENT_CLOSED_REQUEST, 499},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err.Category != tc.wantCategory {
+				t.Errorf("Category = %v, want %v", tc.err.Category, tc.wantCategory)
+			}
+			if tc.err.Code != tc.wantCode {
+				t.Errorf("Code = %v, want %v", tc.err.Code, tc.wantCode)
+			}
+			if tc.err.ToHTTPStatus() != tc.wantStatus {
+				t.Errorf("Status = %d, want %d", tc.err.ToHTTPStatus(), tc.wantStatus)
+			}
+			if !errors.Is(tc.err, tc.sentinel) {
+				t.Error("Should match its sentinel via errors.Is")
+			}
+		})
+	}
+
+	t.Run("Metadata", func(t *testing.T) {
+		err := NewMethodNotAllowedError("DELETE", "GET", "POST")
+		if allowed, _ := err.GetMetadata(MetaAllowedMethods); allowed != "GET, POST" {
+			t.Errorf("allowed_methods = %q", allowed)
+		}
+
+		err = NewPayloadTooLargeError(1024, 4096)
+		if limit, _ := err.GetMetadata(MetaPayloadLimit); limit != "1024" {
+			t.Errorf("payload_limit = %q", limit)
+		}
+
+		err = NewClientClosedError("search", context.Canceled)
+		if !errors.Is(err, context.Canceled) {
+			t.Error("Should wrap context.Canceled")
+		}
+	})
+}
+
+// TestExtendedCategoryContextConstructors tests the context-aware variants
+func TestExtendedCategoryContextConstructors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "req-ext")
+
+	errs := []*CustomError{
+		NewUnavailableErrorFromContext(ctx, "billing", ""),
+		NewNotImplementedErrorFromContext(ctx, "export"),
+		NewGoneErrorFromContext(ctx, "invite", ""),
+		NewPreconditionFailedErrorFromContext(ctx, ""),
+		NewPayloadTooLargeErrorFromContext(ctx, 0, 0),
+		NewUnsupportedMediaTypeErrorFromContext(ctx, "text/csv"),
+		NewUnprocessableErrorFromContext(ctx, "order", ""),
+		NewMethodNotAllowedErrorFromContext(ctx, "PUT"),
+		NewClientClosedErrorFromContext(ctx, "search", nil),
+	}
+
+	for _, err := range errs {
+		if err.RequestID != "req-ext" {
+			t.Errorf("%s: RequestID = %q, want req-ext", err.Code, err.RequestID)
+		}
+	}
+
+	t.Run("Client closed wraps context error", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := NewClientClosedErrorFromContext(cancelled, "search", nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Error("Should wrap the context error")
+		}
+	})
+}
+
+// TestFromHTTPStatusExtendedCategories tests the reverse mapping for the extended categories
+func TestFromHTTPStatusExtendedCategories(t *testing.T) {
+	testCases := map[int]ErrorCategory{
+		405: ErrorCategoryMethodNotAllowed,
+		410: ErrorCategoryGone,
+		412: ErrorCategoryPreconditionFailed,
+		413: ErrorCategoryPayloadTooLarge,
+		415: ErrorCategoryUnsupportedMediaType,
+		422: ErrorCategoryUnprocessable,
+		499: ErrorCategoryClientClosed,
+		501: ErrorCategoryNotImplemented,
+		502: ErrorCategoryExternal,
+		503: ErrorCategoryUnavailable,
+		504: ErrorCategoryExternal,
+	}
+
+	for status, want := range testCases {
+		err := FromHTTPStatus(status, "")
+		if err.Category != want {
+			t.Errorf("FromHTTPStatus(%d) category = %v, want %v", status, err.Category, want)
+		}
+		if status != 502 && status != 504 && err.ToHTTPStatus() != status {
+			t.Errorf("FromHTTPStatus(%d) round-trips to %d", status, err.ToHTTPStatus())
+		}
+	}
+}
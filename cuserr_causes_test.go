@@ -0,0 +1,140 @@
+package cuserr
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// TestMultipleCauses tests errors with several causes
+func TestMultipleCauses(t *testing.T) {
+	primary := NewTimeoutError("replica-a write", nil)
+	secondary := &fs.PathError{Op: "write", Path: "/data/b", Err: fs.ErrPermission}
+
+	err := NewCustomError(ErrInternal, nil, "replicated write failed").WithCauses(primary, secondary)
+
+	t.Run("Causes", func(t *testing.T) {
+		causes := err.Causes()
+		if len(causes) != 2 || causes[0] != primary || causes[1] != secondary {
+			t.Fatalf("Causes = %v", causes)
+		}
+		if err.Unwrap() == nil {
+			t.Error("Unwrap should still return an error")
+		}
+		if len(NewValidationError("email", "invalid").Causes()) != 0 {
+			t.Error("Errors without causes should report none")
+		}
+	})
+
+	t.Run("errors.Is and errors.As traverse every cause", func(t *testing.T) {
+		if !errors.Is(err, ErrTimeout) || !errors.Is(err, fs.ErrPermission) || !errors.Is(err, ErrInternal) {
+			t.Error("errors.Is should match the sentinel and every cause")
+		}
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) || pathErr.Path != "/data/b" {
+			t.Error("errors.As should find the second cause")
+		}
+	})
+
+	t.Run("Error and DetailedError render all causes", func(t *testing.T) {
+		want := "replicated write failed: [replica-a write operation timed out; write /data/b: permission denied]"
+		if err.Error() != want {
+			t.Errorf("Error() = %q", err.Error())
+		}
+		detailed := err.DetailedError()
+		if !strings.Contains(detailed, "Cause 1: replica-a write") || !strings.Contains(detailed, "Cause 2: write /data/b") {
+			t.Errorf("DetailedError missing causes:\n%s", detailed)
+		}
+	})
+
+	t.Run("Log fields and JSON render all causes", func(t *testing.T) {
+		fields := err.ToLogFields()
+		if causes, ok := fields["causes"].([]string); !ok || len(causes) != 2 {
+			t.Errorf("causes field = %#v", fields["causes"])
+		}
+
+		causes := err.ToJSON()[JSON_FIELD_ERROR].(map[string]interface{})[JSON_FIELD_CAUSES].([]map[string]interface{})
+		if len(causes) != 2 {
+			t.Fatalf("JSON causes = %v", causes)
+		}
+		if causes[0][JSON_FIELD_CODE] != ERROR_CODE_TIMEOUT || causes[1][JSON_FIELD_MESSAGE] != secondary.Error() {
+			t.Errorf("JSON causes = %v", causes)
+		}
+		if _, exposed := err.ToClientJSON()[JSON_FIELD_ERROR].(map[string]interface{})[JSON_FIELD_CAUSES]; exposed {
+			t.Error("Client JSON should not expose causes")
+		}
+	})
+
+	t.Run("errors.Join is preserved", func(t *testing.T) {
+		joined := errors.Join(errors.New("disk a full"), errors.New("disk b full"))
+		wrapped := NewCustomError(ErrUnavailable, joined, "storage unavailable")
+
+		if len(wrapped.Causes()) != 2 {
+			t.Errorf("Causes = %v", wrapped.Causes())
+		}
+		if wrapped.Error() != "storage unavailable: [disk a full; disk b full]" {
+			t.Errorf("Error() = %q", wrapped.Error())
+		}
+
+		extended := wrapped.WithCauses(errors.New("disk c full"))
+		if len(extended.Causes()) != 3 {
+			t.Errorf("WithCauses should append to joined causes, got %v", extended.Causes())
+		}
+	})
+
+	t.Run("Multi-%w errors stay a single cause", func(t *testing.T) {
+		cause := fmt.Errorf("writing replicas: %w, %w", errors.New("replica a down"), errors.New("replica b down"))
+		wrapped := NewCustomError(ErrInternal, cause, "save failed")
+		if causes := wrapped.Causes(); len(causes) != 1 || causes[0] != cause {
+			t.Errorf("Causes = %v", causes)
+		}
+		if wrapped.Error() != "save failed: writing replicas: replica a down, replica b down" {
+			t.Errorf("Error() = %q", wrapped.Error())
+		}
+		if logged := wrapped.ToLogFields()["wrapped_error"]; logged != cause.Error() {
+			t.Errorf("wrapped_error = %v", logged)
+		}
+
+		extended := wrapped.WithCauses(primary)
+		if causes := extended.Causes(); len(causes) != 2 || causes[0] != cause || causes[1] != primary {
+			t.Errorf("WithCauses should keep the multi-%%w cause whole, got %v", causes)
+		}
+		if !strings.Contains(extended.Error(), "writing replicas") || !errors.Is(extended, ErrTimeout) {
+			t.Errorf("Error() = %q", extended.Error())
+		}
+
+		multi := multiError{secondary, errors.New("replica c offline")}
+		custom := NewCustomError(ErrInternal, multi, "replication failed")
+		var pathErr *fs.PathError
+		if len(custom.Causes()) != 1 || !errors.As(custom, &pathErr) || !errors.Is(custom, fs.ErrPermission) {
+			t.Error("errors.Is and errors.As should still see the members of a custom multi-error")
+		}
+	})
+
+	t.Run("Collections stay a single cause", func(t *testing.T) {
+		collection := NewErrorCollection("batch failed")
+		collection.Add(primary)
+		collection.Add(NewValidationError("email", "invalid"))
+		wrapped := NewCustomError(ErrInternal, collection, "import failed")
+		if causes := wrapped.Causes(); len(causes) != 1 || causes[0] != collection {
+			t.Errorf("Causes = %v", causes)
+		}
+	})
+
+	t.Run("Frozen errors", func(t *testing.T) {
+		prototype := NewCustomError(ErrInternal, nil, "write failed").Freeze()
+		derived := prototype.WithCauses(primary)
+		if derived == prototype || len(prototype.Causes()) != 0 || len(derived.Causes()) != 1 {
+			t.Error("WithCauses on a frozen error should return a copy")
+		}
+	})
+}
+
+// multiError is a multi-error that is not an errors.Join value
+type multiError []error
+
+func (m multiError) Error() string { return fmt.Sprintf("%d errors", len(m)) }
+
+func (m multiError) Unwrap() []error { return m }
@@ -0,0 +1,86 @@
+package cuserr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestErrorCollectionUnwrap tests errors.Is and errors.As over collection members
+func TestErrorCollectionUnwrap(t *testing.T) {
+	collection := NewErrorCollection("import failed").WithRequestID("req-7")
+	collection.Add(NewNotFoundError("user", "usr_1").WithMetadata(MetaResource, "user"))
+	collection.AddValidationWithCode("email", "must be valid", "EMAIL_INVALID")
+
+	members := collection.Unwrap()
+	if len(members) != 2 {
+		t.Fatalf("Unwrap returned %d members", len(members))
+	}
+
+	if !errors.Is(collection, ErrNotFound) || !errors.Is(collection, ErrInvalidInput) {
+		t.Error("errors.Is should match member sentinels, including validation errors")
+	}
+	if errors.Is(collection, ErrTimeout) {
+		t.Error("errors.Is should not match absent sentinels")
+	}
+
+	synthesized := members[1].(*CustomError)
+	if synthesized.Code != "EMAIL_INVALID" || synthesized.Category != ErrorCategoryValidation || synthesized.RequestID != "req-7" {
+		t.Errorf("Synthesized validation error = %+v", synthesized)
+	}
+	if field, _ := synthesized.GetMetadata(MetaField); field != "email" {
+		t.Errorf("field = %q", field)
+	}
+	if len(synthesized.GetStackTrace()) != 0 {
+		t.Error("Synthesized validation errors should not carry stack traces")
+	}
+
+	wrapped := fmt.Errorf("batch 3: %w", collection)
+	var customErr *CustomError
+	if !errors.As(wrapped, &customErr) || customErr.Code != ERROR_CODE_NOT_FOUND {
+		t.Error("errors.As should reach collection members through wrapping")
+	}
+}
+
+// TestCollectionAwareInspection tests the inspection helpers on collections
+func TestCollectionAwareInspection(t *testing.T) {
+	collection := NewErrorCollection("checkout failed")
+	collection.AddValidation("quantity", "must be positive")
+	collection.Add(NewExternalError("payments", "charge", nil))
+
+	if !IsErrorCategory(collection, ErrorCategoryValidation) || !IsErrorCategory(collection, ErrorCategoryExternal) {
+		t.Error("IsErrorCategory should match any member")
+	}
+	if IsErrorCategory(collection, ErrorCategoryNotFound) {
+		t.Error("IsErrorCategory should not match absent categories")
+	}
+	if !IsErrorCode(fmt.Errorf("wrapped: %w", collection), ERROR_CODE_EXTERNAL_ERROR) {
+		t.Error("IsErrorCode should match members of wrapped collections")
+	}
+	if GetErrorCategory(collection) != ErrorCategoryExternal {
+		t.Errorf("GetErrorCategory = %v, want the most severe member's category", GetErrorCategory(collection))
+	}
+	if GetErrorCode(collection) != ERROR_CODE_MULTIPLE_ERRORS {
+		t.Errorf("GetErrorCode = %q", GetErrorCode(collection))
+	}
+	if service, ok := GetErrorMetadata(collection, MetaService); !ok || service != "payments" {
+		t.Errorf("GetErrorMetadata = %q, %v", service, ok)
+	}
+	if GetErrorSeverity(collection) != SeverityError {
+		t.Errorf("GetErrorSeverity = %v", GetErrorSeverity(collection))
+	}
+
+	t.Run("Single errors keep their behavior", func(t *testing.T) {
+		outer := NewInternalError("sync", NewNotFoundError("user", "1"))
+		if IsErrorCategory(outer, ErrorCategoryNotFound) {
+			t.Error("Only the outermost CustomError should be inspected")
+		}
+		if GetErrorCode(errors.New("plain")) != ERROR_CODE_INTERNAL_ERROR || GetErrorCategory(nil) != ErrorCategoryInternal {
+			t.Error("Plain errors should fall back to internal")
+		}
+		joined := errors.Join(errors.New("plain"), NewTimeoutError("db", nil))
+		if GetErrorCategory(joined) != ErrorCategoryTimeout {
+			t.Error("Joined errors should be searched in order")
+		}
+	})
+}
@@ -208,7 +208,8 @@ func TestHTTPStatusBoundaryConditions(t *testing.T) {
 
 			// Client error boundaries
 			{"HTTP 400 Exact", 400, ErrorCategoryValidation, ""},
-			{"HTTP 499 Edge", 499, ErrorCategoryValidation, ""},
+			{"HTTP 498 Edge", 498, ErrorCategoryValidation, ""},
+			{"HTTP 499 Client Closed", 499, ErrorCategoryClientClosed, ""},
 
 			// Server error boundaries
 			{"HTTP 500 Exact", 500, ErrorCategoryInternal, ""},
@@ -0,0 +1,108 @@
+package cuserr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var errTestUserSuspended = Define("TEST_USER_SUSPENDED", ErrorCategoryForbidden,
+	"user {user_id} is suspended until {until}",
+	WithSentinelDescription("The account was suspended by an administrator"))
+
+// TestDefinitionNew tests instantiating errors from a definition
+func TestDefinitionNew(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "req-def")
+	err := errTestUserSuspended.New(ctx, Arg("user_id", 42), Arg("until", "2026-01-01"))
+
+	if err.Message != "user 42 is suspended until 2026-01-01" {
+		t.Errorf("Message = %q", err.Message)
+	}
+	if err.Code != "TEST_USER_SUSPENDED" || err.Category != ErrorCategoryForbidden {
+		t.Errorf("Code/Category = %v/%v", err.Code, err.Category)
+	}
+	if err.ToHTTPStatus() != 403 {
+		t.Errorf("Status = %d, want 403", err.ToHTTPStatus())
+	}
+	if err.RequestID != "req-def" {
+		t.Errorf("RequestID = %q, want req-def", err.RequestID)
+	}
+	if userID, _ := err.GetMetadata("user_id"); userID != "42" {
+		t.Errorf("user_id metadata = %q, want 42", userID)
+	}
+	if !errors.Is(err, errTestUserSuspended) {
+		t.Error("errors.Is should match the definition")
+	}
+	if errors.Is(err, ErrForbidden) {
+		t.Error("Should not match unrelated sentinels")
+	}
+	if !errors.Is(fmt.Errorf("login: %w", err), errTestUserSuspended) {
+		t.Error("errors.Is should match through wrapping")
+	}
+
+	t.Run("Stack trace starts at caller", func(t *testing.T) {
+		frames := errTestUserSuspended.New(context.Background()).GetStackTrace()
+		if len(frames) == 0 {
+			t.Fatal("Expected stack trace")
+		}
+		if !strings.Contains(frames[0].Function, "TestDefinitionNew") {
+			t.Errorf("First frame = %s, want test function", frames[0].Function)
+		}
+	})
+
+	t.Run("Missing parameters are left in place", func(t *testing.T) {
+		err := errTestUserSuspended.New(context.TODO(), Arg("user_id", "u1"))
+		if err.Message != "user u1 is suspended until {until}" {
+			t.Errorf("Message = %q", err.Message)
+		}
+	})
+}
+
+// TestDefinitionWrap tests wrapping causes with a definition
+func TestDefinitionWrap(t *testing.T) {
+	cause := errors.New("ban list lookup failed")
+	err := errTestUserSuspended.Wrap(context.Background(), cause, Arg("user_id", "u1"), Arg("until", "never"))
+
+	if !errors.Is(err, cause) || !errors.Is(err, errTestUserSuspended) {
+		t.Error("Should match both the cause and the definition")
+	}
+	if errTestUserSuspended.Wrap(context.Background(), nil) != nil {
+		t.Error("Wrapping nil should return nil")
+	}
+}
+
+// TestDefinitionRegistry tests definition registration and introspection
+func TestDefinitionRegistry(t *testing.T) {
+	spec, ok := LookupSentinelByCode("TEST_USER_SUSPENDED")
+	if !ok || spec.Sentinel != errTestUserSuspended {
+		t.Fatal("Definition should be registered as a sentinel")
+	}
+	if spec.Description != errTestUserSuspended.Description() {
+		t.Errorf("Description = %q", spec.Description)
+	}
+
+	found := false
+	for _, def := range Definitions() {
+		if def == errTestUserSuspended {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Definitions should include the definition")
+	}
+
+	if err := NewCustomError(errTestUserSuspended, nil, "custom"); err.Code != "TEST_USER_SUSPENDED" {
+		t.Errorf("NewCustomError should resolve the definition, got %v", err.Code)
+	}
+
+	t.Run("Duplicate code panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic")
+			}
+		}()
+		Define("TEST_USER_SUSPENDED", ErrorCategoryConflict, "again")
+	})
+}
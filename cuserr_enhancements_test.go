@@ -3,6 +3,7 @@ package cuserr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -119,8 +120,8 @@ func TestErrorAggregation(t *testing.T) {
 
 		fields := collection.GetFields()
 		expectedFields := []string{"email", "password", "age"}
-		if len(fields) != len(expectedFields) {
-			t.Errorf("Expected %d fields, got %d", len(expectedFields), len(fields))
+		if strings.Join(fields, ",") != strings.Join(expectedFields, ",") {
+			t.Errorf("Expected fields %v in insertion order, got %v", expectedFields, fields)
 		}
 	})
 
@@ -318,6 +319,19 @@ func TestMigrationHelpers(t *testing.T) {
 		}
 	})
 
+	t.Run("From context cancellation", func(t *testing.T) {
+		customErr := FromStdError(fmt.Errorf("query users: %w", context.Canceled), "")
+		if customErr.Category != ErrorCategoryClientClosed {
+			t.Errorf("Category = %s, want client_closed", customErr.Category)
+		}
+
+		for _, text := range []string{"order cancelled by upstream", "job canceled: quota exceeded"} {
+			if customErr := FromStdError(errors.New(text), ""); customErr.Category == ErrorCategoryClientClosed {
+				t.Errorf("%q should not be treated as a closed client request", text)
+			}
+		}
+	})
+
 	t.Run("From HTTP Status", func(t *testing.T) {
 		err := FromHTTPStatus(404, "resource not found")
 
@@ -511,12 +525,20 @@ func TestErrorCollectionJSONSerialization(t *testing.T) {
 			t.Error("Internal error collection should return 500")
 		}
 
-		// Test mixed errors (validation takes precedence)
+		// Test mixed errors (most severe member takes precedence)
 		mixedCollection := NewErrorCollection("mixed")
 		mixedCollection.Add(NewInternalError("service", nil))
 		mixedCollection.AddValidation("field", "error")
-		if mixedCollection.ToHTTPStatus() != 400 {
-			t.Error("Mixed collection with validation should return 400")
+		if mixedCollection.ToHTTPStatus() != 500 {
+			t.Error("Mixed collection with an internal error should return 500")
+		}
+
+		// Test mixed errors of equal severity (validation wins ties)
+		tiedCollection := NewErrorCollection("tied")
+		tiedCollection.Add(NewNotFoundError("user", "1"))
+		tiedCollection.AddValidation("field", "error")
+		if tiedCollection.ToHTTPStatus() != 400 {
+			t.Error("Tied collection with validation should return 400")
 		}
 	})
 }
@@ -254,7 +254,7 @@ func TestErrorChainDebugging(t *testing.T) {
 		}
 
 		// Extract all metadata from chain
-		allMetadata := make(map[string]map[string]string)
+		allMetadata := make(map[string]map[string]interface{})
 		for i, err := range customErrors {
 			allMetadata[fmt.Sprintf("level_%d", i)] = err.GetAllMetadata()
 		}
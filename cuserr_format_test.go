@@ -0,0 +1,100 @@
+package cuserr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestCustomErrorFormat tests the fmt.Formatter implementation of CustomError
+func TestCustomErrorFormat(t *testing.T) {
+	cause := NewTimeoutError("query", nil)
+	err := NewInternalError("db", cause).
+		WithMetadata("table", "users").
+		WithRequestID("req-42")
+
+	t.Run("Simple verbs print the message chain", func(t *testing.T) {
+		for _, format := range []string{"%s", "%v"} {
+			if got := fmt.Sprintf(format, err); got != err.Error() {
+				t.Errorf("%s = %q", format, got)
+			}
+		}
+		if got := fmt.Sprintf("%q", err); got != fmt.Sprintf("%q", err.Error()) {
+			t.Errorf("%%q = %s", got)
+		}
+		if got := fmt.Errorf("outer: %w", err).Error(); got != "outer: "+err.Error() {
+			t.Errorf("%%w = %q", got)
+		}
+	})
+
+	t.Run("Plus flag prints details", func(t *testing.T) {
+		detailed := fmt.Sprintf("%+v", err)
+		for _, want := range []string{
+			"internal error in db\ncategory: internal\ncode: INTERNAL_ERROR\nrequest_id: req-42\nmetadata:",
+			"\n    table: users",
+			"NewInternalError\n\t",
+			"cuserr.convenience.go:",
+			"\ncaused by: query operation timed out\ncategory: timeout",
+		} {
+			if !strings.Contains(detailed, want) {
+				t.Errorf("%%+v missing %q:\n%s", want, detailed)
+			}
+		}
+		if strings.Index(detailed, "component: db") > strings.Index(detailed, "table: users") {
+			t.Error("Metadata should be sorted by key")
+		}
+	})
+
+	t.Run("Plus flag formats foreign causes with %+v", func(t *testing.T) {
+		wrapped := NewCustomError(ErrExternal, errors.New("connection refused"), "charge failed")
+		if !strings.HasSuffix(fmt.Sprintf("%+v", wrapped), "\ncaused by: connection refused") {
+			t.Errorf("Unexpected output:\n%+v", wrapped)
+		}
+	})
+
+	t.Run("Hash flag prints Go syntax", func(t *testing.T) {
+		goSyntax := fmt.Sprintf("%#v", err)
+		for _, want := range []string{`&cuserr.CustomError{Category:"internal"`, `Code:"INTERNAL_ERROR"`, `"table":"users"`, `RequestID:"req-42"`} {
+			if !strings.Contains(goSyntax, want) {
+				t.Errorf("%%#v missing %q: %s", want, goSyntax)
+			}
+		}
+	})
+
+	t.Run("Unsupported verbs", func(t *testing.T) {
+		if got := fmt.Sprintf("%d", NewNotFoundError("user", "1")); !strings.HasPrefix(got, "%!d(*cuserr.CustomError=") {
+			t.Errorf("%%d = %q", got)
+		}
+	})
+}
+
+// TestErrorCollectionFormat tests the fmt.Formatter implementation of ErrorCollection
+func TestErrorCollectionFormat(t *testing.T) {
+	collection := NewErrorCollection("signup failed").WithRequestID("req-9")
+	collection.AddValidationWithCode("email", "must be valid", "EMAIL_INVALID")
+	collection.Add(NewConflictError("user", "email", "a@example.com"))
+
+	if fmt.Sprintf("%v", collection) != collection.Error() {
+		t.Errorf("%%v = %q", fmt.Sprintf("%v", collection))
+	}
+
+	detailed := fmt.Sprintf("%+v", collection)
+	for _, want := range []string{
+		"signup failed (2 errors)\nrequest_id: req-9",
+		"\n  - email: must be valid (EMAIL_INVALID)",
+		"\n[1] ",
+		"code: ALREADY_EXISTS",
+	} {
+		if !strings.Contains(detailed, want) {
+			t.Errorf("%%+v missing %q:\n%s", want, detailed)
+		}
+	}
+
+	goSyntax := fmt.Sprintf("%#v", collection)
+	for _, want := range []string{"&cuserr.ErrorCollection{Errors:[]*cuserr.CustomError{&cuserr.CustomError{", `Field:"email"`, `Summary:"signup failed"`} {
+		if !strings.Contains(goSyntax, want) {
+			t.Errorf("%%#v missing %q: %s", want, goSyntax)
+		}
+	}
+}
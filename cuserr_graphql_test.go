@@ -0,0 +1,137 @@
+package cuserr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestGraphQLErrorRendering tests GraphQL errors for CustomError and ErrorCollection
+func TestGraphQLErrorRendering(t *testing.T) {
+	t.Run("Extension codes", func(t *testing.T) {
+		cases := []struct {
+			err  *CustomError
+			code string
+		}{
+			{NewValidationError("email", "invalid"), GRAPHQL_CODE_BAD_USER_INPUT},
+			{NewUnauthorizedError("token expired"), GRAPHQL_CODE_UNAUTHENTICATED},
+			{NewForbiddenError("delete", "post"), GRAPHQL_CODE_FORBIDDEN},
+			{NewNotFoundError("user", "1"), GRAPHQL_CODE_NOT_FOUND},
+			{NewTimeoutError("query", nil), GRAPHQL_CODE_INTERNAL_SERVER_ERROR},
+		}
+		for _, tc := range cases {
+			extensions := tc.err.ToGraphQLError().Extensions
+			if extensions[JSON_FIELD_CODE] != tc.code || extensions[JSON_FIELD_ERROR_CODE] != tc.err.Code {
+				t.Errorf("%s: extensions = %v", tc.err.Category, extensions)
+			}
+		}
+
+		SetGraphQLCode(ErrorCategoryRateLimit, "RATE_LIMITED")
+		defer SetGraphQLCode(ErrorCategoryRateLimit, "")
+		if code := NewRateLimitError("100", "1m").ToGraphQLError().Extensions[JSON_FIELD_CODE]; code != "RATE_LIMITED" {
+			t.Errorf("Custom code = %v", code)
+		}
+	})
+
+	t.Run("Path and locations", func(t *testing.T) {
+		gqlErr := NewNotFoundError("friend", "7").
+			WithGraphQLPath("user", "friends", 1, "name").
+			WithGraphQLLocation(3, 5).
+			ToGraphQLError()
+		if fmt.Sprint(gqlErr.Path) != "[user friends 1 name]" || len(gqlErr.Locations) != 1 || gqlErr.Locations[0].Column != 5 {
+			t.Errorf("Error = %+v", gqlErr)
+		}
+		if metadata, _ := gqlErr.Extensions[JSON_FIELD_METADATA].(map[string]interface{}); metadata[MetaGraphQLPath] != nil {
+			t.Error("Path should not be repeated in the metadata extension")
+		}
+
+		dotted := NewInternalError("resolver", nil).WithMetadata(MetaGraphQLPath, "orders.0.total").ToGraphQLError()
+		if fmt.Sprint(dotted.Path) != "[orders 0 total]" {
+			t.Errorf("Path = %v", dotted.Path)
+		}
+		if _, isInt := dotted.Path[1].(int); !isInt {
+			t.Error("Numeric segments should be list indexes")
+		}
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		collection := NewValidationErrorCollection().WithRequestID("req-1")
+		collection.AddValidationWithCode("input.email", "must be valid", "INVALID_EMAIL")
+		collection.Add(NewNotFoundError("org", "o1").WithRequestID("req-own"))
+
+		gqlErrs := collection.ToGraphQLErrors()
+		if len(gqlErrs) != 2 {
+			t.Fatalf("Errors = %+v", gqlErrs)
+		}
+		first := gqlErrs[0].Extensions
+		if first[JSON_FIELD_CODE] != GRAPHQL_CODE_BAD_USER_INPUT || first[JSON_FIELD_FIELD] != "input.email" ||
+			first[JSON_FIELD_ERROR_CODE] != "INVALID_EMAIL" || first[JSON_FIELD_REQUEST_ID] != "req-1" {
+			t.Errorf("First = %v", first)
+		}
+		if gqlErrs[1].Extensions[JSON_FIELD_REQUEST_ID] != "req-own" {
+			t.Errorf("Second = %v", gqlErrs[1].Extensions)
+		}
+	})
+}
+
+// TestGraphQLProductionMode tests masking of internal errors
+func TestGraphQLProductionMode(t *testing.T) {
+	originalConfig := GetConfig()
+	defer SetConfig(originalConfig)
+	SetConfig(&Config{ProductionMode: true})
+
+	internal := NewInternalError("db", nil).WithMetadata("query", "SELECT secret").WithGraphQLPath("me")
+	gqlErr := internal.ToGraphQLError()
+	if gqlErr.Message != internal.ClientSafeMessage() || gqlErr.Message == internal.Message {
+		t.Errorf("Message = %q", gqlErr.Message)
+	}
+	if _, exists := gqlErr.Extensions[JSON_FIELD_METADATA]; exists {
+		t.Errorf("Metadata should be filtered, got %v", gqlErr.Extensions)
+	}
+	if len(gqlErr.Path) != 1 {
+		t.Error("Path should survive production filtering")
+	}
+
+	plain := GraphQLErrors(fmt.Errorf("pq: password authentication failed for user \"admin\""))
+	if len(plain) != 1 || strings.Contains(plain[0].Message, "admin") {
+		t.Errorf("Plain errors should be masked, got %+v", plain)
+	}
+
+	wrapped := GraphQLErrors(fmt.Errorf("query SELECT * FROM users WHERE token='s3cr3t' failed: %w", ErrNotFound))
+	if len(wrapped) != 1 || wrapped[0].Message != ErrNotFound.Error() || wrapped[0].Extensions[JSON_FIELD_CODE] != GRAPHQL_CODE_NOT_FOUND {
+		t.Errorf("Wrapped sentinels should keep their code and message, got %+v", wrapped)
+	}
+	if body, _ := json.Marshal(wrapped); strings.Contains(string(body), "s3cr3t") || strings.Contains(string(body), "SELECT") {
+		t.Errorf("Wrapped sentinels should not leak the foreign text, got %s", body)
+	}
+}
+
+// TestGraphQLResponse tests partial-data responses
+func TestGraphQLResponse(t *testing.T) {
+	data := map[string]interface{}{"user": map[string]interface{}{"name": "Ada", "friends": nil}}
+
+	collection := NewValidationErrorCollection()
+	collection.AddValidation("first", "must be positive")
+	wrapped := fmt.Errorf("resolve friends: %w", NewTimeoutError("friends", nil).WithGraphQLPath("user", "friends"))
+
+	response := NewGraphQLResponse(data, wrapped, nil, collection)
+	if len(response.Errors) != 2 {
+		t.Fatalf("Errors = %+v", response.Errors)
+	}
+	if fmt.Sprint(response.Errors[0].Path) != "[user friends]" {
+		t.Errorf("Wrapped errors should keep their path, got %v", response.Errors[0].Path)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.HasPrefix(string(body), `{"data":{"user":{"friends":null,"name":"Ada"}},"errors":[{"message":`) {
+		t.Errorf("Body = %s", body)
+	}
+
+	if body, _ := json.Marshal(NewGraphQLResponse(nil, NewUnauthorizedError("no token"))); strings.Contains(string(body), `"data"`) {
+		t.Errorf("Request errors should omit data, got %s", body)
+	}
+}
@@ -0,0 +1,191 @@
+package cuserr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripError serves err with WriteHTTPError and decodes the response with FromHTTPResponse
+func roundTripError(t *testing.T, err error, accept string) (error, string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = WriteHTTPError(w, r, err)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, reqErr := server.Client().Do(req)
+	if reqErr != nil {
+		t.Fatal(reqErr)
+	}
+	defer resp.Body.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	return FromHTTPResponse(resp), serverURL.Host
+}
+
+// TestFromHTTPResponseRoundTrip tests decoding responses written by WriteHTTPError
+func TestFromHTTPResponseRoundTrip(t *testing.T) {
+	original := NewNotFoundError("user", "u1").WithRequestID("req-1").WithMetadata(MetaTenantID, "t1")
+
+	for _, accept := range []string{"", CONTENT_TYPE_PROBLEM_JSON} {
+		t.Run("Accept "+accept, func(t *testing.T) {
+			decoded, host := roundTripError(t, original, accept)
+
+			var customErr *CustomError
+			if !errors.As(decoded, &customErr) {
+				t.Fatalf("decoded = %T", decoded)
+			}
+			if !errors.Is(decoded, ErrNotFound) || customErr.Code != ERROR_CODE_NOT_FOUND || customErr.Message != original.Message {
+				t.Errorf("decoded = %+v", customErr)
+			}
+			if customErr.RequestID != "req-1" {
+				t.Errorf("RequestID = %q", customErr.RequestID)
+			}
+			if tenant, _ := customErr.GetMetadata(MetaTenantID); tenant != "t1" {
+				t.Errorf("tenant = %q", tenant)
+			}
+			if !IsRemoteError(decoded) || GetOriginService(decoded) != host {
+				t.Errorf("remote = %v, origin = %q", IsRemoteError(decoded), GetOriginService(decoded))
+			}
+		})
+	}
+
+	t.Run("Retry hint", func(t *testing.T) {
+		decoded, _ := roundTripError(t, NewRateLimitError("100", "1m").WithRetryAfter(30*time.Second), "")
+		if delay, ok := RetryAfter(decoded); !ok || delay != 30*time.Second || !errors.Is(decoded, ErrRateLimit) {
+			t.Errorf("RetryAfter = %v, %v", delay, ok)
+		}
+	})
+
+	t.Run("Unregistered code", func(t *testing.T) {
+		decoded, _ := roundTripError(t, NewForbiddenError("login", "account").WithCode("USER_BANNED"), "")
+		if !errors.Is(decoded, ErrForbidden) || GetErrorCode(decoded) != "USER_BANNED" {
+			t.Errorf("decoded = %+v", decoded)
+		}
+	})
+
+	t.Run("Unregistered code of a custom category", func(t *testing.T) {
+		quota := MustDefineCategory("test_quota", CategorySpec{HTTPStatus: http.StatusPaymentRequired})
+		defer UndefineCategory(quota)
+		userQuota := Define("TEST_USER_QUOTA", quota, "user quota exceeded")
+		defer UnregisterSentinel(userQuota)
+		orgQuota := Define("TEST_ORG_QUOTA", quota, "org quota exceeded")
+		defer UnregisterSentinel(orgQuota)
+
+		decoded, _ := roundTripError(t, userQuota.New(nil).WithCode("TEST_STORAGE_QUOTA"), "")
+		if errors.Is(decoded, userQuota) || errors.Is(decoded, orgQuota) {
+			t.Error("Unregistered codes should not match local definitions of the same category")
+		}
+		if GetErrorCode(decoded) != "TEST_STORAGE_QUOTA" || GetErrorCategory(decoded) != quota {
+			t.Errorf("decoded = %+v", decoded)
+		}
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		collection := NewValidationErrorCollection().WithRequestID("req-c")
+		collection.AddValidationWithCode("email", "is required", "REQUIRED")
+		collection.Add(NewConflictError("user", "name", "ann"))
+
+		decoded, host := roundTripError(t, collection, "")
+		var decodedCollection *ErrorCollection
+		if !errors.As(decoded, &decodedCollection) {
+			t.Fatalf("decoded = %T", decoded)
+		}
+		if decodedCollection.ValidationCount() != 1 || decodedCollection.ErrorCount() != 1 || decodedCollection.RequestID != "req-c" {
+			t.Errorf("decoded = %+v", decodedCollection)
+		}
+		if !errors.Is(decoded, ErrAlreadyExists) || !IsRemoteError(decoded) || GetOriginService(decoded) != host {
+			t.Errorf("Is = %v, remote = %v", errors.Is(decoded, ErrAlreadyExists), IsRemoteError(decoded))
+		}
+	})
+
+	t.Run("Production collection", func(t *testing.T) {
+		originalConfig := GetConfig()
+		defer SetConfig(originalConfig)
+		SetConfig(&Config{ProductionMode: true})
+
+		collection := NewErrorCollection("batch failed")
+		collection.Add(NewInternalError("db", errors.New("connection refused")))
+
+		decoded, _ := roundTripError(t, collection, "")
+		if !errors.Is(decoded, ErrInternal) {
+			t.Errorf("decoded = %v", decoded)
+		}
+	})
+}
+
+// TestFromHTTPResponseFallback tests responses that are not cuserr documents
+func TestFromHTTPResponseFallback(t *testing.T) {
+	newResponse := func(status int, contentType, body string) *http.Response {
+		header := http.Header{}
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	t.Run("Plain text", func(t *testing.T) {
+		decoded := FromHTTPResponse(newResponse(http.StatusBadGateway, "text/plain", " upstream exploded \nstack..."))
+		var customErr *CustomError
+		if !errors.As(decoded, &customErr) || !errors.Is(decoded, ErrExternal) || customErr.Message != "upstream exploded" {
+			t.Errorf("decoded = %+v", decoded)
+		}
+		if !IsRemoteError(decoded) || GetOriginService(decoded) != "" {
+			t.Errorf("remote = %v, origin = %q", IsRemoteError(decoded), GetOriginService(decoded))
+		}
+	})
+
+	t.Run("HTML with headers", func(t *testing.T) {
+		resp := newResponse(http.StatusServiceUnavailable, "text/html", "<html><body>down</body></html>")
+		resp.Header.Set("Retry-After", "120")
+		resp.Header.Set("X-Request-ID", "req-h")
+		decoded := FromHTTPResponse(resp, WithOriginService("billing"))
+
+		var customErr *CustomError
+		if !errors.As(decoded, &customErr) || !errors.Is(decoded, ErrUnavailable) || strings.Contains(customErr.Message, "html") {
+			t.Fatalf("decoded = %+v", decoded)
+		}
+		if delay, _ := customErr.RetryAfter(); delay != 2*time.Minute || customErr.RequestID != "req-h" {
+			t.Errorf("RetryAfter = %v, RequestID = %q", delay, customErr.RequestID)
+		}
+		if GetOriginService(decoded) != "billing" {
+			t.Errorf("origin = %q", GetOriginService(decoded))
+		}
+	})
+
+	t.Run("Oversized and malformed", func(t *testing.T) {
+		body := NewNotFoundError("user", "u1").ToJSONString()
+		for _, decoded := range []error{
+			FromHTTPResponse(newResponse(http.StatusNotFound, "application/json", body), WithMaxBodyBytes(10)),
+			FromHTTPResponse(newResponse(http.StatusNotFound, "application/json", body[:len(body)-5])),
+			FromHTTPResponse(newResponse(http.StatusNotFound, "application/json", `{"error":"not found"}`)),
+			FromHTTPResponse(&http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}}),
+		} {
+			if !errors.Is(decoded, ErrNotFound) || !IsRemoteError(decoded) {
+				t.Errorf("decoded = %+v", decoded)
+			}
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		if FromHTTPResponse(newResponse(http.StatusOK, "application/json", "{}")) != nil || FromHTTPResponse(nil) != nil {
+			t.Error("Successful responses should yield nil")
+		}
+	})
+
+	t.Run("Local errors", func(t *testing.T) {
+		if IsRemoteError(NewNotFoundError("user", "u1")) || IsRemoteError(errors.New("plain")) {
+			t.Error("Local errors should not be remote")
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package cuserr
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCustomErrorHeaders tests the headers derived from single errors
+func TestCustomErrorHeaders(t *testing.T) {
+	cases := []struct {
+		name string
+		err  *CustomError
+		want map[string]string
+	}{
+		{
+			"Rate limit",
+			NewRateLimitError("100", "1m").WithRetryAfter(1500*time.Millisecond).WithMetadata(MetaRateLimitRemaining, "3"),
+			map[string]string{
+				"Retry-After":         "2",
+				"RateLimit-Limit":     "100",
+				"RateLimit-Remaining": "3",
+				"RateLimit-Reset":     "2",
+				"RateLimit-Policy":    "100;w=60",
+			},
+		},
+		{
+			"Rate limit without hint",
+			NewRateLimitError("10", "30s"),
+			map[string]string{
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "30",
+				"RateLimit-Policy":    "10;w=30",
+			},
+		},
+		{
+			"Rate limit with a unit word window",
+			NewRateLimitError("1000", "hour"),
+			map[string]string{
+				"RateLimit-Limit":     "1000",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "3600",
+				"RateLimit-Policy":    "1000;w=3600",
+			},
+		},
+		{
+			"Rate limit with a counted unit window",
+			NewRateLimitError("50", "15 Minutes"),
+			map[string]string{
+				"RateLimit-Limit":     "50",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "900",
+				"RateLimit-Policy":    "50;w=900",
+			},
+		},
+		{
+			"Rate limit with free-form window",
+			NewRateLimitError("20", "a while"),
+			map[string]string{
+				"RateLimit-Limit":     "20",
+				"RateLimit-Remaining": "0",
+			},
+		},
+		{
+			"Rate limit with free-form limit",
+			NewRateLimitError("many", "a while"),
+			map[string]string{},
+		},
+		{
+			"Unavailable",
+			NewUnavailableError("payments", "maintenance").WithRetryAfter(30 * time.Second),
+			map[string]string{"Retry-After": "30"},
+		},
+		{
+			"Unauthorized",
+			NewUnauthorizedError("token expired"),
+			map[string]string{"WWW-Authenticate": "Bearer"},
+		},
+		{
+			"Unauthorized with challenge",
+			NewUnauthorizedError("token expired").WithAuthChallenge(`Bearer realm="api", error="invalid_token"`),
+			map[string]string{"WWW-Authenticate": `Bearer realm="api", error="invalid_token"`},
+		},
+		{
+			"Method not allowed",
+			NewMethodNotAllowedError("DELETE", "GET", "POST"),
+			map[string]string{"Allow": "GET, POST"},
+		},
+		{
+			"Request ID",
+			NewNotFoundError("user", "u1").WithRequestID("req-1"),
+			map[string]string{"X-Request-ID": "req-1"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := Headers(fmt.Errorf("wrapped: %w", tc.err))
+			if len(header) != len(tc.want) {
+				t.Errorf("Headers = %v, want %v", header, tc.want)
+			}
+			for key, value := range tc.want {
+				if got := header.Get(key); got != value {
+					t.Errorf("%s = %q, want %q", key, got, value)
+				}
+			}
+		})
+	}
+
+	t.Run("Default challenge", func(t *testing.T) {
+		defer SetDefaultAuthChallenge(AUTH_CHALLENGE_DEFAULT)
+
+		SetDefaultAuthChallenge(`Basic realm="admin"`)
+		if got := NewUnauthorizedError("").Headers().Get("WWW-Authenticate"); got != `Basic realm="admin"` {
+			t.Errorf("WWW-Authenticate = %q", got)
+		}
+		SetDefaultAuthChallenge("")
+		if _, exists := NewUnauthorizedError("").Headers()["Www-Authenticate"]; exists {
+			t.Error("An empty default challenge should omit the header")
+		}
+	})
+
+	t.Run("Foreign error", func(t *testing.T) {
+		header := Headers(fmt.Errorf("plain"))
+		if header == nil || len(header) != 0 {
+			t.Errorf("Headers = %#v", header)
+		}
+	})
+}
+
+// TestErrorCollectionHeaders tests header merging across members
+func TestErrorCollectionHeaders(t *testing.T) {
+	collection := NewErrorCollection("upstream failures").WithRequestID("req-c")
+	collection.Add(NewRateLimitError("100", "1m").WithRetryAfter(5 * time.Second).WithRequestID("req-member").WithSeverity(SeverityCritical))
+	collection.Add(NewRateLimitError("50", "1m").WithSeverity(SeverityCritical))
+	collection.Add(NewUnavailableError("search", "").WithRetryAfter(20 * time.Second).WithSeverity(SeverityWarning))
+	collection.Add(NewUnauthorizedError("token expired").WithSeverity(SeverityWarning))
+
+	if status := collection.ToHTTPStatus(); status != http.StatusTooManyRequests {
+		t.Fatalf("status = %d", status)
+	}
+	header := Headers(collection)
+
+	if header.Get("RateLimit-Limit") != "100" || header.Get("RateLimit-Policy") != "100;w=60" {
+		t.Errorf("RateLimit fields should come from the first matching member: %v", header)
+	}
+	if header.Get("WWW-Authenticate") != "" {
+		t.Errorf("Members with another status should not contribute: %v", header)
+	}
+	if header.Get("Retry-After") != "20" {
+		t.Errorf("Retry-After = %q, want the longest hint", header.Get("Retry-After"))
+	}
+	if header.Get("X-Request-ID") != "req-c" {
+		t.Errorf("X-Request-ID = %q", header.Get("X-Request-ID"))
+	}
+	if len(Headers(NewErrorCollection("empty"))) != 0 {
+		t.Error("An empty collection should have no headers")
+	}
+}
+
+// TestWriteHTTPErrorHeaders tests that error responses carry the derived headers
+func TestWriteHTTPErrorHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	req = req.WithContext(ContextWithRequestID(req.Context(), "req-ctx"))
+	rec := httptest.NewRecorder()
+	err := NewRateLimitError("100", "1m").WithRetryAfter(10 * time.Second)
+	if writeErr := WriteHTTPError(rec, req, err); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d", rec.Code)
+	}
+	for key, value := range map[string]string{
+		"Retry-After":     "10",
+		"RateLimit-Limit": "100",
+		"RateLimit-Reset": "10",
+		"X-Request-ID":    "req-ctx",
+	} {
+		if got := rec.Header().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	t.Run("Collection request ID from context", func(t *testing.T) {
+		collection := NewValidationErrorCollection()
+		collection.AddValidation("email", "is required")
+		rec := httptest.NewRecorder()
+		_ = WriteHTTPError(rec, req, collection)
+		if rec.Header().Get("X-Request-ID") != "req-ctx" {
+			t.Errorf("X-Request-ID = %q", rec.Header().Get("X-Request-ID"))
+		}
+		if !strings.Contains(rec.Body.String(), `"request_id":"req-ctx"`) {
+			t.Errorf("Body should carry the request ID, got %s", rec.Body.String())
+		}
+		if collection.RequestID != "" {
+			t.Error("The caller's collection should not be modified")
+		}
+	})
+}
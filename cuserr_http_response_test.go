@@ -0,0 +1,212 @@
+package cuserr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestWriteHTTPErrorNegotiation tests the formatter chosen for common Accept headers
+func TestWriteHTTPErrorNegotiation(t *testing.T) {
+	err := NewNotFoundError("user", "u1").WithRequestID("req-1")
+
+	cases := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", CONTENT_TYPE_JSON, `"code":"NOT_FOUND"`},
+		{"*/*", CONTENT_TYPE_JSON, `"code":"NOT_FOUND"`},
+		{"application/problem+json", CONTENT_TYPE_PROBLEM_JSON, `"status":404`},
+		{"application/vnd.api+json", CONTENT_TYPE_JSONAPI, `"status":"404"`},
+		{"application/xml", CONTENT_TYPE_XML, `<error code="NOT_FOUND"`},
+		{"text/xml", CONTENT_TYPE_TEXT_XML, `<error code="NOT_FOUND"`},
+		{"text/plain", CONTENT_TYPE_TEXT, "code: NOT_FOUND"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", CONTENT_TYPE_HTML, "<h1>404 Not Found</h1>"},
+		{"application/json;q=0.5, text/plain", CONTENT_TYPE_TEXT, "code: NOT_FOUND"},
+		{"text/*", CONTENT_TYPE_TEXT, "code: NOT_FOUND"},
+		{"image/png", CONTENT_TYPE_JSON, `"code":"NOT_FOUND"`},
+		{"text/plain;q=0, application/xml", CONTENT_TYPE_XML, `<error code="NOT_FOUND"`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/users/u1", nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		rec := httptest.NewRecorder()
+		if writeErr := WriteHTTPError(rec, req, err); writeErr != nil {
+			t.Fatal(writeErr)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Accept %q: status = %d", tc.accept, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != tc.contentType {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", tc.accept, got, tc.contentType)
+		}
+		if !strings.Contains(rec.Body.String(), tc.body) {
+			t.Errorf("Accept %q: body %s should contain %s", tc.accept, rec.Body.String(), tc.body)
+		}
+		if rec.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: Vary = %q", tc.accept, rec.Header().Get("Vary"))
+		}
+	}
+}
+
+// TestWriteHTTPErrorInputs tests collections, foreign errors, request IDs and HEAD requests
+func TestWriteHTTPErrorInputs(t *testing.T) {
+	t.Run("Collection", func(t *testing.T) {
+		collection := NewValidationErrorCollection()
+		collection.AddValidation("email", "must be valid")
+		rec := httptest.NewRecorder()
+		_ = WriteHTTPError(rec, httptest.NewRequest(http.MethodPost, "/", nil), fmt.Errorf("create: %w", collection))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"validation_errors"`) {
+			t.Errorf("Response = %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Foreign errors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		_ = WriteHTTPError(rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("dial tcp 10.0.0.5:5432: refused"))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("Status = %d", rec.Code)
+		}
+
+		rec = httptest.NewRecorder()
+		_ = WriteHTTPError(rec, nil, fmt.Errorf("lookup: %w", ErrNotFound))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Wrapped sentinel status = %d", rec.Code)
+		}
+	})
+
+	t.Run("Request ID from context", func(t *testing.T) {
+		err := NewInternalError("db", nil)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), "request_id", "req-ctx"))
+		rec := httptest.NewRecorder()
+		_ = WriteHTTPError(rec, req, err)
+		if !strings.Contains(rec.Body.String(), `"request_id":"req-ctx"`) {
+			t.Errorf("Body = %s", rec.Body.String())
+		}
+		if err.RequestID != "" {
+			t.Error("The caller's error should not be modified")
+		}
+	})
+
+	t.Run("HEAD", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		_ = WriteHTTPError(rec, httptest.NewRequest(http.MethodHead, "/", nil), NewForbiddenError("read", "doc"))
+		if rec.Code != http.StatusForbidden || rec.Body.Len() != 0 || rec.Header().Get("Content-Length") == "0" {
+			t.Errorf("Response = %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+		}
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		if err := WriteHTTPError(rec, nil, nil); err != nil || rec.Body.Len() != 0 {
+			t.Errorf("Nil error should write nothing, got %q", rec.Body.String())
+		}
+	})
+}
+
+// TestFromError tests the conversion of any error to a CustomError
+func TestFromError(t *testing.T) {
+	customErr := NewForbiddenError("read", "doc")
+	if FromError(fmt.Errorf("handler: %w", customErr)) != customErr || FromError(nil) != nil {
+		t.Error("A CustomError in the chain should be returned as is")
+	}
+
+	collection := NewErrorCollection("batch failed")
+	collection.Add(NewTimeoutError("query", nil))
+	if converted := FromError(collection); converted == nil || converted.Message != collection.ToCustomError().Message || !errors.Is(converted, ErrTimeout) {
+		t.Errorf("Collections should use ToCustomError, got %+v", converted)
+	}
+
+	foreign := fmt.Errorf("query token='s3cr3t' failed: %w", ErrNotFound)
+	converted := FromError(foreign)
+	if !errors.Is(converted, ErrNotFound) || converted.Message != ErrNotFound.Error() || converted.Unwrap() != foreign {
+		t.Errorf("Wrapped sentinels should keep their sentinel and message, got %+v", converted)
+	}
+	if converted := FromError(errors.New("not found: s3cr3t")); converted.Category != ErrorCategoryInternal || strings.Contains(converted.Message, "s3cr3t") {
+		t.Errorf("Plain errors should be internal errors, got %+v", converted)
+	}
+}
+
+// TestWriteHTTPErrorProductionMode tests that no format leaks internal details
+func TestWriteHTTPErrorProductionMode(t *testing.T) {
+	originalConfig := GetConfig()
+	defer SetConfig(originalConfig)
+	SetConfig(&Config{ProductionMode: true})
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{NewInternalError("postgres", errors.New("password authentication failed")).WithMetadata("dsn", "postgres://admin:secret@db"), http.StatusInternalServerError},
+		{errors.New("password authentication failed for postgres://admin:secret@db"), http.StatusInternalServerError},
+		{fmt.Errorf("query postgres SELECT * FROM users WHERE token='secret' failed: %w", ErrNotFound), http.StatusNotFound},
+	}
+	accepts := []string{"application/json", "application/problem+json", "application/vnd.api+json", "application/xml", "text/plain", "text/html"}
+	for _, tc := range cases {
+		for _, accept := range accepts {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", accept)
+			rec := httptest.NewRecorder()
+			_ = WriteHTTPError(rec, req, tc.err)
+
+			body := rec.Body.String()
+			if strings.Contains(body, "secret") || strings.Contains(body, "password") || strings.Contains(body, "postgres") || strings.Contains(body, "SELECT") {
+				t.Errorf("%s leaked internals: %s", accept, body)
+			}
+			if rec.Code != tc.status {
+				t.Errorf("%s status = %d", accept, rec.Code)
+			}
+		}
+	}
+}
+
+// TestResponseFormatterRegistry tests custom formatters and the default format
+func TestResponseFormatterRegistry(t *testing.T) {
+	formatter := NewResponseFormatter("application/vnd.example.error+json", func(w io.Writer, err error) error {
+		var customErr *CustomError
+		if errors.As(err, &customErr) {
+			return json.NewEncoder(w).Encode(map[string]string{"reason": customErr.Code})
+		}
+		return json.NewEncoder(w).Encode(map[string]string{"reason": ERROR_CODE_MULTIPLE_ERRORS})
+	})
+	if err := RegisterResponseFormatter(formatter); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterResponseFormatter("application/vnd.example.error+json")
+
+	if got := NegotiateResponseFormatter("application/vnd.example.error+json"); got != formatter {
+		t.Errorf("Negotiated %s", got.ContentType())
+	}
+
+	if err := SetDefaultResponseFormat("application/vnd.example.error+json"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetDefaultResponseFormat(CONTENT_TYPE_JSON) }()
+
+	rec := httptest.NewRecorder()
+	_ = WriteHTTPError(rec, httptest.NewRequest(http.MethodGet, "/", nil), NewConflictError("user", "email", "a@b.c"))
+	if strings.TrimSpace(rec.Body.String()) != `{"reason":"ALREADY_EXISTS"}` {
+		t.Errorf("Body = %s", rec.Body.String())
+	}
+
+	invalid := []ResponseFormatter{nil, NewResponseFormatter("not a type", nil), NewResponseFormatter("text/*", nil)}
+	for _, f := range invalid {
+		if err := RegisterResponseFormatter(f); !errors.Is(err, ErrInvalidResponseFormatter) {
+			t.Errorf("RegisterResponseFormatter(%v) error = %v", f, err)
+		}
+	}
+	if err := SetDefaultResponseFormat("application/unknown"); !errors.Is(err, ErrInvalidResponseFormatter) {
+		t.Errorf("SetDefaultResponseFormat error = %v", err)
+	}
+}
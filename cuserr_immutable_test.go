@@ -0,0 +1,154 @@
+package cuserr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestFrozenErrors tests copy-on-write behavior of frozen errors
+func TestFrozenErrors(t *testing.T) {
+	prototype := NewCustomError(ErrNotFound, nil, "user not found").
+		WithMetadata("resource", "user").
+		Freeze()
+
+	t.Run("With methods return copies", func(t *testing.T) {
+		derived := prototype.WithMetadata("resource_id", "usr_1").WithRequestID("req-1")
+
+		if derived == prototype {
+			t.Fatal("Frozen error should not be modified in place")
+		}
+		if _, exists := prototype.GetMetadata("resource_id"); exists {
+			t.Error("Prototype metadata should be unchanged")
+		}
+		if prototype.RequestID != "" {
+			t.Error("Prototype request ID should be unchanged")
+		}
+		if id, _ := derived.GetMetadata("resource_id"); id != "usr_1" {
+			t.Errorf("resource_id = %q", id)
+		}
+		if resource, _ := derived.GetMetadata("resource"); resource != "user" {
+			t.Error("Derived copy should keep existing metadata")
+		}
+		if derived.RequestID != "req-1" {
+			t.Errorf("RequestID = %q", derived.RequestID)
+		}
+		if !derived.IsFrozen() {
+			t.Error("Copies of frozen errors should stay frozen")
+		}
+		if !errors.Is(derived, ErrNotFound) {
+			t.Error("Derived copy should keep its sentinel")
+		}
+	})
+
+	t.Run("Code and message derivations", func(t *testing.T) {
+		derived := prototype.WithCode("USER_NOT_FOUND").WithMessagef("user %s not found", "usr_2")
+
+		if prototype.Code != ERROR_CODE_NOT_FOUND || prototype.Message != "user not found" {
+			t.Error("Prototype should be unchanged")
+		}
+		if derived.Code != "USER_NOT_FOUND" || derived.Message != "user usr_2 not found" {
+			t.Errorf("Derived = %s/%s", derived.Code, derived.Message)
+		}
+	})
+
+	t.Run("Stack trace operations", func(t *testing.T) {
+		cleared := prototype.ClearStackTrace()
+		if cleared == prototype {
+			t.Error("ClearStackTrace should return a copy")
+		}
+		if len(prototype.GetStackTrace()) == 0 {
+			t.Error("Prototype stack trace should be unchanged")
+		}
+	})
+
+	t.Run("Typed metadata follows copies", func(t *testing.T) {
+		tm := prototype.GetTypedMetadata().WithStatusCode(404).WithUserID("usr_3")
+		if _, exists := prototype.GetMetadata(MetaStatusCode); exists {
+			t.Error("Prototype should be unchanged")
+		}
+		if status, _ := tm.GetStatusCode(); status != 404 {
+			t.Errorf("status_code = %d", status)
+		}
+		if userID, _ := KeyUserID.Get(tm.Error()); userID != "usr_3" {
+			t.Errorf("user_id = %q", userID)
+		}
+	})
+}
+
+// TestCloneAndMutableDerivations tests Clone and in-place updates of mutable errors
+func TestCloneAndMutableDerivations(t *testing.T) {
+	original := NewValidationError("email", "invalid").WithRequestID("req-1")
+
+	clone := original.Clone()
+	clone.WithMetadata("field", "phone")
+	if field, _ := original.GetMetadata("field"); field != "email" {
+		t.Error("Clone metadata should be independent")
+	}
+	if clone.IsFrozen() {
+		t.Error("Clones should be mutable")
+	}
+	if clone.RequestID != "req-1" || clone.Code != original.Code {
+		t.Error("Clone should copy fields")
+	}
+
+	if got := original.WithCode("EMAIL_INVALID"); got != original || original.Code != "EMAIL_INVALID" {
+		t.Error("Mutable errors should be updated in place")
+	}
+
+	frozenClone := original.Freeze().Clone()
+	if frozenClone.IsFrozen() {
+		t.Error("Clone of a frozen error should be mutable")
+	}
+}
+
+// TestFrozenErrorConcurrentEnrichment tests sharing a frozen prototype across goroutines
+func TestFrozenErrorConcurrentEnrichment(t *testing.T) {
+	prototype := NewCustomError(ErrTimeout, nil, "upstream timeout").Freeze()
+
+	const numGoroutines = 50
+	var wg sync.WaitGroup
+	results := make([]*CustomError, numGoroutines)
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			results[id] = prototype.
+				WithRequestID(fmt.Sprintf("req-%d", id)).
+				WithMetadataValue("attempt", id).
+				WithMessagef("upstream timeout (attempt %d)", id)
+			_ = prototype.ToJSON()
+			_ = prototype.Error()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if result.RequestID != fmt.Sprintf("req-%d", i) {
+			t.Errorf("Result %d has RequestID %q", i, result.RequestID)
+		}
+	}
+	if len(prototype.GetAllMetadata()) != 0 || prototype.RequestID != "" {
+		t.Error("Prototype should remain unchanged")
+	}
+}
+
+// TestEnrichFromContextFrozen tests that context enrichment applies to frozen errors
+func TestEnrichFromContextFrozen(t *testing.T) {
+	ctx := context.WithValue(ContextWithRequestID(context.Background(), "req-frozen"), "user_id", "u1")
+	prototype := NewCustomError(ErrNotFound, nil, "user not found").Freeze()
+
+	enriched := enrichFromContext(ctx, prototype)
+	if enriched.RequestID != "req-frozen" {
+		t.Errorf("RequestID = %q", enriched.RequestID)
+	}
+	if userID, _ := enriched.GetMetadata(MetaUserID); userID != "u1" {
+		t.Errorf("user_id = %q", userID)
+	}
+	if prototype.RequestID != "" || len(prototype.GetAllMetadata()) != 0 {
+		t.Error("Prototype should remain unchanged")
+	}
+}
@@ -250,7 +250,7 @@ func TestHTTPProductionFiltering(t *testing.T) {
 	}
 
 	// Should contain all metadata in development
-	devMetadata := devErrorData[JSON_FIELD_METADATA].(map[string]string)
+	devMetadata := devErrorData[JSON_FIELD_METADATA].(map[string]interface{})
 	if len(devMetadata) != 2 {
 		t.Errorf("Development mode should contain all metadata, got %d items", len(devMetadata))
 	}
@@ -270,7 +270,7 @@ func TestHTTPProductionFiltering(t *testing.T) {
 	}
 
 	// Should filter sensitive metadata in production
-	prodMetadata, hasMetadata := prodErrorData[JSON_FIELD_METADATA].(map[string]string)
+	prodMetadata, hasMetadata := prodErrorData[JSON_FIELD_METADATA].(map[string]interface{})
 	if !hasMetadata || len(prodMetadata) != 1 {
 		t.Error("Production mode should filter sensitive metadata, keeping only safe identifiers")
 	}
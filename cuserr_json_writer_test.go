@@ -0,0 +1,152 @@
+package cuserr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// jsonWriterTestError builds an error exercising every metadata type the writer handles
+func jsonWriterTestError() *CustomError {
+	return NewInternalError("db", fmt.Errorf("replica: %w", NewTimeoutError("query", nil))).
+		WithCauses(errors.New("second <cause> & more")).
+		WithRequestID("req-\"quoted\"").
+		WithMessage("line one\nline \"two\"\t<tab> \u2028 \x01 \xff").
+		WithMetadataValue("count", 3).
+		WithMetadataValue("ratio", 0.25).
+		WithMetadataValue("tiny", 1e-7).
+		WithMetadataValue("huge", float32(3e21)).
+		WithMetadataValue("ok", true).
+		WithMetadataValue("tags", []string{"a", "b\"c"}).
+		WithMetadataValue("seen_at", time.Date(2024, 5, 1, 12, 0, 0, 123, time.UTC)).
+		WithMetadataValue("elapsed", 1500*time.Millisecond).
+		WithMetadataValue("nested", map[string]interface{}{"z": 1, "a": []interface{}{"x", 2.5}, "empty": map[string]interface{}{}}).
+		WithMetadataValue("point", struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+		}{1, 2}).
+		WithRetryAfter(1500 * time.Millisecond)
+}
+
+// TestWriteJSONMatchesEncodingJSON tests that WriteJSON is byte-identical to encoding/json
+func TestWriteJSONMatchesEncodingJSON(t *testing.T) {
+	err := jsonWriterTestError()
+
+	collection := NewErrorCollection("request failed").WithRequestID("req-2")
+	collection.Add(err).Add(NewNotFoundError("user", "42"))
+	collection.AddValidationWithCode("email", "must contain \"@\"", "INVALID_EMAIL")
+	collection.WithContext("tenant", "acme")
+
+	cases := []struct {
+		name    string
+		write   func(*bytes.Buffer, ...JSONOption) error
+		toJSON  func() map[string]interface{}
+		indents []string
+	}{
+		{"CustomError", func(b *bytes.Buffer, opts ...JSONOption) error { return err.WriteJSON(b, opts...) }, err.ToJSON, nil},
+		{"ErrorCollection", func(b *bytes.Buffer, opts ...JSONOption) error { return collection.WriteJSON(b, opts...) }, collection.ToJSON, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, indent := range []string{"", "  ", "\t"} {
+				var got bytes.Buffer
+				var opts []JSONOption
+				var want []byte
+				if indent == "" {
+					want, _ = json.Marshal(tc.toJSON())
+				} else {
+					opts = append(opts, WithJSONIndent(indent))
+					want, _ = json.MarshalIndent(tc.toJSON(), "", indent)
+				}
+				if writeErr := tc.write(&got, opts...); writeErr != nil {
+					t.Fatalf("WriteJSON failed: %v", writeErr)
+				}
+				if got.String() != string(want) {
+					t.Errorf("indent %q:\n got: %s\nwant: %s", indent, got.String(), want)
+				}
+			}
+		})
+	}
+
+	t.Run("ToJSONString and MarshalJSON", func(t *testing.T) {
+		want, _ := json.Marshal(err.ToJSON())
+		if err.ToJSONString() != string(want) {
+			t.Errorf("ToJSONString = %s", err.ToJSONString())
+		}
+		data, marshalErr := json.Marshal(collection)
+		want, _ = json.Marshal(collection.ToJSON())
+		if marshalErr != nil || string(data) != string(want) {
+			t.Errorf("MarshalJSON = %s, %v", data, marshalErr)
+		}
+	})
+}
+
+// TestToJSONStringEscaping tests that ToJSONString always produces valid JSON
+func TestToJSONStringEscaping(t *testing.T) {
+	err := NewValidationError("name", "quote \" backslash \\ newline \n control \x00 bad utf8 \xc3").
+		WithMetadata("key \"with\" quotes", "value\r\n")
+
+	var decoded map[string]map[string]interface{}
+	if jsonErr := json.Unmarshal([]byte(err.ToJSONString()), &decoded); jsonErr != nil {
+		t.Fatalf("ToJSONString produced invalid JSON: %v\n%s", jsonErr, err.ToJSONString())
+	}
+	if msg := decoded[JSON_FIELD_ERROR][JSON_FIELD_MESSAGE]; msg != "quote \" backslash \\ newline \n control \x00 bad utf8 \ufffd" {
+		t.Errorf("message = %q", msg)
+	}
+
+	first := err.ToJSONString()
+	for i := 0; i < 20; i++ {
+		if err.ToJSONString() != first {
+			t.Fatal("ToJSONString output should be deterministic")
+		}
+	}
+}
+
+// TestWriteJSONEdgeCases tests non-finite floats and writer failures
+func TestWriteJSONEdgeCases(t *testing.T) {
+	t.Run("Non-finite floats", func(t *testing.T) {
+		err := NewInternalError("math", nil).WithMetadataValue("value", 0.0).
+			WithMetadataValue("nan", func() float64 { zero := 0.0; return zero / zero }())
+		var decoded struct {
+			Error struct {
+				Metadata map[string]interface{} `json:"metadata"`
+			} `json:"error"`
+		}
+		if jsonErr := json.Unmarshal([]byte(err.ToJSONString()), &decoded); jsonErr != nil {
+			t.Fatalf("Invalid JSON: %v", jsonErr)
+		}
+		if decoded.Error.Metadata["nan"] != "NaN" {
+			t.Errorf("nan = %#v", decoded.Error.Metadata["nan"])
+		}
+	})
+
+	t.Run("Writer errors", func(t *testing.T) {
+		if err := NewNotFoundError("user", "1").WriteJSON(failingWriter{}); !errors.Is(err, errWriteFailed) {
+			t.Errorf("Expected write error, got %v", err)
+		}
+	})
+
+	t.Run("Large output", func(t *testing.T) {
+		err := NewInternalError("bulk", nil).WithMetadata("blob", strings.Repeat("x", JSON_WRITER_MAX_POOLED_BUFFER))
+		var buf bytes.Buffer
+		if writeErr := err.WriteJSON(&buf); writeErr != nil || !json.Valid(buf.Bytes()) {
+			t.Errorf("Large output failed: %v", writeErr)
+		}
+	})
+}
+
+// errWriteFailed is returned by failingWriter
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter is an io.Writer that always fails
+type failingWriter struct{}
+
+// Write always returns errWriteFailed
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWriteFailed
+}
@@ -0,0 +1,150 @@
+package cuserr
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestJSONAPIRendering tests JSON:API error documents for CustomError and ErrorCollection
+func TestJSONAPIRendering(t *testing.T) {
+	t.Run("Single error", func(t *testing.T) {
+		doc := NewValidationError("profile.email", "invalid format").WithRequestID("req-1").ToJSONAPI()
+		if len(doc.Errors) != 1 {
+			t.Fatalf("Errors = %+v", doc.Errors)
+		}
+		entry := doc.Errors[0]
+		if entry.ID != "" || entry.Meta[JSON_FIELD_REQUEST_ID] != "req-1" || entry.Status != "400" || entry.Code != ERROR_CODE_INVALID_INPUT || entry.Title != "Bad Request" {
+			t.Errorf("Entry = %+v", entry)
+		}
+		if entry.Source == nil || entry.Source.Pointer != "/data/attributes/profile/email" {
+			t.Errorf("Source = %+v", entry.Source)
+		}
+		if entry.Meta[MetaField] != "profile.email" {
+			t.Errorf("Meta = %v", entry.Meta)
+		}
+	})
+
+	t.Run("Query parameters", func(t *testing.T) {
+		doc := NewValidationError("", "unknown sort field").WithMetadata(MetaParameter, "sort").ToJSONAPI()
+		if source := doc.Errors[0].Source; source == nil || source.Parameter != "sort" || source.Pointer != "" {
+			t.Errorf("Source = %+v", source)
+		}
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		collection := NewValidationErrorCollection().WithRequestID("req-2")
+		collection.AddValidationWithCode("email", "must be valid", "INVALID_EMAIL")
+		collection.AddValidationWithValue("age", "must be positive", "-1")
+		collection.Add(NewNotFoundError("team", "t1"))
+
+		data, err := json.Marshal(collection.ToJSONAPI())
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		body := string(data)
+		for _, want := range []string{
+			`{"status":"400","code":"INVALID_EMAIL","title":"Bad Request","detail":"must be valid","source":{"pointer":"/data/attributes/email"}}`,
+			`"meta":{"value":"-1"}`,
+			`"status":"404","code":"NOT_FOUND"`,
+			`"meta":{"request_id":"req-2","summary":"validation failed"}`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Body missing %s:\n%s", want, body)
+			}
+		}
+	})
+}
+
+// TestJSONAPIProductionMode tests meta filtering in production mode
+func TestJSONAPIProductionMode(t *testing.T) {
+	originalConfig := GetConfig()
+	defer SetConfig(originalConfig)
+	SetConfig(&Config{ProductionMode: true})
+
+	internal := NewInternalError("db", nil).WithMetadata("query", "SELECT secret").WithMetadata(MetaUserID, "usr_1")
+	entry := internal.ToJSONAPI().Errors[0]
+	if entry.Detail != internal.ClientSafeMessage() || entry.Detail == internal.Message {
+		t.Errorf("Detail = %q", entry.Detail)
+	}
+	if _, leaked := entry.Meta["query"]; leaked || entry.Meta[MetaUserID] != "usr_1" {
+		t.Errorf("Meta = %v", entry.Meta)
+	}
+
+	collection := NewValidationErrorCollection()
+	collection.AddValidationWithValue("password", "too short", "hunter2")
+	if meta := collection.ToJSONAPI().Errors[0].Meta; meta != nil {
+		t.Errorf("Rejected values should be omitted in production, got %v", meta)
+	}
+}
+
+// TestParseJSONAPIErrors tests decoding JSON:API error documents
+func TestParseJSONAPIErrors(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		original := NewValidationErrorCollection().WithRequestID("req-3")
+		original.AddValidationWithCode("items[0].sku", "unknown", "UNKNOWN_SKU")
+		original.AddValidationWithValue("qty", "must be positive", "0")
+		original.Add(NewConflictError("order", "o1", "already paid").WithMetadataValue("version", 7).WithRequestID("req-3"))
+		original.Add(NewTimeoutError("inventory", nil).WithRequestID("req-3"))
+
+		data, _ := json.Marshal(original.ToJSONAPI())
+		parsed, err := ParseJSONAPIErrors(data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if parsed.RequestID != "req-3" || parsed.Summary != "validation failed" {
+			t.Errorf("Collection = %q/%q", parsed.RequestID, parsed.Summary)
+		}
+		if len(parsed.ValidationErrors) != 2 || parsed.ValidationErrors[0].Field != "items[0].sku" ||
+			parsed.ValidationErrors[0].Code != "UNKNOWN_SKU" || parsed.ValidationErrors[1].Value != "0" {
+			t.Errorf("ValidationErrors = %+v", parsed.ValidationErrors)
+		}
+		if len(parsed.Errors) != 2 || !errors.Is(parsed.Errors[0], ErrAlreadyExists) {
+			t.Fatalf("Errors = %v", parsed.Errors)
+		}
+		for _, member := range parsed.Errors {
+			if _, exists := member.GetMetadata(JSON_FIELD_REQUEST_ID); member.RequestID != "req-3" || exists {
+				t.Errorf("RequestID = %q, metadata = %v", member.RequestID, member.GetAllMetadata())
+			}
+		}
+		if body := string(data); strings.Contains(body, `"id":`) {
+			t.Errorf("Members should not share the request ID as their id: %s", body)
+		}
+		if version, _ := parsed.Errors[0].GetMetadataValue("version"); version != int64(7) {
+			t.Errorf("version = %#v", version)
+		}
+	})
+
+	t.Run("Foreign document", func(t *testing.T) {
+		body := `{"errors":[
+			{"id":"e1","status":"422","title":"Invalid Attribute","detail":"First name must contain at least two characters.",
+			 "source":{"pointer":"/data/attributes/firstName"}},
+			{"status":"400","source":{"parameter":"include"},"title":"Invalid Query Parameter"},
+			{"status":"503","code":"DB_DOWN"}
+		]}`
+		parsed, err := ParseJSONAPIErrors([]byte(body))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(parsed.Errors) != 3 {
+			t.Fatalf("Errors = %v", parsed.Errors)
+		}
+		first := parsed.Errors[0]
+		if first.Category != ErrorCategoryUnprocessable || first.RequestID != "" {
+			t.Errorf("First = %s/%s", first.Category, first.RequestID)
+		}
+		if field, _ := first.GetMetadata(MetaField); field != "firstName" {
+			t.Errorf("field = %q", field)
+		}
+		if parameter, _ := parsed.Errors[1].GetMetadata(MetaParameter); parameter != "include" || parsed.Errors[1].Message != "Invalid Query Parameter" {
+			t.Errorf("Second = %v", parsed.Errors[1])
+		}
+		if third := parsed.Errors[2]; third.Code != "DB_DOWN" || !IsRetryable(third) {
+			t.Errorf("Third = %s retryable=%v", third.Code, IsRetryable(third))
+		}
+		if _, err := ParseJSONAPIErrors([]byte(`{"errors":`)); err == nil {
+			t.Error("Invalid JSON should fail")
+		}
+	})
+}
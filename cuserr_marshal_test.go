@@ -0,0 +1,138 @@
+package cuserr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+// TestCustomErrorJSONRoundTrip tests lossless MarshalJSON and UnmarshalJSON
+func TestCustomErrorJSONRoundTrip(t *testing.T) {
+	inner := NewTimeoutError("query", nil).WithMetadataValue(MetaAttempt, 3)
+	foreign := fmt.Errorf("replica b: %w", &fs.PathError{Op: "write", Path: "/data/b", Err: fs.ErrPermission})
+
+	original := NewInternalError("db", nil).
+		WithCauses(inner, foreign).
+		WithRequestID("req-1").
+		WithMetadataValue("tables", []string{"users", "orders"}).
+		WithMetadataValue("ratio", 0.25).
+		WithSeverity(SeverityCritical).
+		WithRetryAfter(1500 * time.Millisecond)
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded *CustomError
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	t.Run("Fields", func(t *testing.T) {
+		if decoded.Category != original.Category || decoded.Code != original.Code || decoded.Message != original.Message {
+			t.Errorf("Decoded = %s/%s/%s", decoded.Category, decoded.Code, decoded.Message)
+		}
+		if decoded.RequestID != "req-1" || !decoded.Timestamp.Equal(original.Timestamp) {
+			t.Errorf("RequestID = %q, Timestamp = %v", decoded.RequestID, decoded.Timestamp)
+		}
+		if decoded.Severity() != SeverityCritical {
+			t.Errorf("Severity = %v", decoded.Severity())
+		}
+		if delay, _ := decoded.RetryAfter(); delay != 1500*time.Millisecond {
+			t.Errorf("RetryAfter = %v", delay)
+		}
+		if len(decoded.GetStackTrace()) == 0 || decoded.GetStackTrace()[0] != original.GetStackTrace()[0] {
+			t.Error("Stack frames should be preserved")
+		}
+	})
+
+	t.Run("Metadata types", func(t *testing.T) {
+		if value, _ := decoded.GetMetadataValue("ratio"); value != 0.25 {
+			t.Errorf("ratio = %#v", value)
+		}
+		if tables, _ := decoded.GetMetadata("tables"); tables != "users,orders" {
+			t.Errorf("tables = %q", tables)
+		}
+		if component, _ := decoded.GetMetadata(MetaComponent); component != "db" {
+			t.Errorf("component = %q", component)
+		}
+	})
+
+	t.Run("Cause chain", func(t *testing.T) {
+		if decoded.Error() != original.Error() {
+			t.Errorf("Error() = %q, want %q", decoded.Error(), original.Error())
+		}
+		causes := decoded.Causes()
+		if len(causes) != 2 {
+			t.Fatalf("Causes = %v", causes)
+		}
+		if attempt, _ := KeyAttempt.Get(causes[0]); attempt != 3 {
+			t.Errorf("Nested attempt = %d", attempt)
+		}
+		if causes[1].Error() != foreign.Error() {
+			t.Errorf("Foreign cause = %q", causes[1].Error())
+		}
+	})
+
+	t.Run("Sentinels", func(t *testing.T) {
+		if !errors.Is(decoded, ErrInternal) || !errors.Is(decoded, ErrTimeout) {
+			t.Error("errors.Is should match registered sentinels after decoding")
+		}
+		if errors.Is(decoded, fs.ErrPermission) {
+			t.Error("Foreign sentinels cannot be restored")
+		}
+		var customErr *CustomError
+		if !errors.As(decoded.Causes()[0], &customErr) || customErr.Code != ERROR_CODE_TIMEOUT {
+			t.Error("Nested CustomErrors should decode as CustomErrors")
+		}
+	})
+
+	t.Run("Definitions and unregistered codes", func(t *testing.T) {
+		data, _ := json.Marshal(errTestUserSuspended.New(nil, Arg("user_id", "usr_1")))
+		var fromDefinition CustomError
+		if err := json.Unmarshal(data, &fromDefinition); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if !errors.Is(&fromDefinition, errTestUserSuspended) {
+			t.Error("errors.Is should match the definition after decoding")
+		}
+
+		var unknown CustomError
+		if err := json.Unmarshal([]byte(`{"category":"internal","code":"SOMETHING_ELSE","message":"x"}`), &unknown); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if unknown.Sentinel != nil || errors.Is(&unknown, ErrInternal) {
+			t.Error("Unregistered codes should decode without a sentinel")
+		}
+	})
+
+	t.Run("Re-coded errors and frozen errors", func(t *testing.T) {
+		original := NewForbiddenError("login", "account").WithCode("USER_BANNED").Freeze()
+		data, _ := json.Marshal(original)
+
+		var recoded *CustomError
+		if err := json.Unmarshal(data, &recoded); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if recoded.Code != "USER_BANNED" || !errors.Is(recoded, ErrForbidden) {
+			t.Errorf("Decoded = %s, Is(ErrForbidden) = %v", recoded.Code, errors.Is(recoded, ErrForbidden))
+		}
+		if !recoded.IsFrozen() || recoded.WithRequestID("req-2") == recoded {
+			t.Error("Frozen errors should decode frozen")
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		var target CustomError
+		if err := json.Unmarshal([]byte(`{"code":"X","severity":"loud"}`), &target); !errors.Is(err, ErrInvalidSeverity) {
+			t.Errorf("Expected ErrInvalidSeverity, got %v", err)
+		}
+		if err := json.Unmarshal([]byte(`{"code":"X","retry_after":"soon"}`), &target); err == nil {
+			t.Error("Invalid retry_after should fail")
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package cuserr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testMoney is a domain type stored through a custom codec
+type testMoney struct {
+	Cents    int64
+	Currency string
+}
+
+var (
+	keyTestOrderID = NewKey[string]("order_id")
+	keyTestSKUs    = NewKey[[]string]("skus")
+	keyTestAmount  = NewKey[testMoney]("amount", WithCodec(NewCodec(
+		func(m testMoney) interface{} {
+			return fmt.Sprintf("%d.%02d %s", m.Cents/100, m.Cents%100, m.Currency)
+		},
+		func(stored interface{}) (testMoney, error) {
+			s, ok := stored.(string)
+			if !ok {
+				return testMoney{}, ErrMetadataDecode
+			}
+			var whole, fraction int64
+			var currency string
+			if _, err := fmt.Sscanf(s, "%d.%d %s", &whole, &fraction, &currency); err != nil {
+				return testMoney{}, err
+			}
+			return testMoney{Cents: whole*100 + fraction, Currency: currency}, nil
+		},
+	)))
+)
+
+// TestMetadataKeys tests generic typed metadata keys
+func TestMetadataKeys(t *testing.T) {
+	err := NewInternalError("checkout", nil)
+	keyTestOrderID.Set(err, "ord_123")
+	keyTestSKUs.Set(err, []string{"sku-1", "sku-2"})
+	keyTestAmount.Set(err, testMoney{Cents: 1999, Currency: "EUR"})
+	KeyStatusCode.Set(err, 503)
+
+	t.Run("Round trip", func(t *testing.T) {
+		if orderID, ok := keyTestOrderID.Get(err); !ok || orderID != "ord_123" {
+			t.Errorf("order_id = %q, %v", orderID, ok)
+		}
+		if skus, ok := keyTestSKUs.Get(err); !ok || len(skus) != 2 || skus[1] != "sku-2" {
+			t.Errorf("skus = %v, %v", skus, ok)
+		}
+		if amount, ok := keyTestAmount.Get(err); !ok || amount.Cents != 1999 || amount.Currency != "EUR" {
+			t.Errorf("amount = %+v, %v", amount, ok)
+		}
+		if status, ok := KeyStatusCode.Get(err); !ok || status != 503 {
+			t.Errorf("status_code = %d, %v", status, ok)
+		}
+	})
+
+	t.Run("Codec controls stored representation", func(t *testing.T) {
+		if stored, _ := err.GetMetadataValue("amount"); stored != "19.99 EUR" {
+			t.Errorf("Stored amount = %#v", stored)
+		}
+	})
+
+	t.Run("Get through wrapped errors", func(t *testing.T) {
+		wrapped := fmt.Errorf("placing order: %w", err)
+		if orderID, ok := keyTestOrderID.Get(wrapped); !ok || orderID != "ord_123" {
+			t.Errorf("order_id = %q, %v", orderID, ok)
+		}
+	})
+
+	t.Run("Missing and mismatched values", func(t *testing.T) {
+		if _, lookupErr := KeyAttempt.Lookup(err); !errors.Is(lookupErr, ErrMetadataNotFound) {
+			t.Errorf("Expected ErrMetadataNotFound, got %v", lookupErr)
+		}
+		if _, ok := KeyAttempt.Get(errors.New("plain")); ok {
+			t.Error("Plain errors have no metadata")
+		}
+
+		err.WithMetadata(MetaAttempt, "not a number")
+		if _, lookupErr := KeyAttempt.Lookup(err); !errors.Is(lookupErr, ErrMetadataDecode) {
+			t.Errorf("Expected ErrMetadataDecode, got %v", lookupErr)
+		}
+	})
+
+	t.Run("Legacy string values decode", func(t *testing.T) {
+		legacy := NewInternalError("legacy", nil).
+			WithMetadata(MetaStatusCode, "404").
+			WithMetadata(MetaCPUUsage, "0.75")
+
+		if status, ok := KeyStatusCode.Get(legacy); !ok || status != 404 {
+			t.Errorf("status_code = %d, %v", status, ok)
+		}
+		if cpu, ok := KeyCPUUsage.Get(legacy); !ok || cpu != 0.75 {
+			t.Errorf("cpu_usage = %v, %v", cpu, ok)
+		}
+	})
+
+	t.Run("JSON decoded values", func(t *testing.T) {
+		var decoded map[string]interface{}
+		if jsonErr := json.Unmarshal([]byte(`{"n": 42, "d": 1500000000, "tags": ["x"]}`), &decoded); jsonErr != nil {
+			t.Fatal(jsonErr)
+		}
+		remote := NewInternalError("remote", nil)
+		for k, v := range decoded {
+			remote.WithMetadataValue(k, v)
+		}
+
+		if n, ok := NewKey[int]("n").Get(remote); !ok || n != 42 {
+			t.Errorf("n = %d, %v", n, ok)
+		}
+		if d, ok := NewKey[time.Duration]("d").Get(remote); !ok || d != 1500*time.Millisecond {
+			t.Errorf("d = %v, %v", d, ok)
+		}
+		if tags, ok := NewKey[[]string]("tags").Get(remote); !ok || tags[0] != "x" {
+			t.Errorf("tags = %v, %v", tags, ok)
+		}
+	})
+
+	t.Run("Definition arguments", func(t *testing.T) {
+		def := Define("TEST_KEY_ARG", ErrorCategoryValidation, "order {order_id} costs {amount}")
+		t.Cleanup(func() { UnregisterSentinel(def) })
+
+		created := def.New(context.Background(), keyTestOrderID.Arg("ord_9"), keyTestAmount.Arg(testMoney{Cents: 500, Currency: "USD"}))
+		if created.Message != "order ord_9 costs 5.00 USD" {
+			t.Errorf("Message = %q", created.Message)
+		}
+		if amount, ok := keyTestAmount.Get(created); !ok || amount.Cents != 500 {
+			t.Errorf("amount = %+v, %v", amount, ok)
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if keyTestOrderID.Set(nil, "x") != nil {
+			t.Error("Set on nil should return nil")
+		}
+	})
+}
+
+// TestTypedMetadataUsesKeys tests that TypedMetadata and built-in keys share storage
+func TestTypedMetadataUsesKeys(t *testing.T) {
+	err := NewInternalError("svc", nil)
+	err.GetTypedMetadata().WithMemoryUsage(2048).WithTenantID("tenant-1")
+
+	if bytes, ok := KeyMemoryUsage.Get(err); !ok || bytes != 2048 {
+		t.Errorf("memory_usage = %d, %v", bytes, ok)
+	}
+	KeyQueueSize.Set(err, 7)
+	if got, _ := err.GetMetadata(MetaQueueSize); got != "7" {
+		t.Errorf("queue_size = %q", got)
+	}
+	if tenant, _ := err.GetTypedMetadata().GetTenantID(); tenant != "tenant-1" {
+		t.Errorf("tenant_id = %q", tenant)
+	}
+}
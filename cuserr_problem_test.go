@@ -0,0 +1,192 @@
+package cuserr
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProblemDetailsRendering tests RFC 9457 output for CustomError and ErrorCollection
+func TestProblemDetailsRendering(t *testing.T) {
+	t.Run("Default type and title", func(t *testing.T) {
+		pd := NewNotFoundError("user", "42").WithRequestID("req-1").ToProblemDetails()
+		if pd.Type != PROBLEM_TYPE_DEFAULT || pd.Title != "Not Found" || pd.Status != 404 {
+			t.Errorf("Problem = %+v", pd)
+		}
+		if pd.Instance != "urn:request:req-1" || pd.Code != ERROR_CODE_NOT_FOUND || pd.RequestID != "req-1" {
+			t.Errorf("Problem = %+v", pd)
+		}
+	})
+
+	t.Run("Type URIs", func(t *testing.T) {
+		if err := SetProblemTypeBase("https://errors.example.com/"); err != nil {
+			t.Fatalf("SetProblemTypeBase failed: %v", err)
+		}
+		defer func() { _ = SetProblemTypeBase("") }()
+		if err := RegisterProblemType(ERROR_CODE_RATE_LIMIT, ProblemType{URI: "https://docs.example.com/limits", Title: "Slow down"}); err != nil {
+			t.Fatalf("RegisterProblemType failed: %v", err)
+		}
+		defer UnregisterProblemType(ERROR_CODE_RATE_LIMIT)
+
+		if pd := NewNotFoundError("user", "42").ToProblemDetails(); pd.Type != "https://errors.example.com/not-found" {
+			t.Errorf("Type = %q", pd.Type)
+		}
+		pd := NewRateLimitError("100", "1m").ToProblemDetails()
+		if pd.Type != "https://docs.example.com/limits" || pd.Title != "Slow down" {
+			t.Errorf("Problem = %+v", pd)
+		}
+		if err := RegisterProblemType("", ProblemType{URI: "x"}); !errors.Is(err, ErrInvalidProblemType) {
+			t.Errorf("Expected ErrInvalidProblemType, got %v", err)
+		}
+	})
+
+	t.Run("Validation pointers", func(t *testing.T) {
+		pd := NewValidationError("address.lines[1]", "too long").ToProblemDetails()
+		if len(pd.Errors) != 1 || pd.Errors[0].Pointer != "/address/lines/1" || pd.Errors[0].Detail != "too long" {
+			t.Errorf("Errors = %+v", pd.Errors)
+		}
+
+		collection := NewValidationErrorCollection().WithRequestID("req-2")
+		collection.AddValidationWithCode("email", "invalid", "INVALID_EMAIL")
+		collection.AddValidation("a/b~c", "required")
+		collection.Add(NewInternalError("db", nil))
+		pd = collection.ToProblemDetails()
+		if pd.Code != ERROR_CODE_MULTIPLE_ERRORS || pd.Status != 500 || len(pd.Errors) != 3 {
+			t.Fatalf("Problem = %+v", pd)
+		}
+		if pd.Errors[0].Code != "INVALID_EMAIL" || pd.Errors[1].Pointer != "/a~1b~0c" || pd.Errors[2].Pointer != "" {
+			t.Errorf("Errors = %+v", pd.Errors)
+		}
+	})
+
+	t.Run("JSON members", func(t *testing.T) {
+		data, err := json.Marshal(NewValidationError("email", "invalid").WithMetadata("type", "shadowed").ToProblemDetails())
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		body := string(data)
+		if !strings.HasPrefix(body, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid","code":"INVALID_INPUT"`) {
+			t.Errorf("Body = %s", body)
+		}
+		if !strings.Contains(body, `"errors":[{"pointer":"/email","detail":"invalid","code":"INVALID_INPUT"}]`) ||
+			!strings.Contains(body, `"category":"validation"`) || !strings.Contains(body, `"retryable":false`) {
+			t.Errorf("Body = %s", body)
+		}
+	})
+}
+
+// TestProblemDetailsProductionMode tests redaction of internal details
+func TestProblemDetailsProductionMode(t *testing.T) {
+	originalConfig := GetConfig()
+	defer SetConfig(originalConfig)
+	SetConfig(&Config{ProductionMode: true})
+
+	internal := NewInternalError("db", nil).
+		WithMetadata("query", "SELECT secret").
+		WithMetadata(MetaUserID, "usr_1")
+	pd := internal.ToProblemDetails()
+	if pd.Detail == internal.Message || pd.Detail != internal.ClientSafeMessage() {
+		t.Errorf("Detail should be redacted, got %q", pd.Detail)
+	}
+	metadata, _ := pd.Extensions[JSON_FIELD_METADATA].(map[string]interface{})
+	if _, leaked := metadata["query"]; leaked || metadata[MetaUserID] != "usr_1" {
+		t.Errorf("Metadata = %v", metadata)
+	}
+
+	collection := NewErrorCollection("failed").WithContext("db_host", "10.0.0.1")
+	collection.Add(NewInternalError("db", nil))
+	if pd := collection.ToProblemDetails(); pd.Extensions[JSON_FIELD_CONTEXT] != nil {
+		t.Error("Context should be omitted in production mode")
+	}
+}
+
+// TestParseProblemDetails tests turning problem+json back into a CustomError
+func TestParseProblemDetails(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		original := NewRateLimitError("100", "1m").WithRequestID("req-9").WithRetryAfter(30 * time.Second)
+		data, _ := json.Marshal(original.ToProblemDetails())
+
+		parsed, err := ParseProblemDetails(data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if !errors.Is(parsed, ErrRateLimit) || parsed.Code != original.Code || parsed.Message != original.Message {
+			t.Errorf("Parsed = %v", parsed)
+		}
+		if parsed.RequestID != "req-9" {
+			t.Errorf("RequestID = %q", parsed.RequestID)
+		}
+		if delay, _ := parsed.RetryAfter(); delay != 30*time.Second {
+			t.Errorf("RetryAfter = %v", delay)
+		}
+		if instance, _ := parsed.GetMetadata(MetaProblemInstance); instance != "urn:request:req-9" {
+			t.Errorf("instance = %q", instance)
+		}
+	})
+
+	t.Run("Foreign problem", func(t *testing.T) {
+		body := `{"type":"https://example.net/out-of-credit","title":"You do not have enough credit.",
+			"status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc",
+			"balance":30,"accounts":["/account/12345","/account/67890"],
+			"errors":[{"pointer":"/items/0/price","detail":"must be positive"}, "ignored"]}`
+		parsed, err := ParseProblemDetails([]byte(body))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if parsed.Category != ErrorCategoryForbidden || parsed.Message != "Your current balance is 30, but that costs 50." {
+			t.Errorf("Parsed = %s/%s", parsed.Category, parsed.Message)
+		}
+		if balance, _ := parsed.GetMetadataValue("balance"); balance != int64(30) {
+			t.Errorf("balance = %#v", balance)
+		}
+		if problemType, _ := parsed.GetMetadata(MetaProblemType); problemType != "https://example.net/out-of-credit" {
+			t.Errorf("problem_type = %q", problemType)
+		}
+		causes := parsed.Causes()
+		if len(causes) != 1 {
+			t.Fatalf("Causes = %v", causes)
+		}
+		if field, _ := GetErrorMetadata(causes[0], MetaField); field != "items[0].price" {
+			t.Errorf("field = %q", field)
+		}
+	})
+
+	t.Run("Unknown code and wrong member types", func(t *testing.T) {
+		parsed, err := ParseProblemDetails([]byte(`{"status":"502","code":"UPSTREAM_DOWN","title":"Bad Gateway","category":"no_such"}`))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if parsed.Code != "UPSTREAM_DOWN" || parsed.Category != ErrorCategoryInternal || parsed.Message != "Bad Gateway" {
+			t.Errorf("Parsed = %s/%s/%s", parsed.Category, parsed.Code, parsed.Message)
+		}
+		if _, err := ParseProblemDetails([]byte(`not json`)); err == nil {
+			t.Error("Invalid JSON should fail")
+		}
+	})
+}
+
+// TestJSONPointer tests field path and JSON pointer conversion
+func TestJSONPointer(t *testing.T) {
+	cases := map[string]string{
+		"":                   "",
+		"email":              "/email",
+		"address.street":     "/address/street",
+		"items[0].name":      "/items/0/name",
+		"matrix[1][2]":       "/matrix/1/2",
+		"a/b~c":              "/a~1b~0c",
+		"weird[unterminated": "/weird[unterminated",
+	}
+	for field, want := range cases {
+		if got := JSONPointer(field); got != want {
+			t.Errorf("JSONPointer(%q) = %q, want %q", field, got, want)
+		}
+	}
+
+	for _, field := range []string{"email", "address.street", "items[0].name", "matrix[1][2]", "a/b~c"} {
+		if got := FieldFromJSONPointer(JSONPointer(field)); got != field {
+			t.Errorf("FieldFromJSONPointer(JSONPointer(%q)) = %q", field, got)
+		}
+	}
+}
@@ -95,7 +95,7 @@ func TestProductionConfiguration(t *testing.T) {
 
 		clientJSON := err.ToClientJSON()
 		errorData := clientJSON[JSON_FIELD_ERROR].(map[string]interface{})
-		metadata, hasMetadata := errorData[JSON_FIELD_METADATA].(map[string]string)
+		metadata, hasMetadata := errorData[JSON_FIELD_METADATA].(map[string]interface{})
 
 		if !hasMetadata {
 			t.Fatal("Should have metadata even after filtering")
@@ -142,7 +142,7 @@ func TestProductionConfiguration(t *testing.T) {
 		// Should preserve all metadata
 		clientJSON := err.ToClientJSON()
 		errorData := clientJSON[JSON_FIELD_ERROR].(map[string]interface{})
-		metadata := errorData[JSON_FIELD_METADATA].(map[string]string)
+		metadata := errorData[JSON_FIELD_METADATA].(map[string]interface{})
 
 		if len(metadata) != 2 {
 			t.Error("Development mode should preserve all metadata")
@@ -174,7 +174,7 @@ func TestConfigurationTransitions(t *testing.T) {
 		devMessage := err.ClientSafeMessage()
 		devJSON := err.ToClientJSON()
 		devErrorData := devJSON[JSON_FIELD_ERROR].(map[string]interface{})
-		devMetadata := devErrorData[JSON_FIELD_METADATA].(map[string]string)
+		devMetadata := devErrorData[JSON_FIELD_METADATA].(map[string]interface{})
 
 		if devMessage != "sensitive error message" {
 			t.Error("Development mode should show sensitive message")
@@ -193,7 +193,7 @@ func TestConfigurationTransitions(t *testing.T) {
 		prodMessage := err.ClientSafeMessage()
 		prodJSON := err.ToClientJSON()
 		prodErrorData := prodJSON[JSON_FIELD_ERROR].(map[string]interface{})
-		prodMetadata := prodErrorData[JSON_FIELD_METADATA].(map[string]string)
+		prodMetadata := prodErrorData[JSON_FIELD_METADATA].(map[string]interface{})
 
 		if prodMessage != "An internal error occurred" {
 			t.Error("Production mode should show generic message")
@@ -410,7 +410,7 @@ func TestProductionSecurityFiltering(t *testing.T) {
 
 		clientJSON := err.ToClientJSON()
 		errorData := clientJSON[JSON_FIELD_ERROR].(map[string]interface{})
-		metadata, hasMetadata := errorData[JSON_FIELD_METADATA].(map[string]string)
+		metadata, hasMetadata := errorData[JSON_FIELD_METADATA].(map[string]interface{})
 
 		if !hasMetadata {
 			t.Fatal("Should have metadata")
@@ -0,0 +1,304 @@
+package cuserr
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestSentinelRegistry tests custom sentinel registration and lookup
+func TestSentinelRegistry(t *testing.T) {
+	errCardDeclined := errors.New("card declined")
+	if err := RegisterSentinel(errCardDeclined, ErrorCategoryValidation, "CARD_DECLINED"); err != nil {
+		t.Fatalf("RegisterSentinel failed: %v", err)
+	}
+	t.Cleanup(func() { UnregisterSentinel(errCardDeclined) })
+
+	t.Run("Constructor uses registered mapping", func(t *testing.T) {
+		err := NewCustomError(errCardDeclined, nil, "card was declined")
+
+		if err.Category != ErrorCategoryValidation {
+			t.Errorf("Category = %v, want %v", err.Category, ErrorCategoryValidation)
+		}
+		if err.Code != "CARD_DECLINED" {
+			t.Errorf("Code = %v, want CARD_DECLINED", err.Code)
+		}
+		if !errors.Is(err, errCardDeclined) {
+			t.Error("errors.Is should match the registered sentinel")
+		}
+	})
+
+	t.Run("Wrapped sentinel matches", func(t *testing.T) {
+		wrapped := fmt.Errorf("charging order: %w", errCardDeclined)
+		err := NewCustomError(wrapped, nil, "payment failed")
+
+		if err.Code != "CARD_DECLINED" {
+			t.Errorf("Code = %v, want CARD_DECLINED", err.Code)
+		}
+	})
+
+	t.Run("Closest registered sentinel wins", func(t *testing.T) {
+		errPayment := fmt.Errorf("payment rejected: %w", ErrInvalidInput)
+		if err := RegisterSentinel(errPayment, ErrorCategoryConflict, "PAYMENT_REJECTED"); err != nil {
+			t.Fatalf("RegisterSentinel failed: %v", err)
+		}
+		defer UnregisterSentinel(errPayment)
+
+		err := NewCustomError(fmt.Errorf("checkout: %w", errPayment), nil, "checkout failed")
+		if err.Code != "PAYMENT_REJECTED" {
+			t.Errorf("Code = %v, want PAYMENT_REJECTED", err.Code)
+		}
+	})
+
+	t.Run("Unregistered sentinel falls back to internal", func(t *testing.T) {
+		err := NewCustomError(errors.New("unknown"), nil, "boom")
+
+		if err.Category != ErrorCategoryInternal || err.Code != ERROR_CODE_INTERNAL_ERROR {
+			t.Errorf("Expected internal fallback, got %v/%v", err.Category, err.Code)
+		}
+	})
+
+	t.Run("FromStdError consults registry", func(t *testing.T) {
+		err := FromStdError(fmt.Errorf("gateway said: %w", errCardDeclined), "")
+
+		if err.Code != "CARD_DECLINED" {
+			t.Errorf("Code = %v, want CARD_DECLINED", err.Code)
+		}
+	})
+
+	t.Run("ErrorBuilder consults registry", func(t *testing.T) {
+		err := NewErrorBuilder(errCardDeclined).WithMessage("declined").Build()
+
+		if err.Code != "CARD_DECLINED" {
+			t.Errorf("Code = %v, want CARD_DECLINED", err.Code)
+		}
+	})
+
+	t.Run("Lookup by code", func(t *testing.T) {
+		spec, ok := LookupSentinelByCode("CARD_DECLINED")
+		if !ok || spec.Sentinel != errCardDeclined {
+			t.Error("Should find sentinel by code")
+		}
+
+		if _, ok := LookupSentinelByCode("NO_SUCH_CODE"); ok {
+			t.Error("Should not find unknown code")
+		}
+	})
+}
+
+// TestSentinelRegistryValidation tests registration conflicts and invalid input
+func TestSentinelRegistryValidation(t *testing.T) {
+	t.Run("Duplicate code rejected", func(t *testing.T) {
+		errOther := errors.New("other not found")
+		err := RegisterSentinel(errOther, ErrorCategoryNotFound, ERROR_CODE_NOT_FOUND)
+
+		if !errors.Is(err, ErrDuplicateErrorCode) {
+			t.Errorf("Expected ErrDuplicateErrorCode, got %v", err)
+		}
+	})
+
+	t.Run("Idempotent re-registration", func(t *testing.T) {
+		if err := RegisterSentinel(ErrNotFound, ErrorCategoryNotFound, ERROR_CODE_NOT_FOUND); err != nil {
+			t.Errorf("Re-registering identical mapping should succeed, got %v", err)
+		}
+	})
+
+	t.Run("Conflicting re-registration rejected", func(t *testing.T) {
+		err := RegisterSentinel(ErrNotFound, ErrorCategoryValidation, "SOMETHING_ELSE")
+
+		if !errors.Is(err, ErrSentinelAlreadyRegistered) {
+			t.Errorf("Expected ErrSentinelAlreadyRegistered, got %v", err)
+		}
+	})
+
+	t.Run("Replace existing", func(t *testing.T) {
+		errGone := errors.New("gone")
+		if err := RegisterSentinel(errGone, ErrorCategoryNotFound, "GONE_TEST"); err != nil {
+			t.Fatalf("RegisterSentinel failed: %v", err)
+		}
+		defer UnregisterSentinel(errGone)
+
+		if err := RegisterSentinel(errGone, ErrorCategoryConflict, "GONE_TEST", ReplaceExisting()); err != nil {
+			t.Fatalf("Replace should succeed, got %v", err)
+		}
+
+		spec, _ := LookupSentinel(errGone)
+		if spec.Category != ErrorCategoryConflict {
+			t.Errorf("Category = %v, want %v", spec.Category, ErrorCategoryConflict)
+		}
+	})
+
+	t.Run("Invalid registrations", func(t *testing.T) {
+		if err := RegisterSentinel(nil, ErrorCategoryInternal, "NIL"); !errors.Is(err, ErrInvalidSentinel) {
+			t.Errorf("nil sentinel should be rejected, got %v", err)
+		}
+		if err := RegisterSentinel(errors.New("x"), ErrorCategoryInternal, ""); !errors.Is(err, ErrInvalidSentinel) {
+			t.Errorf("empty code should be rejected, got %v", err)
+		}
+	})
+
+	t.Run("Errors holding interfaces", func(t *testing.T) {
+		if err := RegisterSentinel(interfaceFieldError{}, ErrorCategoryInternal, "INTERFACE_FIELD"); !errors.Is(err, ErrInvalidSentinel) {
+			t.Errorf("Struct sentinels holding interfaces should be rejected, got %v", err)
+		}
+
+		// == on this value would panic, so lookup must not use it as a map key
+		wrapped := fmt.Errorf("lookup: %w", interfaceFieldError{value: []string{"a"}})
+		if _, registered := LookupSentinel(wrapped); registered {
+			t.Error("Unregistered error should not resolve")
+		}
+		if spec, registered := LookupSentinel(fmt.Errorf("%w: %w", interfaceFieldError{value: map[string]int{}}, ErrNotFound)); !registered || spec.Sentinel != ErrNotFound {
+			t.Errorf("Lookup should still find wrapped sentinels, got %+v", spec)
+		}
+	})
+
+	t.Run("MustRegisterSentinel panics on conflict", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic")
+			}
+		}()
+		MustRegisterSentinel(errors.New("dup"), ErrorCategoryNotFound, ERROR_CODE_NOT_FOUND)
+	})
+}
+
+// TestSentinelRegistryConcurrency tests concurrent registration and lookup
+func TestSentinelRegistryConcurrency(t *testing.T) {
+	const numGoroutines = 50
+	var wg sync.WaitGroup
+
+	sentinels := make([]error, numGoroutines)
+	for i := range sentinels {
+		sentinels[i] = fmt.Errorf("concurrent sentinel %d", i)
+	}
+	t.Cleanup(func() {
+		for _, sentinel := range sentinels {
+			UnregisterSentinel(sentinel)
+		}
+	})
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			code := fmt.Sprintf("CONCURRENT_%d", id)
+			if err := RegisterSentinel(sentinels[id], ErrorCategoryConflict, code); err != nil {
+				t.Errorf("RegisterSentinel failed: %v", err)
+				return
+			}
+			if got := NewCustomError(sentinels[id], nil, "x").Code; got != code {
+				t.Errorf("Code = %v, want %v", got, code)
+			}
+			_ = NewCustomError(ErrNotFound, nil, "lookup")
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// TestCategoryRegistry tests user-defined categories and their mappings
+func TestCategoryRegistry(t *testing.T) {
+	categoryPaymentRequired := MustDefineCategory("payment_required", CategorySpec{
+		HTTPStatus:    402,
+		ExposeMessage: true,
+		Severity:      SeverityWarning,
+	})
+	categoryLedger := MustDefineCategory("ledger_failure", CategorySpec{
+		HTTPStatus:  503,
+		SafeMessage: "Payments are temporarily unavailable",
+		Severity:    SeverityCritical,
+		Retryable:   true,
+	})
+	t.Cleanup(func() {
+		UndefineCategory(categoryPaymentRequired)
+		UndefineCategory(categoryLedger)
+	})
+
+	t.Run("HTTP status mapping", func(t *testing.T) {
+		if status := CategoryToHTTPStatus(categoryPaymentRequired); status != 402 {
+			t.Errorf("Status = %d, want 402", status)
+		}
+
+		err := NewCustomErrorWithCategory(categoryPaymentRequired, "PAYMENT_REQUIRED", "upgrade your plan")
+		if err.ToHTTPStatus() != 402 {
+			t.Errorf("Error status = %d, want 402", err.ToHTTPStatus())
+		}
+
+		collection := NewErrorCollection("billing").Add(err)
+		if collection.ToHTTPStatus() != 402 {
+			t.Errorf("Collection status = %d, want 402", collection.ToHTTPStatus())
+		}
+	})
+
+	t.Run("Unknown category maps to 500", func(t *testing.T) {
+		if status := CategoryToHTTPStatus("never_defined"); status != HTTP_STATUS_INTERNAL_SERVER_ERROR {
+			t.Errorf("Status = %d, want 500", status)
+		}
+	})
+
+	t.Run("Client safety policy", func(t *testing.T) {
+		originalConfig := GetConfig()
+		defer SetConfig(originalConfig)
+		SetConfig(&Config{ProductionMode: true, EnableStackTrace: false, MaxStackDepth: DEFAULT_STACK_DEPTH})
+
+		exposed := NewCustomErrorWithCategory(categoryPaymentRequired, "PAYMENT_REQUIRED", "upgrade your plan")
+		if exposed.ClientSafeMessage() != "upgrade your plan" {
+			t.Errorf("Exposed message = %q", exposed.ClientSafeMessage())
+		}
+
+		hidden := NewCustomErrorWithCategory(categoryLedger, "LEDGER_DOWN", "ledger db at 10.0.0.5 refused")
+		if hidden.ClientSafeMessage() != "Payments are temporarily unavailable" {
+			t.Errorf("Hidden message = %q", hidden.ClientSafeMessage())
+		}
+
+		unknown := NewCustomErrorWithCategory("never_defined", "X", "secret detail")
+		if unknown.ClientSafeMessage() == "secret detail" {
+			t.Error("Unknown categories should not expose messages in production")
+		}
+	})
+
+	t.Run("Severity and log level", func(t *testing.T) {
+		if CategoryLogLevel(categoryPaymentRequired) != LogLevelWarn {
+			t.Error("Should use the configured log level")
+		}
+		if CategorySeverity(categoryLedger) != SeverityCritical {
+			t.Error("Should use the configured severity")
+		}
+
+		legacy := MustDefineCategory("legacy_quota", CategorySpec{HTTPStatus: 402, LogLevel: LogLevelWarn})
+		defer UndefineCategory(legacy)
+		if CategorySeverity(legacy) != SeverityWarning || CategoryLogLevel(legacy) != LogLevelWarn {
+			t.Error("The deprecated LogLevel should apply when Severity is unset")
+		}
+	})
+
+	t.Run("FromHTTPStatus uses defined category", func(t *testing.T) {
+		err := FromHTTPStatus(402, "")
+		if err.Category != categoryPaymentRequired {
+			t.Errorf("Category = %v, want %v", err.Category, categoryPaymentRequired)
+		}
+	})
+
+	t.Run("Invalid definitions", func(t *testing.T) {
+		if err := DefineCategory("", CategorySpec{HTTPStatus: 400}); !errors.Is(err, ErrInvalidCategory) {
+			t.Errorf("Empty name should be rejected, got %v", err)
+		}
+		if err := DefineCategory("ok_status", CategorySpec{HTTPStatus: 200}); !errors.Is(err, ErrInvalidCategory) {
+			t.Errorf("Non-error status should be rejected, got %v", err)
+		}
+		if UndefineCategory(ErrorCategoryInternal) {
+			t.Error("Built-in categories should not be removable")
+		}
+	})
+}
+
+// interfaceFieldError is a comparable struct error whose == panics for slice or map values
+type interfaceFieldError struct {
+	value interface{}
+}
+
+// Error returns a fixed message
+func (interfaceFieldError) Error() string {
+	return "interface field error"
+}
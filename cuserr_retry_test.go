@@ -0,0 +1,251 @@
+package cuserr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that advances instantly and records requested sleeps
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+	// cancel, when set, is called instead of sleeping to simulate cancellation
+	cancel context.CancelFunc
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	if c.cancel != nil {
+		c.cancel()
+		return ctx.Err()
+	}
+	c.now = c.now.Add(d)
+	return nil
+}
+
+// failing returns fn that fails with the given errors in turn and then succeeds
+func failing(calls *int, errs ...error) func(context.Context) error {
+	return func(context.Context) error {
+		*calls++
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+		return nil
+	}
+}
+
+// TestRetry tests the retry executor with an injected clock
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:  4,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     250 * time.Millisecond,
+		Multiplier:   2,
+	}
+
+	t.Run("Succeeds after transient failures", func(t *testing.T) {
+		clock := newFakeClock()
+		p := policy
+		p.Clock = clock
+
+		calls := 0
+		err := Retry(context.Background(), p, failing(&calls, NewTimeoutError("db", nil), NewTimeoutError("db", nil)))
+		if err != nil {
+			t.Fatalf("Retry returned %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("calls = %d, want 3", calls)
+		}
+		if fmt.Sprint(clock.sleeps) != "[100ms 200ms]" {
+			t.Errorf("sleeps = %v", clock.sleeps)
+		}
+	})
+
+	t.Run("Exhausts attempts and records counts", func(t *testing.T) {
+		clock := newFakeClock()
+		p := policy
+		p.Clock = clock
+
+		calls := 0
+		timeout := NewTimeoutError("db", nil)
+		err := Retry(context.Background(), p, failing(&calls, timeout, timeout, timeout, timeout, timeout))
+		if calls != 4 {
+			t.Errorf("calls = %d, want 4", calls)
+		}
+		if fmt.Sprint(clock.sleeps) != "[100ms 200ms 250ms]" {
+			t.Errorf("sleeps should be capped at MaxDelay: %v", clock.sleeps)
+		}
+		if attempt, _ := KeyAttempt.Get(err); attempt != 4 {
+			t.Errorf("attempt = %d", attempt)
+		}
+		if retries, _ := KeyRetryCount.Get(err); retries != 3 {
+			t.Errorf("retry_count = %d", retries)
+		}
+		if !errors.Is(err, ErrTimeout) {
+			t.Error("Final error should match its sentinel")
+		}
+		if _, exists := timeout.GetMetadata(MetaAttempt); exists {
+			t.Error("The error returned by fn should not be modified")
+		}
+	})
+
+	t.Run("Shared error instance", func(t *testing.T) {
+		p := policy
+		p.Clock = newFakeClock()
+		p.CollectAttempts = true
+
+		shared := NewUnavailableError("db", "maintenance")
+		err := Retry(context.Background(), p, func(context.Context) error { return shared })
+
+		var collection *ErrorCollection
+		if !errors.As(err, &collection) || collection.ErrorCount() != 4 {
+			t.Fatalf("Expected 4 collected attempts, got %v", err)
+		}
+		for i, attemptErr := range collection.Errors {
+			if attempt, _ := KeyAttempt.Get(attemptErr); attempt != i+1 {
+				t.Errorf("Error %d has attempt %d", i, attempt)
+			}
+		}
+		if _, exists := shared.GetMetadata(MetaAttempt); exists {
+			t.Errorf("Shared error was modified: %v", shared.GetAllMetadata())
+		}
+	})
+
+	t.Run("Stops on non-retryable errors", func(t *testing.T) {
+		clock := newFakeClock()
+		p := policy
+		p.Clock = clock
+
+		calls := 0
+		err := Retry(context.Background(), p, failing(&calls, NewValidationError("email", "invalid")))
+		if calls != 1 || len(clock.sleeps) != 0 {
+			t.Errorf("calls = %d, sleeps = %v", calls, clock.sleeps)
+		}
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Unexpected error %v", err)
+		}
+
+		calls = 0
+		err = Retry(context.Background(), p, failing(&calls, errors.New("boom")))
+		if calls != 1 {
+			t.Errorf("Plain errors should not be retried, calls = %d", calls)
+		}
+		if attempt, _ := KeyAttempt.Get(err); attempt != 1 {
+			t.Errorf("Plain errors should be converted and annotated, attempt = %d", attempt)
+		}
+	})
+
+	t.Run("Honours Retry-After hints", func(t *testing.T) {
+		clock := newFakeClock()
+		p := policy
+		p.Clock = clock
+
+		calls := 0
+		err := Retry(context.Background(), p, failing(&calls, NewRateLimitError("10", "1s").WithRetryAfter(2*time.Second)))
+		if err != nil {
+			t.Fatalf("Retry returned %v", err)
+		}
+		if fmt.Sprint(clock.sleeps) != "[2s]" {
+			t.Errorf("sleeps = %v", clock.sleeps)
+		}
+	})
+
+	t.Run("Max elapsed time", func(t *testing.T) {
+		clock := newFakeClock()
+		p := policy
+		p.Clock = clock
+		p.MaxElapsedTime = 350 * time.Millisecond
+
+		calls := 0
+		timeout := NewTimeoutError("db", nil)
+		_ = Retry(context.Background(), p, failing(&calls, timeout, timeout, timeout, timeout))
+		if calls != 3 {
+			t.Errorf("calls = %d, want 3 (100ms + 200ms fit, 250ms more does not)", calls)
+		}
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		clock := newFakeClock()
+		p := policy
+		p.Clock = clock
+		p.Jitter = 0.5
+		p.Rand = func() float64 { return 0 }
+
+		calls := 0
+		_ = Retry(context.Background(), p, failing(&calls, NewTimeoutError("db", nil)))
+		if fmt.Sprint(clock.sleeps) != "[50ms]" {
+			t.Errorf("sleeps = %v", clock.sleeps)
+		}
+	})
+
+	t.Run("Context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		clock := newFakeClock()
+		clock.cancel = cancel
+		p := policy
+		p.Clock = clock
+
+		calls := 0
+		err := Retry(ctx, p, failing(&calls, NewTimeoutError("db", nil), NewTimeoutError("db", nil)))
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+		if !errors.Is(err, context.Canceled) || !IsErrorCategory(err, ErrorCategoryClientClosed) {
+			t.Errorf("Expected a client_closed error wrapping context.Canceled, got %v", err)
+		}
+		if attempt, _ := KeyAttempt.Get(err); attempt != 1 {
+			t.Errorf("attempt = %d", attempt)
+		}
+		var customErr *CustomError
+		if !errors.As(err, &customErr) || len(customErr.Causes()) != 2 || !errors.Is(err, ErrTimeout) {
+			t.Fatalf("The last failure should be kept as a cause, got %v", err)
+		}
+		if attempt, _ := KeyAttempt.Get(customErr.Causes()[1]); attempt != 1 {
+			t.Errorf("cause attempt = %d", attempt)
+		}
+	})
+
+	t.Run("Collects attempts", func(t *testing.T) {
+		p := policy
+		p.Clock = newFakeClock()
+		p.CollectAttempts = true
+
+		calls := 0
+		err := Retry(context.Background(), p, failing(&calls,
+			NewTimeoutError("db", nil), NewUnavailableError("db", "maintenance"), NewValidationError("id", "bad")))
+
+		var collection *ErrorCollection
+		if !errors.As(err, &collection) {
+			t.Fatalf("Expected *ErrorCollection, got %T", err)
+		}
+		if collection.ErrorCount() != 3 {
+			t.Fatalf("ErrorCount = %d", collection.ErrorCount())
+		}
+		for i, attemptErr := range collection.Errors {
+			if attempt, _ := KeyAttempt.Get(attemptErr); attempt != i+1 {
+				t.Errorf("Error %d has attempt %d", i, attempt)
+			}
+		}
+	})
+}
+
+// TestSystemClockSleep tests that the real clock respects cancellation
+func TestSystemClockSleep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := SystemClock.Sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep = %v", err)
+	}
+	if err := SystemClock.Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Sleep = %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package cuserr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// timeoutError is a plain error reporting a timeout, like net.Error
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "i/o timeout" }
+func (timeoutError) Timeout() bool { return true }
+
+// TestRetryableClassification tests category defaults and overrides
+func TestRetryableClassification(t *testing.T) {
+	t.Run("Category defaults", func(t *testing.T) {
+		testCases := []struct {
+			err  error
+			want bool
+		}{
+			{NewTimeoutError("db", nil), true},
+			{NewRateLimitError("100", "1m"), true},
+			{NewExternalError("payments", "charge", nil), true},
+			{NewValidationError("email", "invalid"), false},
+			{NewNotFoundError("user", "1"), false},
+			{NewInternalError("db", nil), false},
+		}
+		for _, tc := range testCases {
+			if got := IsRetryable(tc.err); got != tc.want {
+				t.Errorf("%v: IsRetryable = %v, want %v", tc.err, got, tc.want)
+			}
+		}
+	})
+
+	t.Run("Per-error override", func(t *testing.T) {
+		err := NewTimeoutError("db", nil).WithRetryable(false)
+		if IsRetryable(err) {
+			t.Error("Explicit override should win over the category default")
+		}
+		if !IsRetryable(NewInternalError("db", nil).WithRetryable(true)) {
+			t.Error("Internal error marked retryable should be retryable")
+		}
+		if IsRetryable(err.Clone()) {
+			t.Error("Clone should keep the override")
+		}
+	})
+
+	t.Run("Wrapped and plain errors", func(t *testing.T) {
+		wrapped := fmt.Errorf("calling upstream: %w", NewTimeoutError("db", nil))
+		if !IsRetryable(wrapped) {
+			t.Error("Wrapped CustomError should be classified")
+		}
+		if IsRetryable(nil) || IsRetryable(errors.New("boom")) {
+			t.Error("nil and plain errors should not be retryable")
+		}
+		if !IsRetryable(context.DeadlineExceeded) || !IsRetryable(timeoutError{}) {
+			t.Error("Deadline and timeout errors should be retryable")
+		}
+	})
+
+	t.Run("Classifier hook", func(t *testing.T) {
+		SetRetryClassifier(func(err error) (bool, bool) {
+			if errors.Is(err, ErrInternal) {
+				return true, true
+			}
+			return false, false
+		})
+		defer SetRetryClassifier(nil)
+
+		if !IsRetryable(NewInternalError("db", nil)) {
+			t.Error("Classifier should mark internal errors retryable")
+		}
+		if IsRetryable(NewValidationError("email", "invalid")) {
+			t.Error("Undecided errors should fall through to defaults")
+		}
+		if IsRetryable(NewInternalError("db", nil).WithRetryable(false)) {
+			t.Error("Explicit override should win over the classifier")
+		}
+	})
+}
+
+// TestRetryAfter tests retry hints and their serialization
+func TestRetryAfter(t *testing.T) {
+	t.Run("Hint implies retryable", func(t *testing.T) {
+		err := NewInternalError("db", nil).WithRetryAfter(1500 * time.Millisecond)
+		if delay, ok := RetryAfter(err); !ok || delay != 1500*time.Millisecond {
+			t.Errorf("RetryAfter = %v, %v", delay, ok)
+		}
+		if !IsRetryable(err) {
+			t.Error("Error with a retry hint should be retryable")
+		}
+		if _, ok := RetryAfter(errors.New("plain")); ok {
+			t.Error("Plain errors carry no hint")
+		}
+	})
+
+	t.Run("JSON fields", func(t *testing.T) {
+		err := NewRateLimitError("100", "1m").WithRetryAfter(1500 * time.Millisecond)
+		for name, data := range map[string]map[string]interface{}{
+			"ToJSON":       err.ToJSON(),
+			"ToClientJSON": err.ToClientJSON(),
+		} {
+			errorData := data[JSON_FIELD_ERROR].(map[string]interface{})
+			if errorData[JSON_FIELD_RETRYABLE] != true {
+				t.Errorf("%s: retryable = %v", name, errorData[JSON_FIELD_RETRYABLE])
+			}
+			if errorData[JSON_FIELD_RETRY_AFTER_SECONDS] != int64(2) {
+				t.Errorf("%s: retry_after_seconds = %v", name, errorData[JSON_FIELD_RETRY_AFTER_SECONDS])
+			}
+		}
+
+		var decoded map[string]map[string]interface{}
+		if jsonErr := json.Unmarshal([]byte(err.ToJSONString()), &decoded); jsonErr != nil {
+			t.Fatalf("ToJSONString produced invalid JSON: %v", jsonErr)
+		}
+		if decoded[JSON_FIELD_ERROR][JSON_FIELD_RETRY_AFTER_SECONDS] != float64(2) {
+			t.Errorf("ToJSONString retry_after_seconds = %v", decoded[JSON_FIELD_ERROR][JSON_FIELD_RETRY_AFTER_SECONDS])
+		}
+
+		plain := NewValidationError("email", "invalid").ToJSON()[JSON_FIELD_ERROR].(map[string]interface{})
+		if plain[JSON_FIELD_RETRYABLE] != false {
+			t.Error("Validation errors should serialize retryable=false")
+		}
+		if _, exists := plain[JSON_FIELD_RETRY_AFTER_SECONDS]; exists {
+			t.Error("retry_after_seconds should be omitted without a hint")
+		}
+	})
+
+	t.Run("ParseRetryAfter", func(t *testing.T) {
+		if delay, ok := ParseRetryAfter(" 120 "); !ok || delay != 2*time.Minute {
+			t.Errorf("ParseRetryAfter(120) = %v, %v", delay, ok)
+		}
+		future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+		if delay, ok := ParseRetryAfter(future); !ok || delay <= 59*time.Minute {
+			t.Errorf("ParseRetryAfter(date) = %v, %v", delay, ok)
+		}
+		for _, value := range []string{"", "0", "-5", "soon", "Mon, 02 Jan 2006 15:04:05 GMT"} {
+			if _, ok := ParseRetryAfter(value); ok {
+				t.Errorf("ParseRetryAfter(%q) should fail", value)
+			}
+		}
+	})
+}
+
+// TestFromHTTPStatusRetrySemantics tests retryability of migrated HTTP errors
+func TestFromHTTPStatusRetrySemantics(t *testing.T) {
+	for status, want := range map[int]bool{
+		429: true,
+		503: true,
+		502: true,
+		400: false,
+		404: false,
+		500: false,
+	} {
+		if got := FromHTTPStatus(status, "").IsRetryable(); got != want {
+			t.Errorf("FromHTTPStatus(%d).IsRetryable() = %v, want %v", status, got, want)
+		}
+	}
+
+	SetRetryClassifier(func(err error) (bool, bool) { return false, true })
+	defer SetRetryClassifier(nil)
+	if !FromHTTPStatus(503, "").IsRetryable() {
+		t.Error("503 should stay retryable regardless of the classifier")
+	}
+}
@@ -0,0 +1,266 @@
+package cuserr
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRPCCodes tests code names, parsing and the category and HTTP mappings
+func TestRPCCodes(t *testing.T) {
+	t.Run("Names", func(t *testing.T) {
+		if RPCCodeNotFound.String() != "NOT_FOUND" || RPCCodeNotFound.ConnectName() != "not_found" {
+			t.Errorf("NotFound = %s/%s", RPCCodeNotFound, RPCCodeNotFound.ConnectName())
+		}
+		if RPCCodeCanceled.String() != "CANCELLED" || RPCCodeCanceled.ConnectName() != "canceled" {
+			t.Errorf("Canceled = %s/%s", RPCCodeCanceled, RPCCodeCanceled.ConnectName())
+		}
+		if RPCCode(99).String() != "CODE(99)" {
+			t.Errorf("Unknown code = %s", RPCCode(99))
+		}
+	})
+
+	t.Run("Parsing", func(t *testing.T) {
+		cases := map[string]RPCCode{
+			"NOT_FOUND":        RPCCodeNotFound,
+			"invalid_argument": RPCCodeInvalidArgument,
+			"canceled":         RPCCodeCanceled,
+			"CANCELLED":        RPCCodeCanceled,
+			"dataloss":         RPCCodeDataLoss,
+			"malformed":        RPCCodeInvalidArgument,
+			"16":               RPCCodeUnauthenticated,
+		}
+		for name, want := range cases {
+			if code, err := ParseRPCCode(name); err != nil || code != want {
+				t.Errorf("ParseRPCCode(%q) = %s, %v", name, code, err)
+			}
+		}
+		for _, name := range []string{"", "teapot", "17", "-1"} {
+			if _, err := ParseRPCCode(name); !errors.Is(err, ErrInvalidRPCCode) {
+				t.Errorf("ParseRPCCode(%q) error = %v", name, err)
+			}
+		}
+	})
+
+	t.Run("Categories", func(t *testing.T) {
+		cases := map[ErrorCategory]RPCCode{
+			ErrorCategoryValidation:   RPCCodeInvalidArgument,
+			ErrorCategoryNotFound:     RPCCodeNotFound,
+			ErrorCategoryConflict:     RPCCodeAlreadyExists,
+			ErrorCategoryUnauthorized: RPCCodeUnauthenticated,
+			ErrorCategoryForbidden:    RPCCodePermissionDenied,
+			ErrorCategoryTimeout:      RPCCodeDeadlineExceeded,
+			ErrorCategoryRateLimit:    RPCCodeResourceExhausted,
+			ErrorCategoryUnavailable:  RPCCodeUnavailable,
+			ErrorCategoryInternal:     RPCCodeInternal,
+		}
+		for category, want := range cases {
+			if code := CategoryToRPCCode(category); code != want {
+				t.Errorf("CategoryToRPCCode(%s) = %s, want %s", category, code, want)
+			}
+		}
+
+		MustDefineCategory("payment_required_rpc", CategorySpec{HTTPStatus: 402})
+		defer UndefineCategory("payment_required_rpc")
+		if code := CategoryToRPCCode("payment_required_rpc"); code != RPCCodeFailedPrecondition {
+			t.Errorf("Custom category = %s", code)
+		}
+	})
+
+	t.Run("HTTP status", func(t *testing.T) {
+		for code := RPCCodeCanceled; code <= RPCCodeUnauthenticated; code++ {
+			if status := code.HTTPStatus(); status < 400 || status > 599 {
+				t.Errorf("%s.HTTPStatus() = %d", code, status)
+			}
+		}
+		if RPCCodeFromHTTPStatus(404) != RPCCodeNotFound || RPCCodeFromHTTPStatus(502) != RPCCodeUnavailable {
+			t.Error("HTTP status mapping mismatch")
+		}
+	})
+}
+
+// TestTwirpAndConnectErrors tests rendering and parsing of Twirp and Connect bodies
+func TestTwirpAndConnectErrors(t *testing.T) {
+	err := NewNotFoundError("user", "u1").WithRequestID("req-1").WithMetadataValue("attempts", 3)
+
+	t.Run("Twirp", func(t *testing.T) {
+		data, marshalErr := json.Marshal(err.ToTwirpError())
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+		body := string(data)
+		for _, want := range []string{`"code":"not_found"`, `"msg":"user with id 'u1' not found"`, `"attempts":"3"`, `"error_code":"NOT_FOUND"`, `"request_id":"req-1"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Body %s should contain %s", body, want)
+			}
+		}
+
+		parsed, parseErr := ParseRPCError(data)
+		if parseErr != nil {
+			t.Fatal(parseErr)
+		}
+		if !errors.Is(parsed, ErrNotFound) || parsed.RequestID != "req-1" || parsed.Message != "user with id 'u1' not found" {
+			t.Errorf("Parsed = %+v", parsed)
+		}
+		if attempts, _ := parsed.GetMetadata("attempts"); attempts != "3" {
+			t.Errorf("attempts = %q", attempts)
+		}
+	})
+
+	t.Run("Connect", func(t *testing.T) {
+		data, _ := json.Marshal(NewTimeoutError("query", nil).ToConnectError())
+		if !strings.HasPrefix(string(data), `{"code":"deadline_exceeded","message":`) {
+			t.Errorf("Body = %s", data)
+		}
+
+		parsed, parseErr := ParseRPCError([]byte(`{"code":"permission_denied","message":"no access"}`))
+		if parseErr != nil {
+			t.Fatal(parseErr)
+		}
+		if parsed.Category != ErrorCategoryForbidden || parsed.Message != "no access" {
+			t.Errorf("Parsed = %+v", parsed)
+		}
+	})
+
+	t.Run("Collections", func(t *testing.T) {
+		collection := NewValidationErrorCollection().WithRequestID("req-c")
+		collection.AddValidationWithCode("email", "must be valid", "INVALID_EMAIL")
+		collection.Add(NewConflictError("user", "name", "ann").WithSeverity(SeverityCritical))
+
+		twirpErr := collection.ToTwirpError()
+		if twirpErr.Code != "already_exists" || twirpErr.Msg != collection.Error() {
+			t.Errorf("Twirp error = %+v", twirpErr)
+		}
+		if twirpErr.Meta[JSON_FIELD_ERROR_CODE] != ERROR_CODE_MULTIPLE_ERRORS || twirpErr.Meta[JSON_FIELD_REQUEST_ID] != "req-c" {
+			t.Errorf("Meta = %v", twirpErr.Meta)
+		}
+
+		data, _ := json.Marshal(twirpErr)
+		parsed, parseErr := ParseRPCError(data)
+		if parseErr != nil {
+			t.Fatal(parseErr)
+		}
+		causes := parsed.Causes()
+		if len(causes) != 1 || GetErrorCode(causes[0]) != "INVALID_EMAIL" || parsed.RequestID != "req-c" {
+			t.Fatalf("Parsed = %+v, causes = %v", parsed, causes)
+		}
+		if field, _ := GetErrorMetadata(causes[0], MetaField); field != "email" {
+			t.Errorf("field = %q", field)
+		}
+		if _, exists := parsed.GetMetadata(JSON_FIELD_FIELD_VIOLATIONS); exists {
+			t.Error("Field violations should become causes, not metadata")
+		}
+
+		connectErr := collection.ToConnectError()
+		if connectErr.Code != "already_exists" || connectErr.Message != collection.Error() {
+			t.Errorf("Connect error = %+v", connectErr)
+		}
+		if meta := NewErrorCollection("empty").ToTwirpError().Meta; len(meta) != 1 {
+			t.Errorf("Empty collections should only carry error_code, meta = %v", meta)
+		}
+	})
+
+	t.Run("Invalid bodies", func(t *testing.T) {
+		for _, body := range []string{`{"code":"teapot"}`, `{"msg":"no code"}`, `not json`} {
+			if _, parseErr := ParseRPCError([]byte(body)); parseErr == nil {
+				t.Errorf("ParseRPCError(%s) should fail", body)
+			}
+		}
+	})
+
+	t.Run("Production mode", func(t *testing.T) {
+		originalConfig := GetConfig()
+		defer SetConfig(originalConfig)
+		SetConfig(&Config{ProductionMode: true})
+
+		twirpErr := NewInternalError("database", nil).WithMetadata("query", "SELECT secret").WithRequestID("req-2").ToTwirpError()
+		if twirpErr.Code != "internal" || twirpErr.Msg != SAFE_MSG_INTERNAL || twirpErr.Meta["query"] != "" {
+			t.Errorf("Twirp error = %+v", twirpErr)
+		}
+		if twirpErr.Meta[JSON_FIELD_REQUEST_ID] != "req-2" {
+			t.Errorf("Request ID should be kept, meta = %v", twirpErr.Meta)
+		}
+	})
+}
+
+// TestRPCStatus tests google.rpc.Status rendering and parsing
+func TestRPCStatus(t *testing.T) {
+	SetRPCErrorDomain("orders.example.com")
+	defer SetRPCErrorDomain("")
+
+	t.Run("Details", func(t *testing.T) {
+		err := NewRateLimitError("100", "1m").WithRetryAfter(1500 * time.Millisecond)
+		data, marshalErr := json.Marshal(err.ToRPCStatus())
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+		body := string(data)
+		for _, want := range []string{
+			`"code":8`,
+			`"@type":"` + RPC_TYPE_ERROR_INFO + `"`,
+			`"reason":"RATE_LIMIT"`,
+			`"domain":"orders.example.com"`,
+			`"@type":"` + RPC_TYPE_RETRY_INFO + `","retryDelay":"1.5s"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Body %s should contain %s", body, want)
+			}
+		}
+
+		parsed, parseErr := ParseRPCError(data)
+		if parseErr != nil {
+			t.Fatal(parseErr)
+		}
+		if !errors.Is(parsed, ErrRateLimit) {
+			t.Errorf("Parsed = %+v", parsed)
+		}
+		if delay, ok := parsed.RetryAfter(); !ok || delay != 1500*time.Millisecond {
+			t.Errorf("RetryAfter = %v, %v", delay, ok)
+		}
+	})
+
+	t.Run("Bad request", func(t *testing.T) {
+		collection := NewValidationErrorCollection().WithRequestID("req-3")
+		collection.AddValidationWithCode("email", "must be valid", "INVALID_EMAIL")
+		collection.AddValidation("name", "is required")
+
+		status := collection.ToRPCStatus()
+		if status.Code != RPCCodeInvalidArgument || status.BadRequest == nil || len(status.BadRequest.FieldViolations) != 2 {
+			t.Fatalf("Status = %+v", status)
+		}
+
+		data, _ := json.Marshal(status)
+		if !strings.Contains(string(data), `"fieldViolations":[{"field":"email","description":"must be valid","reason":"INVALID_EMAIL"}`) {
+			t.Errorf("Body = %s", data)
+		}
+
+		parsed, parseErr := ParseRPCStatus(data)
+		if parseErr != nil {
+			t.Fatal(parseErr)
+		}
+		causes := parsed.Causes()
+		if len(causes) != 2 || parsed.RequestID != "req-3" {
+			t.Fatalf("Parsed = %+v, causes = %v", parsed, causes)
+		}
+		var first *CustomError
+		if !errors.As(causes[0], &first) || first.Code != "INVALID_EMAIL" {
+			t.Errorf("First cause = %v", causes[0])
+		}
+		if field, _ := first.GetMetadata(MetaField); field != "email" {
+			t.Errorf("Field = %q", field)
+		}
+	})
+
+	t.Run("Unknown details", func(t *testing.T) {
+		data := []byte(`{"code":5,"message":"gone","details":[{"@type":"type.googleapis.com/google.rpc.Help","links":[]}]}`)
+		var status RPCStatus
+		if err := json.Unmarshal(data, &status); err != nil {
+			t.Fatal(err)
+		}
+		if status.Code != RPCCodeNotFound || status.ErrorInfo != nil || status.Message != "gone" {
+			t.Errorf("Status = %+v", status)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package cuserr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// recordingSeverityLogger records the severities it is asked to log
+type recordingSeverityLogger struct {
+	*ZapLogger
+	severities []Severity
+}
+
+// LogSeverity implements SeverityLogger
+func (l *recordingSeverityLogger) LogSeverity(_ context.Context, severity Severity, _ string, _ map[string]interface{}) {
+	l.severities = append(l.severities, severity)
+}
+
+// TestSeverity tests severity defaults, overrides and names
+func TestSeverity(t *testing.T) {
+	t.Run("Category defaults", func(t *testing.T) {
+		testCases := []struct {
+			err  *CustomError
+			want Severity
+		}{
+			{NewValidationError("email", "invalid"), SeverityWarning},
+			{NewInternalError("db", nil), SeverityError},
+			{NewClientClosedError("search", nil), SeverityInfo},
+			{NewCustomErrorWithCategory("never_defined", "X", "x"), SeverityError},
+		}
+		for _, tc := range testCases {
+			if got := tc.err.Severity(); got != tc.want {
+				t.Errorf("%s: Severity = %v, want %v", tc.err.Code, got, tc.want)
+			}
+		}
+	})
+
+	t.Run("Per-error override", func(t *testing.T) {
+		err := NewInternalError("ledger", nil).WithSeverity(SeverityCritical)
+		if err.Severity() != SeverityCritical {
+			t.Errorf("Severity = %v", err.Severity())
+		}
+		if GetErrorSeverity(err) != SeverityCritical {
+			t.Error("GetErrorSeverity should read the override")
+		}
+		if GetErrorSeverity(errors.New("plain")) != SeverityError {
+			t.Error("Plain errors should default to SeverityError")
+		}
+		if err.Clone().Severity() != SeverityCritical {
+			t.Error("Clone should keep the override")
+		}
+	})
+
+	t.Run("Names round trip", func(t *testing.T) {
+		for s := SeverityDebug; s <= SeverityFatal; s++ {
+			parsed, err := ParseSeverity(strings.ToUpper(s.String()))
+			if err != nil || parsed != s {
+				t.Errorf("ParseSeverity(%q) = %v, %v", s.String(), parsed, err)
+			}
+		}
+		if _, err := ParseSeverity("loud"); !errors.Is(err, ErrInvalidSeverity) {
+			t.Errorf("Expected ErrInvalidSeverity, got %v", err)
+		}
+	})
+
+	t.Run("Level mapping", func(t *testing.T) {
+		if SeverityNotice.LogLevel() != LogLevelInfo || SeverityCritical.LogLevel() != LogLevelError {
+			t.Error("Unexpected LogLevel mapping")
+		}
+		if SeverityCritical.SlogLevel() <= slog.LevelError || SeverityNotice.SlogLevel() >= slog.LevelWarn {
+			t.Error("Custom slog levels should sit between the standard levels")
+		}
+	})
+}
+
+// TestSeverityLogging tests that logging honors severity
+func TestSeverityLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewDefaultSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	t.Run("Log fields", func(t *testing.T) {
+		fields := NewValidationError("email", "invalid").ToLogFields()
+		if fields["severity"] != SEVERITY_WARNING {
+			t.Errorf("severity field = %v", fields["severity"])
+		}
+	})
+
+	t.Run("slog level follows severity", func(t *testing.T) {
+		buf.Reset()
+		logger.LogError(context.Background(), NewValidationError("email", "invalid"))
+		if !strings.Contains(buf.String(), `"level":"WARN"`) {
+			t.Errorf("Expected WARN level: %s", buf.String())
+		}
+
+		buf.Reset()
+		logger.LogError(context.Background(), NewInternalError("ledger", nil).WithSeverity(SeverityCritical))
+		if !strings.Contains(buf.String(), `"level":"ERROR+4"`) {
+			t.Errorf("Expected critical level: %s", buf.String())
+		}
+	})
+
+	t.Run("Collection uses most severe member", func(t *testing.T) {
+		collection := NewErrorCollection("batch")
+		collection.AddValidation("email", "required")
+		collection.Add(NewInternalError("db", nil))
+
+		if collection.Severity() != SeverityError {
+			t.Errorf("Collection severity = %v", collection.Severity())
+		}
+
+		buf.Reset()
+		logger.LogErrorCollection(context.Background(), collection)
+		if !strings.Contains(buf.String(), `"level":"ERROR"`) {
+			t.Errorf("Expected ERROR level: %s", buf.String())
+		}
+
+		clientOnly := NewValidationErrorCollection()
+		clientOnly.AddValidation("email", "required")
+		if clientOnly.Severity() != SeverityWarning {
+			t.Errorf("Validation collection severity = %v", clientOnly.Severity())
+		}
+	})
+
+	t.Run("Handler level is a floor", func(t *testing.T) {
+		recorder := &recordingSeverityLogger{ZapLogger: NewZapLogger(nil)}
+		handler := NewLoggingErrorHandler(recorder, LogLevelWarn)
+
+		handler.Handle(context.Background(), NewClientClosedError("search", nil))
+		handler.Handle(context.Background(), NewInternalError("db", nil).WithSeverity(SeverityFatal))
+
+		if len(recorder.severities) != 2 {
+			t.Fatalf("Expected 2 log calls, got %d", len(recorder.severities))
+		}
+		if recorder.severities[0] != SeverityWarning {
+			t.Errorf("Info error should be raised to the handler floor, got %v", recorder.severities[0])
+		}
+		if recorder.severities[1] != SeverityFatal {
+			t.Errorf("Fatal error should keep its severity, got %v", recorder.severities[1])
+		}
+	})
+}
+
+// TestCollectionCategoryRendering tests that collections report the category of their most severe member
+func TestCollectionCategoryRendering(t *testing.T) {
+	collection := NewValidationErrorCollection()
+	collection.AddValidation("email", "is required")
+	collection.Add(NewInternalError("db", errors.New("connection refused")).WithSeverity(SeverityCritical))
+
+	if collection.ToHTTPStatus() != HTTP_STATUS_INTERNAL_SERVER_ERROR || collection.Severity() != SeverityCritical {
+		t.Fatalf("status = %d, severity = %v", collection.ToHTTPStatus(), collection.Severity())
+	}
+
+	want := string(ErrorCategoryInternal)
+	if category := collection.ToJSON()[JSON_FIELD_ERROR].(map[string]interface{})[JSON_FIELD_CATEGORY]; category != want {
+		t.Errorf("ToJSON category = %v", category)
+	}
+	var buf bytes.Buffer
+	if err := collection.WriteJSON(&buf); err != nil || !strings.Contains(buf.String(), `"category":"internal","code":"MULTIPLE_ERRORS"`) {
+		t.Errorf("WriteJSON = %s, %v", buf.String(), err)
+	}
+	if fields := collection.ToLogFields(); fields["error_category"] != want || fields["severity"] != SEVERITY_CRITICAL {
+		t.Errorf("log fields = %v / %v", fields["error_category"], fields["severity"])
+	}
+
+	originalConfig := GetConfig()
+	defer SetConfig(originalConfig)
+	SetConfig(&Config{ProductionMode: true})
+	if category := collection.ToClientJSON()[JSON_FIELD_ERROR].(map[string]interface{})[JSON_FIELD_CATEGORY]; category != want {
+		t.Errorf("ToClientJSON category = %v", category)
+	}
+}
@@ -0,0 +1,116 @@
+package cuserr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTypedMetadataValues tests that metadata keeps native value types
+func TestTypedMetadataValues(t *testing.T) {
+	occurred := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	err := NewInternalError("billing", nil).
+		WithMetadataValue("attempts", 3).
+		WithMetadataValue("ratio", 0.25).
+		WithMetadataValue("cached", true).
+		WithMetadataValue("occurred_at", occurred).
+		WithMetadataValue("latency", 1500*time.Millisecond).
+		WithMetadataValue("tags", []string{"a", "b"}).
+		WithMetadataValue("limits", map[string]interface{}{"max": 10})
+	err.GetTypedMetadata().WithRetryCount(2).WithResponseTime(250 * time.Millisecond)
+
+	t.Run("Native values", func(t *testing.T) {
+		all := err.GetAllMetadata()
+		if all["attempts"] != 3 {
+			t.Errorf("attempts = %#v, want int 3", all["attempts"])
+		}
+		if all[MetaRetryCount] != 2 {
+			t.Errorf("retry_count = %#v, want int 2", all[MetaRetryCount])
+		}
+		if all[MetaResponseTime] != 250*time.Millisecond {
+			t.Errorf("response_time = %#v, want duration", all[MetaResponseTime])
+		}
+	})
+
+	t.Run("String API", func(t *testing.T) {
+		testCases := map[string]string{
+			"attempts":       "3",
+			"ratio":          "0.25",
+			"cached":         "true",
+			"occurred_at":    "2025-09-20T12:00:00Z",
+			"latency":        "1.5s",
+			"tags":           "a,b",
+			"limits":         `{"max":10}`,
+			MetaRetryCount:   "2",
+			MetaResponseTime: "250ms",
+		}
+		for key, want := range testCases {
+			if got, _ := err.GetMetadata(key); got != want {
+				t.Errorf("GetMetadata(%q) = %q, want %q", key, got, want)
+			}
+		}
+		if got := err.GetAllMetadataStrings()["attempts"]; got != "3" {
+			t.Errorf("GetAllMetadataStrings attempts = %q", got)
+		}
+	})
+
+	t.Run("Typed getters accept string values", func(t *testing.T) {
+		legacy := NewInternalError("legacy", nil).
+			WithMetadata(MetaRetryCount, "4").
+			WithMetadata(MetaDuration, "2s")
+
+		if count, ok := legacy.GetTypedMetadata().GetRetryCount(); !ok || count != 4 {
+			t.Errorf("GetRetryCount = %d, %v", count, ok)
+		}
+		if duration, ok := legacy.GetTypedMetadata().GetDuration(); !ok || duration != 2*time.Second {
+			t.Errorf("GetDuration = %v, %v", duration, ok)
+		}
+	})
+
+	t.Run("JSON keeps numbers", func(t *testing.T) {
+		data, marshalErr := json.Marshal(err.ToJSON())
+		if marshalErr != nil {
+			t.Fatalf("Marshal failed: %v", marshalErr)
+		}
+		for _, fragment := range []string{`"attempts":3`, `"cached":true`, `"ratio":0.25`, `"tags":["a","b"]`} {
+			if !strings.Contains(string(data), fragment) {
+				t.Errorf("JSON should contain %s: %s", fragment, data)
+			}
+		}
+	})
+
+	t.Run("Log fields keep native types", func(t *testing.T) {
+		fields := err.ToLogFields()
+		if fields["meta_attempts"] != 3 {
+			t.Errorf("meta_attempts = %#v, want int 3", fields["meta_attempts"])
+		}
+	})
+
+	t.Run("slog output keeps numbers", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := NewDefaultSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+		logger.LogError(context.Background(), err)
+
+		if !strings.Contains(buf.String(), `"meta_attempts":3`) {
+			t.Errorf("slog JSON should contain numeric attempts: %s", buf.String())
+		}
+	})
+
+	t.Run("Collection counts are numeric", func(t *testing.T) {
+		collection := NewValidationErrorCollection()
+		collection.AddValidation("email", "required")
+		collection.AddValidation("name", "required")
+
+		converted := collection.ToCustomError()
+		if value, _ := converted.GetMetadataValue("total_error_count"); value != 2 {
+			t.Errorf("total_error_count = %#v, want int 2", value)
+		}
+		if fields, _ := converted.GetMetadata("validation_fields"); fields != "email,name" {
+			t.Errorf("validation_fields = %q", fields)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package cuserr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestXMLRendering tests the XML schema of CustomError and ErrorCollection
+func TestXMLRendering(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		err := NewNotFoundError("user", "u1").WithRequestID("req-1").WithRetryAfter(2 * time.Second)
+		data, marshalErr := xml.Marshal(err)
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+
+		var decoded struct {
+			XMLName    xml.Name
+			Code       string `xml:"code,attr"`
+			Category   string `xml:"category,attr"`
+			Message    string `xml:"message"`
+			RequestID  string `xml:"request_id"`
+			RetryAfter int64  `xml:"retry_after_seconds"`
+			Entries    []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"metadata>entry"`
+		}
+		if err := xml.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.XMLName.Local != "error" || decoded.Code != ERROR_CODE_NOT_FOUND || decoded.Category != "not_found" ||
+			decoded.RequestID != "req-1" || decoded.RetryAfter != 2 {
+			t.Errorf("Decoded = %+v", decoded)
+		}
+		if len(decoded.Entries) != 3 || decoded.Entries[1].Key != "resource" || decoded.Entries[1].Value != "user" {
+			t.Errorf("Metadata entries should be sorted by key, got %+v", decoded.Entries)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewValidationError("email", "invalid").WriteXML(&buf,
+			WithXMLNamespace("urn:example:errors"), WithXMLHeader(), WithXMLIndent("  "))
+		if err != nil {
+			t.Fatal(err)
+		}
+		output := buf.String()
+		if !strings.HasPrefix(output, xml.Header+`<error xmlns="urn:example:errors" code="INVALID_INPUT"`) {
+			t.Errorf("Output = %s", output)
+		}
+		if !strings.Contains(output, "\n  <message>") {
+			t.Errorf("Output should be indented: %s", output)
+		}
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		collection := NewValidationErrorCollection().WithRequestID("req-2")
+		collection.AddValidationWithCode("email", "must be valid", "INVALID_EMAIL")
+		collection.Add(NewInternalError("database", nil))
+
+		data, err := xml.Marshal(collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		output := string(data)
+		for _, want := range []string{
+			`<errors code="MULTIPLE_ERRORS" category="internal" count="2">`,
+			`<validation_error field="email" code="INVALID_EMAIL"><message>must be valid</message></validation_error>`,
+			`<error code="INTERNAL_ERROR" category="internal">`,
+			`<request_id>req-2</request_id>`,
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Output %s should contain %s", output, want)
+			}
+		}
+	})
+}
+
+// TestXMLAndTextProductionMode tests that XML and text follow ToClientJSON filtering
+func TestXMLAndTextProductionMode(t *testing.T) {
+	originalConfig := GetConfig()
+	defer SetConfig(originalConfig)
+	SetConfig(&Config{ProductionMode: true})
+
+	err := NewInternalError("database", nil).WithMetadata("query", "SELECT secret").WithMetadata("user_id", "u1")
+	data, _ := xml.Marshal(err)
+	text := err.ToText()
+	for _, output := range []string{string(data), text} {
+		if strings.Contains(output, "SELECT secret") || strings.Contains(output, "database") {
+			t.Errorf("Internal details leaked: %s", output)
+		}
+		if !strings.Contains(output, SAFE_MSG_INTERNAL) || !strings.Contains(output, "u1") {
+			t.Errorf("Safe message and identifiers should be kept: %s", output)
+		}
+	}
+
+	collection := NewErrorCollection("request failed")
+	collection.AddValidation("name", "is required")
+	collection.Add(err)
+	data, _ = xml.Marshal(collection)
+	text = collection.ToText()
+	for _, output := range []string{string(data), text} {
+		if strings.Contains(output, SAFE_MSG_INTERNAL) || strings.Contains(output, "SELECT secret") {
+			t.Errorf("Members should be omitted in production: %s", output)
+		}
+		if !strings.Contains(output, "is required") {
+			t.Errorf("Validation errors should be kept: %s", output)
+		}
+	}
+}
+
+// TestTextRendering tests the text/plain layout
+func TestTextRendering(t *testing.T) {
+	err := NewRateLimitError("100", "1m").WithRequestID("req-3").WithRetryAfter(30 * time.Second)
+	want := "rate limit exceeded: 100 per 1m\n" +
+		"category: rate_limit\n" +
+		"code: RATE_LIMIT\n" +
+		"request_id: req-3\n" +
+		"retryable: true\n" +
+		"retry_after_seconds: 30\n" +
+		"metadata:\n" +
+		"    error_type: rate_limit\n" +
+		"    limit: 100\n" +
+		"    window: 1m\n"
+	if text := err.ToText(); text != want {
+		t.Errorf("ToText() =\n%s\nwant\n%s", text, want)
+	}
+
+	collection := NewValidationErrorCollection()
+	collection.AddValidationWithCode("email", "must be valid", "INVALID_EMAIL")
+	collection.Add(NewNotFoundError("org", "o1"))
+
+	var buf bytes.Buffer
+	if writeErr := collection.WriteText(&buf); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	if !strings.Contains(buf.String(), "  - email: must be valid (INVALID_EMAIL)\n[1] org with id 'o1' not found\ncategory: not_found") {
+		t.Errorf("WriteText() =\n%s", buf.String())
+	}
+}
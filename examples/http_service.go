@@ -221,38 +221,15 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 // handleError handles custom errors and converts them to HTTP responses
 func (h *UserHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
 	var customErr *cuserr.CustomError
-
 	if errors.As(err, &customErr) {
 		// Log detailed error for debugging
 		log.Printf("[ERROR] %s", customErr.DetailedError())
-
-		// Send appropriate HTTP response
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(customErr.ToHTTPStatus())
-
-		// Use client-safe JSON in production
-		config := cuserr.GetConfig()
-		var response map[string]interface{}
-		if config.ProductionMode {
-			response = customErr.ToClientJSON()
-		} else {
-			response = customErr.ToJSON()
-		}
-
-		json.NewEncoder(w).Encode(response)
-		return
+	} else {
+		log.Printf("[ERROR] Unexpected error: %v", err)
 	}
 
-	// Handle unexpected errors
-	log.Printf("[ERROR] Unexpected error: %v", err)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusInternalServerError)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    "INTERNAL_ERROR",
-			"message": "Internal server error",
-		},
-	})
+	// Status, Content-Type and a client-safe body negotiated from Accept
+	_ = cuserr.WriteHTTPError(w, r, err)
 }
 
 // writeJSON writes a JSON response
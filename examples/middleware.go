@@ -35,7 +35,7 @@ func ErrorMiddleware(next http.Handler) http.Handler {
 					WithMetadata("panic_value", fmt.Sprintf("%v", recovered)).
 					WithRequestID(GetRequestID(r.Context()))
 
-				WriteErrorResponse(w, panicErr)
+				WriteErrorResponse(w, r, panicErr)
 			}
 		}()
 
@@ -91,7 +91,7 @@ func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
 						WithMetadata("path", r.URL.Path).
 						WithRequestID(GetRequestID(r.Context()))
 
-					WriteErrorResponse(w, timeoutErr)
+					WriteErrorResponse(w, r, timeoutErr)
 				}
 			}
 		})
@@ -128,7 +128,7 @@ func RateLimitMiddleware(requestsPerMinute int) func(http.Handler) http.Handler
 					WithMetadata("current_requests", fmt.Sprintf("%d", len(validRequests))).
 					WithRequestID(GetRequestID(r.Context()))
 
-				WriteErrorResponse(w, rateLimitErr)
+				WriteErrorResponse(w, r, rateLimitErr)
 				return
 			}
 
@@ -155,7 +155,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 				WithMetadata("path", r.URL.Path).
 				WithRequestID(GetRequestID(r.Context()))
 
-			WriteErrorResponse(w, unauthorizedErr)
+			WriteErrorResponse(w, r, unauthorizedErr)
 			return
 		}
 
@@ -168,7 +168,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 				WithMetadata("api_key", maskAPIKey(apiKey)).
 				WithRequestID(GetRequestID(r.Context()))
 
-			WriteErrorResponse(w, unauthorizedErr)
+			WriteErrorResponse(w, r, unauthorizedErr)
 			return
 		}
 
@@ -234,19 +234,9 @@ func GetUserID(ctx context.Context) string {
 }
 
 // WriteErrorResponse writes a cuserr.CustomError as HTTP response
-func WriteErrorResponse(w http.ResponseWriter, err *cuserr.CustomError) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(err.ToHTTPStatus())
-
-	config := cuserr.GetConfig()
-	var response map[string]interface{}
-	if config.ProductionMode {
-		response = err.ToClientJSON()
-	} else {
-		response = err.ToJSON()
-	}
-
-	json.NewEncoder(w).Encode(response)
+// The representation follows the request's Accept header
+func WriteErrorResponse(w http.ResponseWriter, r *http.Request, err *cuserr.CustomError) {
+	_ = cuserr.WriteHTTPError(w, r, err)
 }
 
 // getClientIP extracts client IP from request
@@ -387,7 +377,7 @@ func ErrorDemoHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	WriteErrorResponse(w, err)
+	WriteErrorResponse(w, r, err)
 }
 
 // Demo server setup
@@ -0,0 +1,23 @@
+// Package httpx provides net/http middleware for cuserr errors.
+// This file contains the constants shared by the middleware.
+package httpx
+
+const (
+	// Request IDs
+
+	// HEADER_REQUEST_ID defines the default header carrying request IDs
+	HEADER_REQUEST_ID = "X-Request-ID"
+	// REQUEST_ID_MAX_LENGTH defines the longest incoming request ID that is accepted
+	REQUEST_ID_MAX_LENGTH = 128
+	// REQUEST_ID_RANDOM_BYTES defines the number of random bytes in generated request IDs
+	REQUEST_ID_RANDOM_BYTES = 16
+
+	// Recovered panics
+
+	// PANIC_MSG defines the message of errors created from recovered panics
+	PANIC_MSG = "handler panicked"
+	// PANIC_CAUSE_TEMPLATE defines the template of the cause wrapped around a panic value
+	PANIC_CAUSE_TEMPLATE = "panic: %v"
+	// PANIC_CAUSE_ERROR_TEMPLATE defines the template of the cause wrapped around an error panic value
+	PANIC_CAUSE_ERROR_TEMPLATE = "panic: %w"
+)
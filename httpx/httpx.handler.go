@@ -0,0 +1,91 @@
+// Package httpx provides net/http middleware for cuserr errors.
+// This file contains error-returning handlers, panic recovery and error logging.
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/itsatony/go-cuserr"
+)
+
+// HandlerFunc is an HTTP handler that returns an error instead of writing it
+// Returned errors are logged and rendered with cuserr.WriteHTTPError.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls f and handles the returned error
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw := WrapResponseWriter(w)
+	if err := f(rw, r); err != nil {
+		HandleError(rw, r, err)
+	}
+}
+
+// ErrorHandler adapts an error-returning function to http.Handler
+func ErrorHandler(fn func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return HandlerFunc(fn)
+}
+
+// HandleError logs err through the configured StructuredLogger and writes the error response
+// The response is skipped when w is a *ResponseWriter whose handler already
+// started writing, since the status line can no longer change.
+func HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+
+	logError(r, err)
+	if rw, ok := w.(*ResponseWriter); ok && rw.Written() {
+		return
+	}
+	_ = cuserr.WriteHTTPError(w, r, err)
+}
+
+// Recover returns middleware that turns panics into internal errors
+// The error wraps the panic value (with %w when it is an error), carries the
+// stack trace of the panic and is handled like a returned error. Panics with
+// http.ErrAbortHandler are re-raised so net/http can abort the response.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := WrapResponseWriter(w)
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			cause := fmt.Errorf(PANIC_CAUSE_TEMPLATE, recovered)
+			if recoveredErr, ok := recovered.(error); ok {
+				cause = fmt.Errorf(PANIC_CAUSE_ERROR_TEMPLATE, recoveredErr)
+			}
+			HandleError(rw, r, cuserr.NewCustomError(cuserr.ErrInternal, cause, PANIC_MSG))
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// logError logs err with the request method, path and request ID
+// Errors without a CustomError or ErrorCollection in their chain are
+// converted with cuserr.FromError
+func logError(r *http.Request, err error) {
+	ctx := r.Context()
+	logger := cuserr.GetStructuredLogger()
+
+	var collection *cuserr.ErrorCollection
+	if errors.As(err, &collection) {
+		logger.LogErrorCollection(ctx, collection)
+		return
+	}
+
+	customErr := cuserr.FromError(err).Clone()
+	customErr = customErr.WithMetadata(cuserr.MetaMethod, r.Method).WithMetadata(cuserr.MetaEndpoint, r.URL.Path)
+	if requestID := cuserr.GetRequestIDFromContext(ctx); requestID != "" && customErr.RequestID == "" {
+		customErr = customErr.WithRequestID(requestID)
+	}
+	logger.LogError(ctx, customErr)
+}
@@ -0,0 +1,85 @@
+// Package httpx provides net/http middleware for cuserr errors.
+// This file contains the request ID middleware.
+package httpx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/itsatony/go-cuserr"
+)
+
+// RequestIDOption configures RequestID
+type RequestIDOption func(*requestIDConfig)
+
+// requestIDConfig holds the RequestID settings
+type requestIDConfig struct {
+	header    string
+	generator func() string
+}
+
+// WithRequestIDHeader sets the header read from requests and echoed in responses
+func WithRequestIDHeader(header string) RequestIDOption {
+	return func(c *requestIDConfig) {
+		c.header = header
+	}
+}
+
+// WithRequestIDGenerator sets the function that creates IDs for requests without a valid one
+func WithRequestIDGenerator(generator func() string) RequestIDOption {
+	return func(c *requestIDConfig) {
+		c.generator = generator
+	}
+}
+
+// RequestID returns middleware that assigns every request an ID
+// An incoming X-Request-ID header (or the configured header) is kept when it
+// is at most 128 printable ASCII characters; otherwise a new ID is generated.
+// The ID is stored under cuserr.RequestIDContextKey, so errors created with
+// the request context and WriteHTTPError pick it up, and is echoed in the
+// response header.
+func RequestID(opts ...RequestIDOption) func(http.Handler) http.Handler {
+	config := requestIDConfig{header: HEADER_REQUEST_ID, generator: NewRequestID}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(config.header)
+			if !validRequestID(requestID) {
+				requestID = config.generator()
+			}
+
+			w.Header().Set(config.header, requestID)
+			next.ServeHTTP(w, r.WithContext(cuserr.ContextWithRequestID(r.Context(), requestID)))
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID assigned by RequestID
+func RequestIDFromContext(ctx context.Context) string {
+	return cuserr.GetRequestIDFromContext(ctx)
+}
+
+// NewRequestID generates a random 32-character hexadecimal request ID
+func NewRequestID() string {
+	buf := make([]byte, REQUEST_ID_RANDOM_BYTES)
+	_, _ = rand.Read(buf) // crypto/rand.Read never returns an error since Go 1.24 and practically never before
+	return hex.EncodeToString(buf)
+}
+
+// validRequestID reports whether an incoming ID is safe to propagate into logs and headers
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > REQUEST_ID_MAX_LENGTH {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < '!' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+// Package httpx provides net/http middleware for cuserr errors.
+// This file contains the status-capturing response writer.
+package httpx
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter records the status and size of a response
+// It lets middleware log the outcome and skip writing an error body once
+// the handler has started its own response. http.Flusher, http.Hijacker and
+// io.ReaderFrom are forwarded; other optional interfaces of the wrapped
+// writer are only reachable through http.ResponseController.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// WrapResponseWriter wraps w, or returns it unchanged when it already is a *ResponseWriter
+func WrapResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// WriteHeader records the first final status and forwards it
+// Informational 1xx statuses are forwarded without being recorded
+func (rw *ResponseWriter) WriteHeader(status int) {
+	if rw.status == 0 && status >= http.StatusOK {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Write records an implicit 200 status and the number of bytes written
+func (rw *ResponseWriter) Write(data []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(data)
+	rw.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the wrapped writer supports it
+func (rw *ResponseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the wrapped writer supports it
+// A hijacked connection counts as written, so no error body follows it.
+// Returns http.ErrNotSupported when the wrapped writer cannot be hijacked.
+func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err == nil && rw.status == 0 {
+		rw.status = http.StatusSwitchingProtocols
+	}
+	return conn, buf, err
+}
+
+// ReadFrom records an implicit 200 status and copies src with the wrapped
+// writer's io.ReaderFrom when it has one
+func (rw *ResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := io.Copy(rw.ResponseWriter, src)
+	rw.size += n
+	return n, err
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Status returns the response status, or 200 when nothing has been written yet
+func (rw *ResponseWriter) Status() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+	return rw.status
+}
+
+// Size returns the number of body bytes written
+func (rw *ResponseWriter) Size() int64 {
+	return rw.size
+}
+
+// Written reports whether the status line has been sent
+func (rw *ResponseWriter) Written() bool {
+	return rw.status != 0
+}
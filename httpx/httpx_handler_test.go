@@ -0,0 +1,90 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/itsatony/go-cuserr"
+)
+
+// TestErrorHandler tests error-returning handlers
+func TestErrorHandler(t *testing.T) {
+	logger := useRecordingLogger(t)
+
+	t.Run("CustomError", func(t *testing.T) {
+		handler := ErrorHandler(func(w http.ResponseWriter, r *http.Request) error {
+			return fmt.Errorf("load: %w", cuserr.NewNotFoundError("user", "u1"))
+		})
+		req := httptest.NewRequest(http.MethodGet, "/users/u1", nil)
+		req.Header.Set("Accept", cuserr.CONTENT_TYPE_PROBLEM_JSON)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound || rec.Header().Get("Content-Type") != cuserr.CONTENT_TYPE_PROBLEM_JSON {
+			t.Errorf("Response = %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		logged := logger.errors[len(logger.errors)-1]
+		if method, _ := logged.GetMetadata(cuserr.MetaMethod); method != http.MethodGet || logged.Code != cuserr.ERROR_CODE_NOT_FOUND {
+			t.Errorf("Logged = %+v", logged)
+		}
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			collection := cuserr.NewValidationErrorCollection()
+			collection.AddValidation("email", "is required")
+			return collection
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+		if rec.Code != http.StatusBadRequest || len(logger.collections) != 1 {
+			t.Errorf("Response = %d, logged collections = %d", rec.Code, len(logger.collections))
+		}
+	})
+
+	t.Run("Foreign error", func(t *testing.T) {
+		handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return errors.New("connection reset by peer")
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		logged := logger.errors[len(logger.errors)-1]
+		if rec.Code != http.StatusInternalServerError || logged.Category != cuserr.ErrorCategoryInternal {
+			t.Errorf("Response = %d, logged category = %s", rec.Code, logged.Category)
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		count := len(logger.errors)
+		handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte("ok"))
+			return err
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusOK || rec.Body.String() != "ok" || len(logger.errors) != count {
+			t.Errorf("Response = %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Error after write", func(t *testing.T) {
+		count := len(logger.errors)
+		handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			_, _ = w.Write([]byte(`{"items":[`))
+			return cuserr.NewTimeoutError("stream", nil)
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "TIMEOUT") || len(logger.errors) != count+1 {
+			t.Errorf("Response = %d %q", rec.Code, rec.Body.String())
+		}
+	})
+}
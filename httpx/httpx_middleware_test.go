@@ -0,0 +1,209 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/itsatony/go-cuserr"
+)
+
+// recordingLogger captures logged errors
+type recordingLogger struct {
+	mu          sync.Mutex
+	errors      []*cuserr.CustomError
+	collections []*cuserr.ErrorCollection
+}
+
+// Log ignores plain messages
+func (l *recordingLogger) Log(ctx context.Context, level cuserr.LogLevel, message string, fields map[string]interface{}) {
+}
+
+// LogError records err
+func (l *recordingLogger) LogError(ctx context.Context, err *cuserr.CustomError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, err)
+}
+
+// LogErrorCollection records collection
+func (l *recordingLogger) LogErrorCollection(ctx context.Context, collection *cuserr.ErrorCollection) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.collections = append(l.collections, collection)
+}
+
+// useRecordingLogger installs a recordingLogger for the duration of the test
+func useRecordingLogger(t *testing.T) *recordingLogger {
+	t.Helper()
+	original := cuserr.GetStructuredLogger()
+	logger := &recordingLogger{}
+	cuserr.SetStructuredLogger(logger)
+	t.Cleanup(func() { cuserr.SetStructuredLogger(original) })
+	return logger
+}
+
+// TestRequestID tests ID propagation, generation and options
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	t.Run("Incoming header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HEADER_REQUEST_ID, "req-abc")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if seen != "req-abc" || rec.Header().Get(HEADER_REQUEST_ID) != "req-abc" {
+			t.Errorf("seen = %q, header = %q", seen, rec.Header().Get(HEADER_REQUEST_ID))
+		}
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		for _, incoming := range []string{"", "bad id\nwith newline", strings.Repeat("x", REQUEST_ID_MAX_LENGTH+1)} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(HEADER_REQUEST_ID, incoming)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if len(seen) != 2*REQUEST_ID_RANDOM_BYTES || seen == incoming || rec.Header().Get(HEADER_REQUEST_ID) != seen {
+				t.Errorf("Incoming %q: seen = %q", incoming, seen)
+			}
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		custom := RequestID(WithRequestIDHeader("X-Correlation-ID"), WithRequestIDGenerator(func() string { return "fixed" }))(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = cuserr.GetRequestIDFromContext(r.Context())
+			}))
+		rec := httptest.NewRecorder()
+		custom.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if seen != "fixed" || rec.Header().Get("X-Correlation-ID") != "fixed" {
+			t.Errorf("seen = %q, headers = %v", seen, rec.Header())
+		}
+	})
+}
+
+// TestRecover tests panic recovery
+func TestRecover(t *testing.T) {
+	logger := useRecordingLogger(t)
+	errBoom := errors.New("boom")
+
+	handler := RequestID()(Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(errBoom)
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(HEADER_REQUEST_ID, "req-panic")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), `"request_id":"req-panic"`) {
+		t.Errorf("Response = %d %s", rec.Code, rec.Body.String())
+	}
+	if len(logger.errors) != 1 {
+		t.Fatalf("Logged %d errors", len(logger.errors))
+	}
+	logged := logger.errors[0]
+	if !errors.Is(logged, errBoom) || !errors.Is(logged, cuserr.ErrInternal) || logged.RequestID != "req-panic" {
+		t.Errorf("Logged = %+v", logged)
+	}
+	if endpoint, _ := logged.GetMetadata(cuserr.MetaEndpoint); endpoint != "/orders" {
+		t.Errorf("endpoint = %q", endpoint)
+	}
+	if len(logged.GetStackTrace()) == 0 {
+		t.Error("Recovered errors should carry a stack trace")
+	}
+
+	t.Run("After partial write", func(t *testing.T) {
+		partial := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte("partial"))
+			panic("late")
+		}))
+		rec := httptest.NewRecorder()
+		partial.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusAccepted || rec.Body.String() != "partial" {
+			t.Errorf("Response = %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Abort handler", func(t *testing.T) {
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Errorf("recovered = %v", recovered)
+			}
+		}()
+		Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+// TestResponseWriter tests status and size capture
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := WrapResponseWriter(rec)
+	if rw.Written() || rw.Status() != http.StatusOK {
+		t.Errorf("Fresh writer: written = %v, status = %d", rw.Written(), rw.Status())
+	}
+	if WrapResponseWriter(rw) != rw {
+		t.Error("Wrapping twice should return the same writer")
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	_, _ = rw.Write([]byte("hello"))
+	rw.Flush()
+	if rw.Status() != http.StatusCreated || rw.Size() != 5 || !rw.Written() || !rec.Flushed {
+		t.Errorf("status = %d, size = %d, flushed = %v", rw.Status(), rw.Size(), rec.Flushed)
+	}
+	if http.NewResponseController(rw).Flush() != nil {
+		t.Error("ResponseController should reach the wrapped writer")
+	}
+
+	t.Run("ReadFrom", func(t *testing.T) {
+		rw := WrapResponseWriter(httptest.NewRecorder())
+		if n, err := io.Copy(rw, strings.NewReader("streamed")); err != nil || n != 8 || rw.Size() != 8 || rw.Status() != http.StatusOK {
+			t.Errorf("n = %d, err = %v, size = %d", n, err, rw.Size())
+		}
+	})
+
+	t.Run("Hijack", func(t *testing.T) {
+		if _, _, err := WrapResponseWriter(httptest.NewRecorder()).Hijack(); !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("Hijack on a recorder = %v", err)
+		}
+
+		server := httptest.NewServer(ErrorHandler(func(w http.ResponseWriter, r *http.Request) error {
+			hijacker, ok := w.(http.Hijacker)
+			if !ok {
+				return cuserr.NewInternalError("websocket", errors.New("writer cannot be hijacked"))
+			}
+			conn, buf, err := hijacker.Hijack()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: close\r\n\r\n")
+			_ = buf.Flush()
+			if !w.(*ResponseWriter).Written() {
+				return cuserr.NewInternalError("websocket", errors.New("hijack should count as written"))
+			}
+			return nil
+		}))
+		defer server.Close()
+
+		resp, err := server.Client().Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Errorf("status = %d", resp.StatusCode)
+		}
+	})
+}